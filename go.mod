@@ -24,5 +24,6 @@ require (
 	golang.org/x/text v0.3.2
 	google.golang.org/api v0.26.0
 	gopkg.in/yaml.v2 v2.4.0
+	gopkg.in/yaml.v3 v3.0.1
 	gotest.tools v2.2.0+incompatible
 )
@@ -0,0 +1,169 @@
+package keygen
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"time"
+)
+
+// Errors
+var (
+	ErrInvalidCAKey         = errKeygen.Code("invalid_ca_key").ErrorPref("cannot parse the private key of the CA: %v")
+	ErrInvalidCACertificate = errKeygen.Code("invalid_ca_certificate").ErrorPref("cannot parse the certificate of the CA: %v")
+	ErrNotACA               = errKeygen.Code("not_a_ca").Error("the certificate of the CA is not allowed to sign other certificates")
+	ErrCAKeyMismatch        = errKeygen.Code("ca_key_mismatch").Error("the private key of the CA does not belong to its certificate")
+	ErrInvalidValidity      = errKeygen.Code("invalid_validity").Error("the validity of the certificate must be positive")
+)
+
+// CertificateOptions configures a certificate to generate.
+type CertificateOptions struct {
+	// CommonName is the common name of the subject.
+	CommonName string
+	// DNSNames and IPAddresses are added as subject alternative names.
+	DNSNames    []string
+	IPAddresses []net.IP
+	// ValidFor is the duration from now for which the certificate is valid.
+	ValidFor time.Duration
+	// IsCA determines whether the certificate can be used to sign other certificates.
+	IsCA bool
+	// KeyType and Bits configure the private key of the certificate.
+	KeyType string
+	Bits    int
+	// CA signs the certificate. When nil, the certificate is self-signed.
+	CA *CA
+}
+
+// CA is a certificate authority that signs certificates.
+type CA struct {
+	Key         crypto.Signer
+	Certificate *x509.Certificate
+}
+
+// ParseCA parses the PEM encoded private key and certificate of a CA.
+// The private key can be in PKCS #8, PKCS #1 or SEC 1 form.
+func ParseCA(keyPEM []byte, certPEM []byte) (*CA, error) {
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil {
+		return nil, ErrInvalidCAKey("no PEM data found")
+	}
+
+	var key interface{}
+	var err error
+	switch keyBlock.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(keyBlock.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(keyBlock.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(keyBlock.Bytes)
+	}
+	if err != nil {
+		return nil, ErrInvalidCAKey(err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, ErrInvalidCAKey("unsupported key type")
+	}
+
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil {
+		return nil, ErrInvalidCACertificate("no PEM data found")
+	}
+
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, ErrInvalidCACertificate(err)
+	}
+
+	if !cert.IsCA {
+		return nil, ErrNotACA
+	}
+
+	publicKey, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return nil, ErrInvalidCAKey(err)
+	}
+	if string(publicKey) != string(cert.RawSubjectPublicKeyInfo) {
+		return nil, ErrCAKeyMismatch
+	}
+
+	return &CA{
+		Key:         signer,
+		Certificate: cert,
+	}, nil
+}
+
+// Certificate generates a private key and an X.509 certificate for it.
+// Both are returned PEM encoded. The certificate is signed by the CA in
+// the options, or self-signed when no CA is given.
+func Certificate(opts CertificateOptions) ([]byte, []byte, error) {
+	if opts.ValidFor <= 0 {
+		return nil, nil, ErrInvalidValidity
+	}
+
+	key, err := generateKey(opts.KeyType, opts.Bits)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			CommonName: opts.CommonName,
+		},
+		DNSNames:              opts.DNSNames,
+		IPAddresses:           opts.IPAddresses,
+		NotBefore:             now,
+		NotAfter:              now.Add(opts.ValidFor),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+
+	if opts.IsCA {
+		template.IsCA = true
+		template.KeyUsage |= x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+	} else {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+		if _, isRSA := key.(*rsa.PrivateKey); isRSA {
+			template.KeyUsage |= x509.KeyUsageKeyEncipherment
+		}
+	}
+
+	parent := template
+	var signer crypto.Signer = key
+	if opts.CA != nil {
+		parent = opts.CA.Certificate
+		signer = opts.CA.Key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), signer)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyPEM, err := encodePrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if opts.CA != nil {
+		// Include the CA, so the certificate can be served with its chain.
+		certPEM = append(certPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: opts.CA.Certificate.Raw})...)
+	}
+
+	return keyPEM, certPEM, nil
+}
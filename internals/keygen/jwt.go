@@ -0,0 +1,105 @@
+package keygen
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+)
+
+// Supported JWT signing algorithms.
+const (
+	JWTAlgHS256 = "HS256"
+	JWTAlgHS384 = "HS384"
+	JWTAlgHS512 = "HS512"
+	JWTAlgRS256 = "RS256"
+	JWTAlgRS384 = "RS384"
+	JWTAlgRS512 = "RS512"
+	JWTAlgES256 = "ES256"
+	JWTAlgES384 = "ES384"
+	JWTAlgES512 = "ES512"
+	JWTAlgEdDSA = "EdDSA"
+)
+
+// JWTAlgorithms are all supported JWT signing algorithms.
+var JWTAlgorithms = []string{
+	JWTAlgHS256, JWTAlgHS384, JWTAlgHS512,
+	JWTAlgRS256, JWTAlgRS384, JWTAlgRS512,
+	JWTAlgES256, JWTAlgES384, JWTAlgES512,
+	JWTAlgEdDSA,
+}
+
+// Errors
+var (
+	ErrUnknownJWTAlgorithm = errKeygen.Code("unknown_jwt_algorithm").ErrorPref("unknown JWT signing algorithm %s: supported algorithms are %s")
+)
+
+// jwtRSABits is the size of RSA keys used to sign JWTs.
+const jwtRSABits = 2048
+
+// JWTKey generates a key to sign JSON Web Tokens with the given algorithm.
+//
+// For the HMAC algorithms (HS*), a random secret with the size of the hash output is
+// returned, encoded with unpadded base64url, and the public key is nil.
+// For the other algorithms, the private key is returned PEM encoded in PKCS #8 form
+// and the public key PEM encoded in PKIX form.
+func JWTKey(alg string) ([]byte, []byte, error) {
+	switch alg {
+	case JWTAlgHS256, JWTAlgHS384, JWTAlgHS512:
+		secret, err := hmacSecret(alg)
+		return secret, nil, err
+	}
+
+	var keyType string
+	var bits int
+	switch alg {
+	case JWTAlgRS256, JWTAlgRS384, JWTAlgRS512:
+		keyType, bits = KeyTypeRSA, jwtRSABits
+	case JWTAlgES256:
+		keyType, bits = KeyTypeECDSA, 256
+	case JWTAlgES384:
+		keyType, bits = KeyTypeECDSA, 384
+	case JWTAlgES512:
+		keyType, bits = KeyTypeECDSA, 521
+	case JWTAlgEdDSA:
+		keyType = KeyTypeEd25519
+	default:
+		return nil, nil, ErrUnknownJWTAlgorithm(alg, strings.Join(JWTAlgorithms, ", "))
+	}
+
+	key, err := generateKey(keyType, bits)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	private, err := encodePrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	public, err := encodePublicKey(key.Public())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return private, public, nil
+}
+
+// hmacSecret returns a random secret of the output size of the hash used by the HMAC algorithm,
+// which is the recommended size by RFC 7518.
+func hmacSecret(alg string) ([]byte, error) {
+	size := map[string]int{
+		JWTAlgHS256: 32,
+		JWTAlgHS384: 48,
+		JWTAlgHS512: 64,
+	}[alg]
+
+	secret := make([]byte, size)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded := make([]byte, base64.RawURLEncoding.EncodedLen(size))
+	base64.RawURLEncoding.Encode(encoded, secret)
+	return encoded, nil
+}
@@ -0,0 +1,104 @@
+// Package keygen generates typed secrets, such as SSH keys, X.509 certificates,
+// JWT signing keys and diceware passphrases.
+//
+// All private keys and secrets are generated using crypto/rand.
+package keygen
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+
+	"github.com/secrethub/secrethub-go/internals/errio"
+)
+
+// Errors
+var (
+	errKeygen = errio.Namespace("keygen")
+
+	ErrUnknownKeyType = errKeygen.Code("unknown_key_type").ErrorPref("unknown key type %s: supported key types are %s")
+	ErrInvalidKeySize = errKeygen.Code("invalid_key_size").ErrorPref("invalid key size of %d bits for %s keys: %s")
+)
+
+// Supported key types.
+const (
+	KeyTypeEd25519 = "ed25519"
+	KeyTypeRSA     = "rsa"
+	KeyTypeECDSA   = "ecdsa"
+)
+
+const (
+	defaultRSABits   = 4096
+	minRSABits       = 2048
+	defaultECDSABits = 256
+)
+
+// generateKey generates a private key of the given type.
+// When bits is 0, the default size for the key type is used.
+func generateKey(keyType string, bits int) (crypto.Signer, error) {
+	switch keyType {
+	case KeyTypeEd25519:
+		if bits != 0 {
+			return nil, ErrInvalidKeySize(bits, keyType, "ed25519 keys have a fixed size")
+		}
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	case KeyTypeRSA:
+		if bits == 0 {
+			bits = defaultRSABits
+		}
+		if bits < minRSABits {
+			return nil, ErrInvalidKeySize(bits, keyType, "the minimum is 2048 bits")
+		}
+		return rsa.GenerateKey(rand.Reader, bits)
+	case KeyTypeECDSA:
+		if bits == 0 {
+			bits = defaultECDSABits
+		}
+		curve, err := ecdsaCurve(bits)
+		if err != nil {
+			return nil, err
+		}
+		return ecdsa.GenerateKey(curve, rand.Reader)
+	default:
+		return nil, ErrUnknownKeyType(keyType, strings.Join([]string{KeyTypeEd25519, KeyTypeRSA, KeyTypeECDSA}, ", "))
+	}
+}
+
+// ecdsaCurve returns the NIST curve with the given size.
+func ecdsaCurve(bits int) (elliptic.Curve, error) {
+	switch bits {
+	case 256:
+		return elliptic.P256(), nil
+	case 384:
+		return elliptic.P384(), nil
+	case 521:
+		return elliptic.P521(), nil
+	default:
+		return nil, ErrInvalidKeySize(bits, KeyTypeECDSA, "supported sizes are 256, 384 and 521 bits")
+	}
+}
+
+// encodePrivateKey returns the PEM encoded PKCS #8 form of the private key.
+func encodePrivateKey(key crypto.Signer) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// encodePublicKey returns the PEM encoded PKIX form of the public key.
+func encodePublicKey(key crypto.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
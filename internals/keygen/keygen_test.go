@@ -0,0 +1,187 @@
+package keygen
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestSSHKeyPair(t *testing.T) {
+	cases := map[string]struct {
+		keyType string
+		bits    int
+		err     error
+	}{
+		"ed25519": {
+			keyType: KeyTypeEd25519,
+		},
+		"rsa": {
+			keyType: KeyTypeRSA,
+			bits:    2048,
+		},
+		"rsa too small": {
+			keyType: KeyTypeRSA,
+			bits:    1024,
+			err:     ErrInvalidKeySize(1024, KeyTypeRSA, "the minimum is 2048 bits"),
+		},
+		"ecdsa": {
+			keyType: KeyTypeECDSA,
+			err:     ErrUnknownKeyType(KeyTypeECDSA, "ed25519, rsa"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			private, public, err := SSHKeyPair(tc.keyType, tc.bits, "deploy@example.com")
+
+			assert.Equal(t, err, tc.err)
+			if tc.err != nil {
+				return
+			}
+
+			signer, err := ssh.ParsePrivateKey(private)
+			assert.OK(t, err)
+
+			publicKey, comment, _, _, err := ssh.ParseAuthorizedKey(public)
+			assert.OK(t, err)
+			assert.Equal(t, comment, "deploy@example.com")
+			assert.Equal(t, publicKey.Marshal(), signer.PublicKey().Marshal())
+		})
+	}
+}
+
+func TestCertificate(t *testing.T) {
+	caKey, caCert, err := Certificate(CertificateOptions{
+		CommonName: "Example CA",
+		ValidFor:   24 * time.Hour,
+		IsCA:       true,
+		KeyType:    KeyTypeECDSA,
+	})
+	assert.OK(t, err)
+
+	ca, err := ParseCA(caKey, caCert)
+	assert.OK(t, err)
+
+	key, cert, err := Certificate(CertificateOptions{
+		CommonName: "example.com",
+		DNSNames:   []string{"example.com", "www.example.com"},
+		ValidFor:   time.Hour,
+		KeyType:    KeyTypeEd25519,
+		CA:         ca,
+	})
+	assert.OK(t, err)
+
+	keyBlock, _ := pem.Decode(key)
+	parsedKey, err := x509.ParsePKCS8PrivateKey(keyBlock.Bytes)
+	assert.OK(t, err)
+	_, isEd25519 := parsedKey.(ed25519.PrivateKey)
+	assert.Equal(t, isEd25519, true)
+
+	certBlock, rest := pem.Decode(cert)
+	leaf, err := x509.ParseCertificate(certBlock.Bytes)
+	assert.OK(t, err)
+	assert.Equal(t, leaf.DNSNames, []string{"example.com", "www.example.com"})
+	assert.Equal(t, leaf.IsCA, false)
+
+	// The chain includes the CA.
+	chainBlock, _ := pem.Decode(rest)
+	assert.Equal(t, chainBlock.Bytes, ca.Certificate.Raw)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Certificate)
+	_, err = leaf.Verify(x509.VerifyOptions{DNSName: "www.example.com", Roots: roots})
+	assert.OK(t, err)
+
+	// A leaf certificate cannot be used as a CA.
+	_, err = ParseCA(key, cert)
+	assert.Equal(t, err, ErrNotACA)
+
+	// The key must belong to the certificate.
+	_, err = ParseCA(key, caCert)
+	assert.Equal(t, err, ErrCAKeyMismatch)
+}
+
+func TestJWTKey(t *testing.T) {
+	cases := map[string]struct {
+		alg       string
+		check     func(t *testing.T, private, public []byte)
+		expectErr error
+	}{
+		"HS384": {
+			alg: JWTAlgHS384,
+			check: func(t *testing.T, private, public []byte) {
+				secret, err := base64.RawURLEncoding.DecodeString(string(private))
+				assert.OK(t, err)
+				assert.Equal(t, len(secret), 48)
+				assert.Equal(t, public, []byte(nil))
+			},
+		},
+		"RS256": {
+			alg: JWTAlgRS256,
+			check: func(t *testing.T, private, public []byte) {
+				block, _ := pem.Decode(private)
+				key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+				assert.OK(t, err)
+				assert.Equal(t, key.(*rsa.PrivateKey).N.BitLen(), 2048)
+
+				block, _ = pem.Decode(public)
+				_, err = x509.ParsePKIXPublicKey(block.Bytes)
+				assert.OK(t, err)
+			},
+		},
+		"ES512": {
+			alg: JWTAlgES512,
+			check: func(t *testing.T, private, public []byte) {
+				block, _ := pem.Decode(private)
+				key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+				assert.OK(t, err)
+				assert.Equal(t, key.(*ecdsa.PrivateKey).Curve.Params().BitSize, 521)
+			},
+		},
+		"unknown": {
+			alg:       "none",
+			expectErr: ErrUnknownJWTAlgorithm("none", strings.Join(JWTAlgorithms, ", ")),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			private, public, err := JWTKey(tc.alg)
+
+			assert.Equal(t, err, tc.expectErr)
+			if tc.expectErr == nil {
+				tc.check(t, private, public)
+			}
+		})
+	}
+}
+
+func TestPassphrase(t *testing.T) {
+	words := make(map[string]bool, len(effLargeWordList))
+	for _, word := range effLargeWordList {
+		words[word] = true
+	}
+	assert.Equal(t, len(words), 7776)
+
+	passphrase, err := Passphrase(6, " ")
+	assert.OK(t, err)
+
+	picked := strings.Split(passphrase, " ")
+	assert.Equal(t, len(picked), 6)
+	for _, word := range picked {
+		assert.Equal(t, words[word], true)
+	}
+
+	_, err = Passphrase(0, " ")
+	assert.Equal(t, err, ErrInvalidWordCount)
+}
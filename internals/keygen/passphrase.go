@@ -0,0 +1,33 @@
+package keygen
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+)
+
+// Errors
+var (
+	ErrInvalidWordCount = errKeygen.Code("invalid_word_count").Error("a passphrase must consist of at least 1 word")
+)
+
+// Passphrase generates a diceware passphrase of the given number of words, picked
+// uniformly at random from the EFF large wordlist and joined by the separator.
+// Every word adds almost 12.9 bits of entropy.
+func Passphrase(words int, separator string) (string, error) {
+	if words < 1 {
+		return "", ErrInvalidWordCount
+	}
+
+	max := big.NewInt(int64(len(effLargeWordList)))
+	picked := make([]string, words)
+	for i := range picked {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		picked[i] = effLargeWordList[n.Int64()]
+	}
+
+	return strings.Join(picked, separator), nil
+}
@@ -0,0 +1,132 @@
+package keygen
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/binary"
+	"encoding/pem"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// openSSHMagic is the prefix of the openssh-key-v1 private key format.
+const openSSHMagic = "openssh-key-v1\x00"
+
+// SSHKeyPair generates an SSH key pair of the given type, which is either ed25519 or rsa.
+// The private key is returned in the OpenSSH private key format and the public key
+// in the authorized_keys format, followed by the comment when it is not empty.
+func SSHKeyPair(keyType string, bits int, comment string) ([]byte, []byte, error) {
+	if keyType != KeyTypeEd25519 && keyType != KeyTypeRSA {
+		return nil, nil, ErrUnknownKeyType(keyType, strings.Join([]string{KeyTypeEd25519, KeyTypeRSA}, ", "))
+	}
+
+	key, err := generateKey(keyType, bits)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	publicKey, err := ssh.NewPublicKey(key.Public())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	privateKey, err := marshalOpenSSHPrivateKey(key, publicKey, comment)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	authorizedKey := bytes.TrimSuffix(ssh.MarshalAuthorizedKey(publicKey), []byte("\n"))
+	if comment != "" {
+		authorizedKey = append(authorizedKey, []byte(" "+comment)...)
+	}
+	authorizedKey = append(authorizedKey, '\n')
+
+	return privateKey, authorizedKey, nil
+}
+
+// marshalOpenSSHPrivateKey encodes the private key in the unencrypted openssh-key-v1 format,
+// as documented in https://github.com/openssh/openssh-portable/blob/master/PROTOCOL.key
+func marshalOpenSSHPrivateKey(key interface{}, publicKey ssh.PublicKey, comment string) ([]byte, error) {
+	var check [4]byte
+	_, err := rand.Read(check[:])
+	if err != nil {
+		return nil, err
+	}
+	checkInt := binary.BigEndian.Uint32(check[:])
+
+	var block []byte
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		block = ssh.Marshal(struct {
+			Check1  uint32
+			Check2  uint32
+			KeyType string
+			Pub     []byte
+			Priv    []byte
+			Comment string
+		}{
+			Check1:  checkInt,
+			Check2:  checkInt,
+			KeyType: ssh.KeyAlgoED25519,
+			Pub:     k.Public().(ed25519.PublicKey),
+			Priv:    k,
+			Comment: comment,
+		})
+	case *rsa.PrivateKey:
+		k.Precompute()
+		block = ssh.Marshal(struct {
+			Check1  uint32
+			Check2  uint32
+			KeyType string
+			N       *big.Int
+			E       *big.Int
+			D       *big.Int
+			Iqmp    *big.Int
+			P       *big.Int
+			Q       *big.Int
+			Comment string
+		}{
+			Check1:  checkInt,
+			Check2:  checkInt,
+			KeyType: ssh.KeyAlgoRSA,
+			N:       k.N,
+			E:       big.NewInt(int64(k.E)),
+			D:       k.D,
+			Iqmp:    k.Precomputed.Qinv,
+			P:       k.Primes[0],
+			Q:       k.Primes[1],
+			Comment: comment,
+		})
+	default:
+		return nil, ErrUnknownKeyType(publicKey.Type(), strings.Join([]string{KeyTypeEd25519, KeyTypeRSA}, ", "))
+	}
+
+	// Without a cipher, the block size is 8 and the padding consists of the bytes 1, 2, 3, ...
+	for i := 1; len(block)%8 != 0; i++ {
+		block = append(block, byte(i))
+	}
+
+	envelope := ssh.Marshal(struct {
+		CipherName   string
+		KdfName      string
+		KdfOpts      string
+		NumKeys      uint32
+		PubKey       []byte
+		PrivKeyBlock []byte
+	}{
+		CipherName:   "none",
+		KdfName:      "none",
+		NumKeys:      1,
+		PubKey:       publicKey.Marshal(),
+		PrivKeyBlock: block,
+	})
+
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "OPENSSH PRIVATE KEY",
+		Bytes: append([]byte(openSSHMagic), envelope...),
+	}), nil
+}
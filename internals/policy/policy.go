@@ -0,0 +1,251 @@
+// Package policy enforces password policies on the values of secrets.
+//
+// A policy is stored as a YAML secret named .policy in a repository or
+// directory and applies to all secrets below it, unless a directory closer
+// to the secret has a policy of its own.
+package policy
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/secrethub/secrethub-go/internals/errio"
+	"github.com/secrethub/secrethub-go/pkg/randchar"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Errors
+var (
+	errPolicy = errio.Namespace("policy")
+
+	ErrInvalidPolicy  = errPolicy.Code("invalid_policy").ErrorPref("invalid policy: %v")
+	ErrUnknownCharset = errPolicy.Code("unknown_charset").ErrorPref("unknown charset in policy: %s")
+	ErrInvalidMaxAge  = errPolicy.Code("invalid_max_age").ErrorPref("invalid max_age %s: use a duration such as 90d or 12h")
+	ErrInvalidMinimum = errPolicy.Code("invalid_minimum").ErrorPref("invalid minimum for charset %s: must be positive")
+)
+
+// FileName is the name of the secret that holds the policy of a repository or directory.
+const FileName = ".policy"
+
+// Policy describes the requirements the value of a secret must meet.
+// All fields are optional; an empty policy accepts every value.
+type Policy struct {
+	// Length is the minimum length of a value.
+	Length int `yaml:"length,omitempty"`
+	// Charsets are the names of the character sets a value may consist of.
+	Charsets []string `yaml:"charsets,omitempty"`
+	// Min is the minimum number of characters a value must contain, by charset name.
+	Min map[string]int `yaml:"min,omitempty"`
+	// ForbiddenWords must not occur in a value, regardless of case.
+	ForbiddenWords []string `yaml:"forbidden_words,omitempty"`
+	// MinEntropy is the minimum estimated entropy of a value in bits.
+	MinEntropy float64 `yaml:"min_entropy,omitempty"`
+	// MaxAge is the maximum age of the latest version of a secret, e.g. 90d.
+	MaxAge string `yaml:"max_age,omitempty"`
+
+	charset randchar.Charset
+	mins    []minimum
+	maxAge  time.Duration
+}
+
+type minimum struct {
+	name    string
+	count   int
+	charset randchar.Charset
+}
+
+// Parse parses and validates a YAML encoded policy.
+func Parse(raw []byte) (*Policy, error) {
+	var p Policy
+	err := yaml.UnmarshalStrict(raw, &p)
+	if err != nil {
+		return nil, ErrInvalidPolicy(err)
+	}
+
+	if p.Length < 0 {
+		return nil, ErrInvalidPolicy("length cannot be negative")
+	}
+
+	for _, name := range p.Charsets {
+		charset, ok := randchar.CharsetByName(name)
+		if !ok {
+			return nil, ErrUnknownCharset(name)
+		}
+		p.charset = p.charset.Add(charset)
+	}
+
+	names := make([]string, 0, len(p.Min))
+	for name := range p.Min {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		charset, ok := randchar.CharsetByName(name)
+		if !ok {
+			return nil, ErrUnknownCharset(name)
+		}
+		if p.Min[name] < 1 {
+			return nil, ErrInvalidMinimum(name)
+		}
+		if len(p.Charsets) > 0 && !charset.IsSubset(p.charset) {
+			return nil, ErrInvalidPolicy(fmt.Sprintf("min charset %s is not part of the charsets", name))
+		}
+		p.mins = append(p.mins, minimum{name: name, count: p.Min[name], charset: charset})
+	}
+
+	if p.MaxAge != "" {
+		p.maxAge, err = ParseDuration(p.MaxAge)
+		if err != nil || p.maxAge <= 0 {
+			return nil, ErrInvalidMaxAge(p.MaxAge)
+		}
+	}
+
+	return &p, nil
+}
+
+// Charset returns the set of characters to generate values from.
+// When the policy does not restrict the characters, this is the fallback
+// extended with the charsets of the minimum counts.
+func (p *Policy) Charset(fallback randchar.Charset) randchar.Charset {
+	if len(p.Charsets) > 0 {
+		return p.charset
+	}
+	for _, min := range p.mins {
+		fallback = fallback.Add(min.charset)
+	}
+	return fallback
+}
+
+// Options returns the options to configure a random generator with,
+// so that generated values meet the minimum counts of the policy.
+func (p *Policy) Options() []randchar.Option {
+	options := make([]randchar.Option, len(p.mins))
+	for i, min := range p.mins {
+		options[i] = randchar.Min(min.count, min.charset)
+	}
+	return options
+}
+
+// Check returns a description of every requirement that the value violates.
+// The age is the time since the value was written and is only checked when
+// the policy has a maximum age.
+func (p *Policy) Check(value []byte, age time.Duration) []string {
+	var violations []string
+
+	if len(value) < p.Length {
+		violations = append(violations, fmt.Sprintf("is %d characters long, the minimum is %d", len(value), p.Length))
+	}
+
+	if len(p.Charsets) > 0 {
+		var invalid []byte
+		for _, char := range value {
+			if !contains(p.charset, char) && bytes.IndexByte(invalid, char) == -1 {
+				invalid = append(invalid, char)
+			}
+		}
+		if len(invalid) > 0 {
+			violations = append(violations, fmt.Sprintf("contains %d character(s) outside of the charsets %s", len(invalid), strings.Join(p.Charsets, ", ")))
+		}
+	}
+
+	for _, min := range p.mins {
+		count := 0
+		for _, char := range value {
+			if contains(min.charset, char) {
+				count++
+			}
+		}
+		if count < min.count {
+			violations = append(violations, fmt.Sprintf("contains %d %s character(s), the minimum is %d", count, min.name, min.count))
+		}
+	}
+
+	lower := strings.ToLower(string(value))
+	for _, word := range p.ForbiddenWords {
+		if word != "" && strings.Contains(lower, strings.ToLower(word)) {
+			violations = append(violations, fmt.Sprintf("contains the forbidden word %q", word))
+		}
+	}
+
+	if p.MinEntropy > 0 {
+		entropy := Entropy(value)
+		if entropy < p.MinEntropy {
+			violations = append(violations, fmt.Sprintf("has an estimated entropy of %.0f bits, the minimum is %.0f", entropy, p.MinEntropy))
+		}
+	}
+
+	if p.maxAge > 0 && age > p.maxAge {
+		violations = append(violations, fmt.Sprintf("is %s old, the maximum age is %s", formatDays(age), p.MaxAge))
+	}
+
+	return violations
+}
+
+// Entropy estimates the entropy of a value in bits, assuming every character
+// is picked at random from the classes of characters that occur in the value.
+// This overestimates the strength of human chosen values, so it is best
+// combined with a list of forbidden words.
+func Entropy(value []byte) float64 {
+	var lower, upper, digit, other bool
+	for _, char := range value {
+		switch {
+		case char >= 'a' && char <= 'z':
+			lower = true
+		case char >= 'A' && char <= 'Z':
+			upper = true
+		case char >= '0' && char <= '9':
+			digit = true
+		default:
+			other = true
+		}
+	}
+
+	pool := 0
+	if lower {
+		pool += 26
+	}
+	if upper {
+		pool += 26
+	}
+	if digit {
+		pool += 10
+	}
+	if other {
+		// The number of printable ASCII characters that are not letters or digits.
+		pool += 33
+	}
+	if pool == 0 {
+		return 0
+	}
+
+	return float64(len(value)) * math.Log2(float64(pool))
+}
+
+// ParseDuration parses a duration like time.ParseDuration does,
+// but also accepts a whole number of days, e.g. 90d.
+func ParseDuration(s string) (time.Duration, error) {
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// contains returns whether the charset contains the character.
+func contains(charset randchar.Charset, char byte) bool {
+	return randchar.NewCharset(string(char)).IsSubset(charset)
+}
+
+// formatDays formats a duration as a whole number of days.
+func formatDays(d time.Duration) string {
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
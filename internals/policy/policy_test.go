@@ -0,0 +1,140 @@
+package policy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/randchar"
+)
+
+func TestParse(t *testing.T) {
+	cases := map[string]struct {
+		raw string
+		err error
+	}{
+		"full policy": {
+			raw: "length: 16\ncharsets: [alphanumeric, symbols]\nmin: {numeric: 2}\nforbidden_words: [password]\nmin_entropy: 80\nmax_age: 90d",
+		},
+		"empty policy": {
+			raw: "",
+		},
+		"unknown charset": {
+			raw: "charsets: [emoji]",
+			err: ErrUnknownCharset("emoji"),
+		},
+		"unknown min charset": {
+			raw: "min: {emoji: 1}",
+			err: ErrUnknownCharset("emoji"),
+		},
+		"zero minimum": {
+			raw: "min: {numeric: 0}",
+			err: ErrInvalidMinimum("numeric"),
+		},
+		"min outside charsets": {
+			raw: "charsets: [lowercase]\nmin: {numeric: 1}",
+			err: ErrInvalidPolicy("min charset numeric is not part of the charsets"),
+		},
+		"invalid max age": {
+			raw: "max_age: 3 months",
+			err: ErrInvalidMaxAge("3 months"),
+		},
+		"negative length": {
+			raw: "length: -1",
+			err: ErrInvalidPolicy("length cannot be negative"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse([]byte(tc.raw))
+
+			assert.Equal(t, err, tc.err)
+		})
+	}
+}
+
+func TestPolicy_Check(t *testing.T) {
+	cases := map[string]struct {
+		policy   string
+		value    string
+		age      time.Duration
+		expected []string
+	}{
+		"empty policy": {
+			value: "a",
+		},
+		"too short": {
+			policy:   "length: 10",
+			value:    "abc",
+			expected: []string{"is 3 characters long, the minimum is 10"},
+		},
+		"outside charsets": {
+			policy:   "charsets: [lowercase, numeric]",
+			value:    "abc-123-ABC",
+			expected: []string{"contains 4 character(s) outside of the charsets lowercase, numeric"},
+		},
+		"minimum counts": {
+			policy:   "min: {numeric: 2, uppercase: 1}",
+			value:    "abc1",
+			expected: []string{"contains 1 numeric character(s), the minimum is 2", "contains 0 uppercase character(s), the minimum is 1"},
+		},
+		"forbidden word": {
+			policy:   "forbidden_words: [secret]",
+			value:    "MySecretValue",
+			expected: []string{`contains the forbidden word "secret"`},
+		},
+		"low entropy": {
+			policy:   "min_entropy: 60",
+			value:    "abcdefgh",
+			expected: []string{"has an estimated entropy of 38 bits, the minimum is 60"},
+		},
+		"too old": {
+			policy:   "max_age: 30d",
+			value:    "value",
+			age:      45 * 24 * time.Hour,
+			expected: []string{"is 45d old, the maximum age is 30d"},
+		},
+		"compliant": {
+			policy: "length: 12\ncharsets: [alphanumeric]\nmin: {numeric: 1}\nmin_entropy: 60\nmax_age: 24h",
+			value:  "abcdefghijk1",
+			age:    time.Hour,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p, err := Parse([]byte(tc.policy))
+			assert.OK(t, err)
+
+			actual := p.Check([]byte(tc.value), tc.age)
+
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
+
+func TestPolicy_Options(t *testing.T) {
+	p, err := Parse([]byte("length: 20\nmin: {symbols: 5}"))
+	assert.OK(t, err)
+
+	rand, err := randchar.NewRand(p.Charset(randchar.Alphanumeric), p.Options()...)
+	assert.OK(t, err)
+
+	value, err := rand.Generate(p.Length)
+	assert.OK(t, err)
+	assert.Equal(t, p.Check(value, 0), []string(nil))
+}
+
+func TestParseDuration(t *testing.T) {
+	d, err := ParseDuration("90d")
+	assert.OK(t, err)
+	assert.Equal(t, d, 90*24*time.Hour)
+
+	d, err = ParseDuration("36h")
+	assert.OK(t, err)
+	assert.Equal(t, d, 36*time.Hour)
+
+	_, err = ParseDuration("d")
+	assert.Equal(t, err != nil, true)
+}
@@ -22,8 +22,11 @@ func NewACLCommand(io ui.IO, newClient newClientFunc) *ACLCommand {
 // Register registers the command and its sub-commands on the provided Registerer.
 func (cmd *ACLCommand) Register(r cli.Registerer) {
 	clause := r.Command("acl", "Manage access rules on directories.")
+	NewACLApplyCommand(cmd.io, cmd.newClient).Register(clause)
 	NewACLCheckCommand(cmd.io, cmd.newClient).Register(clause)
 	NewACLListCommand(cmd.io, cmd.newClient).Register(clause)
+	NewACLPlanCommand(cmd.io, cmd.newClient).Register(clause)
 	NewACLRmCommand(cmd.io, cmd.newClient).Register(clause)
 	NewACLSetCommand(cmd.io, cmd.newClient).Register(clause)
+	NewACLSuggestCommand(cmd.io, cmd.newClient).Register(clause)
 }
@@ -0,0 +1,112 @@
+package secrethub
+
+import (
+	"fmt"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+)
+
+// ACLApplyCommand changes the access rules to match an ACL file.
+type ACLApplyCommand struct {
+	io        ui.IO
+	file      string
+	prune     bool
+	force     bool
+	newClient newClientFunc
+}
+
+// NewACLApplyCommand creates a new ACLApplyCommand.
+func NewACLApplyCommand(io ui.IO, newClient newClientFunc) *ACLApplyCommand {
+	return &ACLApplyCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *ACLApplyCommand) Register(r cli.Registerer) {
+	clause := r.Command("apply", "Change the access rules to match an ACL file.")
+	clause.HelpLong(aclFileHelp + "\n\n" +
+		"The changes are shown in the same way as by the plan command and are only made after confirmation. " +
+		"Access rules are added and changed before any rule is removed.")
+	clause.Flags().StringVarP(&cmd.file, "file", "f", defaultACLFile, "The path to the ACL file.")
+	clause.Flags().BoolVar(&cmd.prune, "prune", false, "Remove the access rules that are not in the file.")
+	clause.Flags().BoolVar(&cmd.force, "force", false, "Apply the changes without asking for confirmation.")
+
+	clause.BindAction(cmd.Run)
+	clause.BindArguments(nil)
+}
+
+// Run makes the changes needed to make the access rules match the file.
+func (cmd *ACLApplyCommand) Run() error {
+	declared, err := readACLFile(cmd.file)
+	if err != nil {
+		return err
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	plan, err := planACL(client, declared, cmd.prune)
+	if err != nil {
+		return err
+	}
+
+	err = plan.print(cmd.io.Output())
+	if err != nil {
+		return err
+	}
+	if len(plan.changes) == 0 {
+		return nil
+	}
+
+	if !cmd.force {
+		fmt.Fprintln(cmd.io.Output())
+		confirmed, err := ui.AskYesNo(
+			cmd.io,
+			"[WARNING] This changes which accounts can read and/or modify secrets. Do you want to apply these changes?",
+			ui.DefaultNo,
+		)
+		if err != nil {
+			return err
+		}
+
+		if !confirmed {
+			fmt.Fprintln(cmd.io.Output(), "Aborting.")
+			return nil
+		}
+	}
+
+	fmt.Fprintln(cmd.io.Output())
+
+	// Rules are removed last, so accounts never lose access they should keep.
+	for _, change := range plan.changes {
+		if change.kind == aclChangeRemove {
+			continue
+		}
+
+		fmt.Fprintf(cmd.io.Output(), "Setting access rule for %s at %s with %s\n", change.rule.account, change.rule.path, change.rule.permission)
+		_, err = client.AccessRules().Set(change.rule.path.Value(), change.rule.permission.String(), change.rule.account.Value())
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, change := range plan.changes {
+		if change.kind != aclChangeRemove {
+			continue
+		}
+
+		fmt.Fprintf(cmd.io.Output(), "Removing access rule for %s at %s\n", change.rule.account, change.rule.path)
+		err = client.AccessRules().Delete(change.rule.path.Value(), change.rule.account.Value())
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Apply complete! %d added, %d changed, %d removed.\n", plan.count(aclChangeAdd), plan.count(aclChangeUpdate), plan.count(aclChangeRemove))
+	return nil
+}
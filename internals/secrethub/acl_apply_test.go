@@ -0,0 +1,155 @@
+package secrethub
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestACLApplyCommand_Run(t *testing.T) {
+	plan := "  ~ namespace/repo/app    dev1              read -> write\n" +
+		"  + namespace/repo/app    s-Zp3hGk9sVxQa    read\n"
+	prompt := "[WARNING] This changes which accounts can read and/or modify secrets. Do you want to apply these changes? [y/N]: "
+
+	cases := map[string]struct {
+		file            string
+		prune           bool
+		force           bool
+		in              string
+		askErr          error
+		err             error
+		out             string
+		promptOut       string
+		expectedSet     map[string]string
+		expectedRemoved []string
+	}{
+		"apply": {
+			file: testACLFile,
+			in:   "y",
+			out: plan +
+				"\n" +
+				"Plan: 1 to add, 1 to change, 0 to remove.\n" +
+				"1 access rule not in the file will be kept. Use --prune to remove them.\n" +
+				"\n\n" +
+				"Setting access rule for dev1 at namespace/repo/app with write\n" +
+				"Setting access rule for s-Zp3hGk9sVxQa at namespace/repo/app with read\n" +
+				"Apply complete! 1 added, 1 changed, 0 removed.\n",
+			promptOut: prompt,
+			expectedSet: map[string]string{
+				"namespace/repo/app dev1":           "write",
+				"namespace/repo/app s-Zp3hGk9sVxQa": "read",
+			},
+		},
+		"prune": {
+			file:  testACLFile,
+			prune: true,
+			force: true,
+			out: plan +
+				"  - namespace/repo/old    intern            write\n" +
+				"\n" +
+				"Plan: 1 to add, 1 to change, 1 to remove.\n" +
+				"\n" +
+				"Setting access rule for dev1 at namespace/repo/app with write\n" +
+				"Setting access rule for s-Zp3hGk9sVxQa at namespace/repo/app with read\n" +
+				"Removing access rule for intern at namespace/repo/old\n" +
+				"Apply complete! 1 added, 1 changed, 1 removed.\n",
+			expectedSet: map[string]string{
+				"namespace/repo/app dev1":           "write",
+				"namespace/repo/app s-Zp3hGk9sVxQa": "read",
+			},
+			expectedRemoved: []string{"namespace/repo/old intern"},
+		},
+		"prune keeps own admin rule": {
+			file: "rules:\n" +
+				"  - path: namespace/repo/app\n    account: dev1\n    permission: write\n" +
+				"  - path: namespace/repo/app\n    account: s-Zp3hGk9sVxQa\n    permission: read\n",
+			prune: true,
+			force: true,
+			out: plan +
+				"  - namespace/repo/old    intern            write\n" +
+				"\n" +
+				"Plan: 1 to add, 1 to change, 1 to remove.\n" +
+				"Your admin rule on namespace/repo is not in the file, but will be kept, as you would lose access to the repository.\n" +
+				"\n" +
+				"Setting access rule for dev1 at namespace/repo/app with write\n" +
+				"Setting access rule for s-Zp3hGk9sVxQa at namespace/repo/app with read\n" +
+				"Removing access rule for intern at namespace/repo/old\n" +
+				"Apply complete! 1 added, 1 changed, 1 removed.\n",
+			expectedSet: map[string]string{
+				"namespace/repo/app dev1":           "write",
+				"namespace/repo/app s-Zp3hGk9sVxQa": "read",
+			},
+			expectedRemoved: []string{"namespace/repo/old intern"},
+		},
+		"without admin": {
+			file:  "rules:\n  - path: namespace/repo\n    account: owner\n    permission: write\n",
+			force: true,
+			err:   ErrACLWithoutAdmin("namespace/repo", "namespace/repo"),
+		},
+		"abort": {
+			file: testACLFile,
+			in:   "n",
+			out: plan +
+				"\n" +
+				"Plan: 1 to add, 1 to change, 0 to remove.\n" +
+				"1 access rule not in the file will be kept. Use --prune to remove them.\n" +
+				"\n" +
+				"Aborting.\n",
+			promptOut: prompt,
+		},
+		"no changes": {
+			file: "rules:\n  - path: namespace/repo\n    account: owner\n    permission: admin\n",
+			out: "No changes. The access rules match the file.\n" +
+				"2 access rules not in the file will be kept. Use --prune to remove them.\n",
+		},
+		"ask error": {
+			file:   testACLFile,
+			askErr: ui.ErrCannotAsk,
+			err:    ui.ErrCannotAsk,
+			out: plan +
+				"\n" +
+				"Plan: 1 to add, 1 to change, 0 to remove.\n" +
+				"1 access rule not in the file will be kept. Use --prune to remove them.\n" +
+				"\n",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			dir, cleanup := testdata.tempDir(t)
+			defer cleanup()
+			file := writeACLTestFile(t, dir, tc.file)
+
+			set := map[string]string{}
+			var removed []string
+			io := fakeui.NewIO(t)
+			io.PromptIn.Buffer = bytes.NewBufferString(tc.in)
+			io.PromptErr = tc.askErr
+			cmd := ACLApplyCommand{
+				io:        io,
+				file:      file,
+				prune:     tc.prune,
+				force:     tc.force,
+				newClient: newACLTestClient(t, set, &removed),
+			}
+
+			// Act
+			err := cmd.Run()
+
+			// Assert
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, io.Out.String(), tc.out)
+			assert.Equal(t, io.PromptOut.String(), tc.promptOut)
+			if tc.expectedSet == nil {
+				tc.expectedSet = map[string]string{}
+			}
+			assert.Equal(t, set, tc.expectedSet)
+			assert.Equal(t, removed, tc.expectedRemoved)
+		})
+	}
+}
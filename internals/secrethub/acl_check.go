@@ -1,21 +1,28 @@
 package secrethub
 
 import (
+	"encoding/csv"
 	"fmt"
 	"sort"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/secrethub/secrethub-cli/internals/cli"
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
 
 	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
 	"github.com/secrethub/secrethub-go/pkg/secretpath"
+
+	"github.com/spf13/cobra"
 )
 
 // ACLCheckCommand prints the access level(s) on a given directory.
 type ACLCheckCommand struct {
 	path        api.DirPath
 	accountName api.AccountName
+	recursive   bool
+	format      string
 	io          ui.IO
 	newClient   newClientFunc
 }
@@ -31,6 +38,13 @@ func NewACLCheckCommand(io ui.IO, newClient newClientFunc) *ACLCheckCommand {
 // Register registers the command, arguments and flags on the provided Registerer.
 func (cmd *ACLCheckCommand) Register(r cli.Registerer) {
 	clause := r.Command("check", "Checks the effective permission of accounts on a path.")
+	clause.HelpLong("With --recursive, the effective permissions on the directory and all directories below it are shown as a matrix of directories and accounts, " +
+		"e.g. for an access review. Directories that have exactly the same permissions as their parent directory are left out, as they only inherit permissions.")
+	clause.Flags().BoolVarP(&cmd.recursive, "recursive", "r", false, "Check the effective permissions on all directories below the path as well.")
+	clause.Flags().StringVar(&cmd.format, "output-format", formatTable, "Specify the format in which to output the permissions. Options are: table, csv and json. The csv and json formats contain the matrix of directories and accounts, also without --recursive.")
+	_ = clause.Cmd.RegisterFlagCompletionFunc("output-format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{formatTable, formatCSV, formatJSON}, cobra.ShellCompDirectiveDefault
+	})
 
 	clause.BindAction(cmd.Run)
 	clause.BindArguments([]cli.Argument{
@@ -41,6 +55,13 @@ func (cmd *ACLCheckCommand) Register(r cli.Registerer) {
 
 // Run prints the access level(s) on the given directory.
 func (cmd *ACLCheckCommand) Run() error {
+	if cmd.format != formatTable && cmd.format != formatCSV && cmd.format != formatJSON {
+		return errNoSuchFormat(cmd.format)
+	}
+	if cmd.recursive || cmd.format != formatTable {
+		return cmd.runMatrix()
+	}
+
 	levels, err := cmd.listLevels()
 	if err != nil {
 		return err
@@ -107,3 +128,151 @@ func (cmd *ACLCheckCommand) listLevels() ([]*api.AccessLevel, error) {
 	}
 	return nil, listLevelsErr
 }
+
+// aclMatrixRow contains the effective permissions of accounts on a directory.
+type aclMatrixRow struct {
+	path        string
+	permissions map[string]api.Permission
+}
+
+// runMatrix prints the effective permissions of all accounts on the directories.
+func (cmd *ACLCheckCommand) runMatrix() error {
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	var rows []aclMatrixRow
+	if cmd.recursive {
+		tree, err := client.Dirs().GetTree(cmd.path.Value(), -1, false)
+		if err != nil {
+			return err
+		}
+
+		rows, err = cmd.matrixRows(client, cmd.path.Value(), tree.RootDir, nil)
+		if err != nil {
+			return err
+		}
+	} else {
+		levels, err := cmd.listLevels()
+		if err != nil {
+			return err
+		}
+		rows = []aclMatrixRow{{path: cmd.path.Value(), permissions: cmd.permissions(levels)}}
+	}
+
+	accountSet := make(map[string]bool)
+	for _, row := range rows {
+		for account := range row.permissions {
+			accountSet[account] = true
+		}
+	}
+	if cmd.accountName != "" {
+		accountSet = map[string]bool{cmd.accountName.Value(): true}
+	}
+	accounts := make([]string, 0, len(accountSet))
+	for account := range accountSet {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+
+	switch cmd.format {
+	case formatJSON:
+		output := make([]aclMatrixOutput, len(rows))
+		for i, row := range rows {
+			output[i] = aclMatrixOutput{
+				Path:        row.path,
+				Permissions: make(map[string]string, len(accounts)),
+			}
+			for _, account := range accounts {
+				output[i].Permissions[account] = row.permissions[account].String()
+			}
+		}
+		return newPrettyJSONFormatter(cmd.io.Output()).WriteValue(output)
+	case formatCSV:
+		w := csv.NewWriter(cmd.io.Output())
+		err = w.Write(append([]string{"path"}, accounts...))
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			err = w.Write(aclMatrixCells(row, accounts))
+			if err != nil {
+				return err
+			}
+		}
+		w.Flush()
+		return w.Error()
+	default:
+		tabWriter := tabwriter.NewWriter(cmd.io.Output(), 0, 4, 4, ' ', 0)
+		fmt.Fprintf(tabWriter, "%s\t%s\n", "PATH", strings.Join(accounts, "\t"))
+		for _, row := range rows {
+			fmt.Fprintln(tabWriter, strings.Join(aclMatrixCells(row, accounts), "\t"))
+		}
+		return tabWriter.Flush()
+	}
+}
+
+// matrixRows returns the rows of the directory and all directories below it,
+// leaving out the directories that have the same permissions as their parent.
+func (cmd *ACLCheckCommand) matrixRows(client secrethub.ClientInterface, path string, dir *api.Dir, parent map[string]api.Permission) ([]aclMatrixRow, error) {
+	levels, err := client.AccessRules().ListLevels(path)
+	if err != nil {
+		return nil, err
+	}
+	permissions := cmd.permissions(levels)
+
+	var rows []aclMatrixRow
+	if parent == nil || !equalPermissions(permissions, parent) {
+		rows = append(rows, aclMatrixRow{path: path, permissions: permissions})
+	}
+
+	sort.Sort(api.SortDirByName(dir.SubDirs))
+	for _, subDir := range dir.SubDirs {
+		subRows, err := cmd.matrixRows(client, secretpath.Join(path, subDir.Name), subDir, permissions)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, subRows...)
+	}
+	return rows, nil
+}
+
+// permissions returns the permission of every account in the levels.
+func (cmd *ACLCheckCommand) permissions(levels []*api.AccessLevel) map[string]api.Permission {
+	permissions := make(map[string]api.Permission, len(levels))
+	for _, level := range levels {
+		if level.Permission != api.PermissionNone {
+			permissions[level.Account.Name.Value()] = level.Permission
+		}
+	}
+	return permissions
+}
+
+// equalPermissions returns whether the accounts have the same permissions in a and b.
+func equalPermissions(a, b map[string]api.Permission) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for account, permission := range a {
+		if b[account] != permission {
+			return false
+		}
+	}
+	return true
+}
+
+// aclMatrixCells returns the path and the permissions of the accounts on a directory.
+func aclMatrixCells(row aclMatrixRow, accounts []string) []string {
+	cells := []string{row.path}
+	for _, account := range accounts {
+		cells = append(cells, row.permissions[account].String())
+	}
+	return cells
+}
+
+// aclMatrixOutput is the JSON representation of the permissions on a directory.
+type aclMatrixOutput struct {
+	Path        string            `json:"path"`
+	Permissions map[string]string `json:"permissions"`
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
 
 	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/api/uuid"
 	"github.com/secrethub/secrethub-go/internals/assert"
 	"github.com/secrethub/secrethub-go/pkg/secrethub"
 	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
@@ -107,6 +108,9 @@ func TestACLCheckCommand_Run(t *testing.T) {
 			// Setup
 			io := fakeui.NewIO(t)
 			tc.cmd.io = io
+			if tc.cmd.format == "" {
+				tc.cmd.format = formatTable
+			}
 
 			lister := tc.lister
 			var argPath string
@@ -131,3 +135,129 @@ func TestACLCheckCommand_Run(t *testing.T) {
 		})
 	}
 }
+
+func TestACLCheckCommand_Run_matrix(t *testing.T) {
+	rootID := uuid.New()
+	appID := uuid.New()
+	prodID := uuid.New()
+	docsID := uuid.New()
+	prod := &api.Dir{DirID: prodID, Name: "prod"}
+	app := &api.Dir{DirID: appID, Name: "app", SubDirs: []*api.Dir{prod}}
+	docs := &api.Dir{DirID: docsID, Name: "docs"}
+	tree := &api.Tree{
+		ParentPath: "namespace",
+		RootDir:    &api.Dir{DirID: rootID, Name: "repo", SubDirs: []*api.Dir{docs, app}},
+	}
+
+	level := func(name string, permission api.Permission) *api.AccessLevel {
+		return &api.AccessLevel{Account: &api.Account{Name: api.AccountName(name)}, Permission: permission}
+	}
+	levels := map[string][]*api.AccessLevel{
+		"namespace/repo":          {level("owner", api.PermissionAdmin)},
+		"namespace/repo/app":      {level("owner", api.PermissionAdmin), level("dev1", api.PermissionWrite)},
+		"namespace/repo/app/prod": {level("owner", api.PermissionAdmin), level("dev1", api.PermissionWrite)},
+		"namespace/repo/docs":     {level("owner", api.PermissionAdmin), level("dev1", api.PermissionNone)},
+	}
+
+	cases := map[string]struct {
+		cmd ACLCheckCommand
+		out string
+		err error
+	}{
+		"table": {
+			cmd: ACLCheckCommand{
+				recursive: true,
+				format:    formatTable,
+			},
+			out: "PATH                  dev1     owner\n" +
+				"namespace/repo        none     admin\n" +
+				"namespace/repo/app    write    admin\n",
+		},
+		"csv": {
+			cmd: ACLCheckCommand{
+				recursive: true,
+				format:    formatCSV,
+			},
+			out: "path,dev1,owner\n" +
+				"namespace/repo,none,admin\n" +
+				"namespace/repo/app,write,admin\n",
+		},
+		"json": {
+			cmd: ACLCheckCommand{
+				recursive: true,
+				format:    formatJSON,
+			},
+			out: `[
+    {
+        "path": "namespace/repo",
+        "permissions": {
+            "dev1": "none",
+            "owner": "admin"
+        }
+    },
+    {
+        "path": "namespace/repo/app",
+        "permissions": {
+            "dev1": "write",
+            "owner": "admin"
+        }
+    }
+]
+`,
+		},
+		"account": {
+			cmd: ACLCheckCommand{
+				recursive:   true,
+				accountName: "dev1",
+				format:      formatCSV,
+			},
+			out: "path,dev1\n" +
+				"namespace/repo,none\n" +
+				"namespace/repo/app,write\n",
+		},
+		"not recursive": {
+			cmd: ACLCheckCommand{
+				format: formatCSV,
+			},
+			out: "path,owner\n" +
+				"namespace/repo,admin\n",
+		},
+		"invalid format": {
+			cmd: ACLCheckCommand{
+				recursive: true,
+				format:    "xml",
+			},
+			err: errNoSuchFormat("xml"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			io := fakeui.NewIO(t)
+			tc.cmd.io = io
+			tc.cmd.path = "namespace/repo"
+			tc.cmd.newClient = func() (secrethub.ClientInterface, error) {
+				return fakeclient.Client{
+					AccessRuleService: &fakeclient.AccessRuleService{
+						ListLevelsFunc: func(path string) ([]*api.AccessLevel, error) {
+							return levels[path], nil
+						},
+					},
+					DirService: &fakeclient.DirService{
+						GetTreeFunc: func(path string, depth int, ancestors bool) (*api.Tree, error) {
+							return tree, nil
+						},
+					},
+				}, nil
+			}
+
+			// Act
+			err := tc.cmd.Run()
+
+			// Assert
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, io.Out.String(), tc.out)
+		})
+	}
+}
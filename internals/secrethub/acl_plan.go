@@ -0,0 +1,335 @@
+package secrethub
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Errors
+var (
+	ErrInvalidACLFile  = errMain.Code("invalid_acl_file").ErrorPref("invalid ACL file %s: %v")
+	ErrACLWithoutAdmin = errMain.Code("acl_without_admin").ErrorPref("the changes leave the repository %s without an admin: give an account admin permission on %s in the ACL file")
+)
+
+const (
+	defaultACLFile = "secrethub.acl.yml"
+
+	aclChangeAdd    = "+"
+	aclChangeUpdate = "~"
+	aclChangeRemove = "-"
+)
+
+// aclFileHelp explains the format of the ACL file.
+const aclFileHelp = "The ACL file lists the access rules of one or more repositories:\n\n" +
+	"  rules:\n" +
+	"    - path: company/app\n" +
+	"      account: developer\n" +
+	"      permission: read       # read, write or admin\n" +
+	"    - path: company/app/prod\n" +
+	"      account: s-Zp3hGk9sVxQa\n" +
+	"      permission: read\n\n" +
+	"The access rules of all repositories that occur in the file are compared with the file. " +
+	"Rules that are not in the file are kept, unless --prune is set. " +
+	"Even with --prune, your own admin rule on the root directory of a repository is never removed, " +
+	"and changes that leave a repository without an admin rule on its root directory are refused."
+
+// ACLPlanCommand prints the changes that are needed to make the access rules match an ACL file.
+type ACLPlanCommand struct {
+	io        ui.IO
+	file      string
+	prune     bool
+	newClient newClientFunc
+}
+
+// NewACLPlanCommand creates a new ACLPlanCommand.
+func NewACLPlanCommand(io ui.IO, newClient newClientFunc) *ACLPlanCommand {
+	return &ACLPlanCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *ACLPlanCommand) Register(r cli.Registerer) {
+	clause := r.Command("plan", "Show the changes needed to make the access rules match an ACL file.")
+	clause.HelpLong(aclFileHelp)
+	clause.Flags().StringVarP(&cmd.file, "file", "f", defaultACLFile, "The path to the ACL file.")
+	clause.Flags().BoolVar(&cmd.prune, "prune", false, "Plan to remove the access rules that are not in the file.")
+
+	clause.BindAction(cmd.Run)
+	clause.BindArguments(nil)
+}
+
+// Run prints the changes to the access rules.
+func (cmd *ACLPlanCommand) Run() error {
+	declared, err := readACLFile(cmd.file)
+	if err != nil {
+		return err
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	plan, err := planACL(client, declared, cmd.prune)
+	if err != nil {
+		return err
+	}
+
+	return plan.print(cmd.io.Output())
+}
+
+// aclRule is an access rule on a directory.
+type aclRule struct {
+	path       api.DirPath
+	account    api.AccountName
+	permission api.Permission
+}
+
+// key identifies the rule, as an account can only have one rule per directory.
+func (r aclRule) key() string {
+	return strings.ToLower(r.path.Value() + " " + r.account.Value())
+}
+
+// aclChange is a change to an access rule.
+type aclChange struct {
+	kind string
+	rule aclRule
+	// old is the current permission of a rule that is updated.
+	old api.Permission
+}
+
+// aclPlan contains the changes to make the access rules match the ACL file.
+type aclPlan struct {
+	changes []aclChange
+	// kept is the number of rules that are not in the file, but are not removed.
+	kept int
+	// protected are the admin rules of the authenticated account that are not in the file,
+	// but are not removed when pruning, as the account would lose access to the repository.
+	protected []aclRule
+}
+
+// count returns the number of changes of the given kind.
+func (p *aclPlan) count(kind string) int {
+	n := 0
+	for _, change := range p.changes {
+		if change.kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// print writes the changes in the plan to w.
+func (p *aclPlan) print(w io.Writer) error {
+	if len(p.changes) == 0 {
+		fmt.Fprintln(w, "No changes. The access rules match the file.")
+	} else {
+		tw := tabwriter.NewWriter(w, 0, 4, 4, ' ', 0)
+		for _, change := range p.changes {
+			permission := change.rule.permission.String()
+			if change.kind == aclChangeUpdate {
+				permission = change.old.String() + " -> " + permission
+			}
+			fmt.Fprintf(tw, "  %s %s\t%s\t%s\n", change.kind, change.rule.path, change.rule.account, permission)
+		}
+		err := tw.Flush()
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(w, "\nPlan: %d to add, %d to change, %d to remove.\n", p.count(aclChangeAdd), p.count(aclChangeUpdate), p.count(aclChangeRemove))
+	}
+
+	if p.kept > 0 {
+		fmt.Fprintf(w, "%s not in the file will be kept. Use --prune to remove them.\n", pluralize("access rule", "access rules", p.kept))
+	}
+	for _, rule := range p.protected {
+		fmt.Fprintf(w, "Your admin rule on %s is not in the file, but will be kept, as you would lose access to the repository.\n", rule.path)
+	}
+	return nil
+}
+
+// isRepoAdmin returns whether the rule gives admin permission on the root directory of a repository.
+func (r aclRule) isRepoAdmin() bool {
+	return r.permission == api.PermissionAdmin && strings.EqualFold(r.path.Value(), r.path.GetRepoPath().Value())
+}
+
+// aclFile is the format of an ACL file.
+type aclFile struct {
+	Rules []aclFileRule `yaml:"rules"`
+}
+
+// aclFileRule is an access rule in an ACL file.
+type aclFileRule struct {
+	Path       string `yaml:"path"`
+	Account    string `yaml:"account"`
+	Permission string `yaml:"permission"`
+}
+
+// readACLFile reads and validates the access rules declared in an ACL file.
+func readACLFile(filePath string) ([]aclRule, error) {
+	raw, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, ErrReadFile(filePath, err)
+	}
+
+	var file aclFile
+	err = yaml.UnmarshalStrict(raw, &file)
+	if err != nil {
+		return nil, ErrInvalidACLFile(filePath, err)
+	}
+
+	rules := make([]aclRule, len(file.Rules))
+	declared := make(map[string]bool, len(file.Rules))
+	for i, spec := range file.Rules {
+		dirPath, err := api.NewDirPath(spec.Path)
+		if err != nil {
+			return nil, ErrInvalidACLFile(filePath, fmt.Sprintf("rule %d has an invalid path %q: %v", i+1, spec.Path, err))
+		}
+
+		accountName, err := api.NewAccountName(spec.Account)
+		if err != nil {
+			return nil, ErrInvalidACLFile(filePath, fmt.Sprintf("rule %d has an invalid account %q: %v", i+1, spec.Account, err))
+		}
+
+		var permission api.Permission
+		err = permission.Set(spec.Permission)
+		if err != nil || permission == api.PermissionNone {
+			return nil, ErrInvalidACLFile(filePath, fmt.Sprintf("rule %d has an invalid permission %q: use read, write or admin", i+1, spec.Permission))
+		}
+
+		rules[i] = aclRule{
+			path:       dirPath,
+			account:    accountName,
+			permission: permission,
+		}
+		if declared[rules[i].key()] {
+			return nil, ErrInvalidACLFile(filePath, fmt.Sprintf("%s has more than one rule on %s", accountName, dirPath))
+		}
+		declared[rules[i].key()] = true
+	}
+
+	return rules, nil
+}
+
+// planACL compares the declared access rules with the current rules of the repositories they are in.
+// The current rules that are not declared are removed when prune is set, except for the admin rules
+// of the authenticated account on the root directory of a repository.
+// An error is returned when the changes leave a repository without an admin rule on its root directory.
+func planACL(client secrethub.ClientInterface, declared []aclRule, prune bool) (*aclPlan, error) {
+	var me api.AccountName
+	if prune {
+		account, err := client.Accounts().Me()
+		if err != nil {
+			return nil, err
+		}
+		me = account.Name
+	}
+
+	var repoPaths []string
+	seen := make(map[string]bool)
+	for _, rule := range declared {
+		repoPath := strings.ToLower(rule.path.GetRepoPath().Value())
+		if !seen[repoPath] {
+			seen[repoPath] = true
+			repoPaths = append(repoPaths, rule.path.GetRepoPath().Value())
+		}
+	}
+
+	current := make(map[string]aclRule)
+	var currentKeys []string
+	for _, repoPath := range repoPaths {
+		rules, err := client.AccessRules().List(repoPath, -1, false)
+		if err != nil {
+			return nil, err
+		}
+
+		tree, err := client.Dirs().GetTree(repoPath, -1, false)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rule := range rules {
+			dirPath, err := tree.AbsDirPath(rule.DirID)
+			if err != nil {
+				return nil, err
+			}
+
+			currentRule := aclRule{
+				path:       dirPath,
+				account:    rule.Account.Name,
+				permission: rule.Permission,
+			}
+			current[currentRule.key()] = currentRule
+			currentKeys = append(currentKeys, currentRule.key())
+		}
+	}
+
+	plan := &aclPlan{}
+	for _, rule := range declared {
+		currentRule, ok := current[rule.key()]
+		if !ok {
+			plan.changes = append(plan.changes, aclChange{kind: aclChangeAdd, rule: rule})
+		} else if currentRule.permission != rule.permission {
+			plan.changes = append(plan.changes, aclChange{kind: aclChangeUpdate, rule: rule, old: currentRule.permission})
+		}
+	}
+
+	isDeclared := make(map[string]bool, len(declared))
+	for _, rule := range declared {
+		isDeclared[rule.key()] = true
+	}
+	for _, key := range currentKeys {
+		if isDeclared[key] {
+			continue
+		}
+		rule := current[key]
+		if prune && rule.isRepoAdmin() && strings.EqualFold(rule.account.Value(), me.Value()) {
+			plan.protected = append(plan.protected, rule)
+		} else if prune {
+			plan.changes = append(plan.changes, aclChange{kind: aclChangeRemove, rule: rule})
+		} else {
+			plan.kept++
+		}
+	}
+
+	admins := make(map[string]int)
+	for _, key := range currentKeys {
+		if current[key].isRepoAdmin() {
+			admins[strings.ToLower(current[key].path.GetRepoPath().Value())]++
+		}
+	}
+	for _, change := range plan.changes {
+		repoPath := strings.ToLower(change.rule.path.GetRepoPath().Value())
+		if current[change.rule.key()].isRepoAdmin() {
+			admins[repoPath]--
+		}
+		if change.kind != aclChangeRemove && change.rule.isRepoAdmin() {
+			admins[repoPath]++
+		}
+	}
+	for _, repoPath := range repoPaths {
+		if n, ok := admins[strings.ToLower(repoPath)]; ok && n <= 0 {
+			return nil, ErrACLWithoutAdmin(repoPath, repoPath)
+		}
+	}
+
+	sort.SliceStable(plan.changes, func(i, j int) bool {
+		return plan.changes[i].rule.key() < plan.changes[j].rule.key()
+	})
+	return plan, nil
+}
@@ -0,0 +1,171 @@
+package secrethub
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/api/uuid"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+const testACLFile = `rules:
+  - path: namespace/repo
+    account: owner
+    permission: admin
+  - path: namespace/repo/app
+    account: dev1
+    permission: write
+  - path: namespace/repo/app
+    account: s-Zp3hGk9sVxQa
+    permission: read
+`
+
+// newACLTestClient returns a client with a repository of which the rules differ from testACLFile.
+func newACLTestClient(t *testing.T, set map[string]string, removed *[]string) func() (secrethub.ClientInterface, error) {
+	rootID := uuid.New()
+	appID := uuid.New()
+	oldID := uuid.New()
+	tree := &api.Tree{
+		ParentPath: "namespace",
+		RootDir:    &api.Dir{DirID: rootID, Name: "repo"},
+		Dirs: map[uuid.UUID]*api.Dir{
+			rootID: {DirID: rootID, Name: "repo"},
+			appID:  {DirID: appID, ParentID: &rootID, Name: "app"},
+			oldID:  {DirID: oldID, ParentID: &rootID, Name: "old"},
+		},
+	}
+	rules := []*api.AccessRule{
+		{Account: &api.Account{Name: "owner"}, DirID: rootID, Permission: api.PermissionAdmin},
+		{Account: &api.Account{Name: "dev1"}, DirID: appID, Permission: api.PermissionRead},
+		{Account: &api.Account{Name: "intern"}, DirID: oldID, Permission: api.PermissionWrite},
+	}
+
+	return func() (secrethub.ClientInterface, error) {
+		return fakeclient.Client{
+			AccountService: &fakeclient.AccountService{
+				MeFunc: func() (*api.Account, error) {
+					return &api.Account{Name: "Owner"}, nil
+				},
+			},
+			AccessRuleService: &fakeclient.AccessRuleService{
+				ListFunc: func(path string, depth int, ancestors bool) ([]*api.AccessRule, error) {
+					assert.Equal(t, path, "namespace/repo")
+					return rules, nil
+				},
+				SetFunc: func(path string, permission string, accountName string) (*api.AccessRule, error) {
+					set[path+" "+accountName] = permission
+					return &api.AccessRule{}, nil
+				},
+				DeleteFunc: func(path string, accountName string) error {
+					*removed = append(*removed, path+" "+accountName)
+					return nil
+				},
+			},
+			DirService: &fakeclient.DirService{
+				GetTreeFunc: func(path string, depth int, ancestors bool) (*api.Tree, error) {
+					return tree, nil
+				},
+			},
+		}, nil
+	}
+}
+
+// writeACLTestFile writes the ACL file to a temporary directory and returns its path.
+func writeACLTestFile(t *testing.T, dir string, content string) string {
+	file := filepath.Join(dir, defaultACLFile)
+	err := ioutil.WriteFile(file, []byte(content), 0600)
+	assert.OK(t, err)
+	return file
+}
+
+func TestACLPlanCommand_Run(t *testing.T) {
+	cases := map[string]struct {
+		file  string
+		prune bool
+		out   string
+		err   error
+	}{
+		"changes": {
+			file: testACLFile,
+			out: "  ~ namespace/repo/app    dev1              read -> write\n" +
+				"  + namespace/repo/app    s-Zp3hGk9sVxQa    read\n" +
+				"\n" +
+				"Plan: 1 to add, 1 to change, 0 to remove.\n" +
+				"1 access rule not in the file will be kept. Use --prune to remove them.\n",
+		},
+		"prune": {
+			file:  testACLFile,
+			prune: true,
+			out: "  ~ namespace/repo/app    dev1              read -> write\n" +
+				"  + namespace/repo/app    s-Zp3hGk9sVxQa    read\n" +
+				"  - namespace/repo/old    intern            write\n" +
+				"\n" +
+				"Plan: 1 to add, 1 to change, 1 to remove.\n",
+		},
+		"no changes": {
+			file: "rules:\n" +
+				"  - path: namespace/repo\n    account: owner\n    permission: admin\n" +
+				"  - path: Namespace/Repo/App\n    account: dev1\n    permission: r\n" +
+				"  - path: namespace/repo/old\n    account: intern\n    permission: write\n",
+			out: "No changes. The access rules match the file.\n",
+		},
+		"invalid permission": {
+			file: "rules:\n  - path: namespace/repo\n    account: owner\n    permission: none\n",
+			err:  ErrInvalidACLFile("<file>", `rule 1 has an invalid permission "none": use read, write or admin`),
+		},
+		"invalid path": {
+			file: "rules:\n  - path: namespace\n    account: owner\n    permission: read\n",
+			err:  ErrInvalidACLFile("<file>", `rule 1 has an invalid path "namespace": `+api.ErrInvalidDirPath("namespace").Error()),
+		},
+		"duplicate rule": {
+			file: "rules:\n" +
+				"  - path: namespace/repo\n    account: owner\n    permission: admin\n" +
+				"  - path: namespace/repo/\n    account: Owner\n    permission: read\n",
+			err: ErrInvalidACLFile("<file>", "Owner has more than one rule on namespace/repo"),
+		},
+		"unknown field": {
+			file: "rules:\n  - path: namespace/repo\n    user: owner\n",
+			err:  ErrInvalidACLFile("<file>", "yaml: unmarshal errors:\n  line 3: field user not found in type secrethub.aclFileRule"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			dir, cleanup := testdata.tempDir(t)
+			defer cleanup()
+			file := writeACLTestFile(t, dir, tc.file)
+
+			var removed []string
+			io := fakeui.NewIO(t)
+			cmd := ACLPlanCommand{
+				io:        io,
+				file:      file,
+				prune:     tc.prune,
+				newClient: newACLTestClient(t, map[string]string{}, &removed),
+			}
+
+			// Act
+			err := cmd.Run()
+
+			// Assert
+			if err != nil {
+				err = errors.New(strings.ReplaceAll(err.Error(), file, "<file>"))
+			}
+			if tc.err != nil {
+				tc.err = errors.New(tc.err.Error())
+			}
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, io.Out.String(), tc.out)
+			assert.Equal(t, len(removed), 0)
+		})
+	}
+}
@@ -0,0 +1,277 @@
+package secrethub
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/iterator"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+// Errors
+var (
+	ErrSuggestNotService = errMain.Code("suggest_not_service").ErrorPref("%s is not a service: access rules can only be suggested for service accounts")
+)
+
+const (
+	formatCommands = "commands"
+	formatYAML     = "yaml"
+)
+
+// ACLSuggestCommand suggests the narrowest access rules of a service that cover its access in the audit log.
+type ACLSuggestCommand struct {
+	io        ui.IO
+	serviceID api.AccountName
+	since     string
+	format    string
+	newClient newClientFunc
+}
+
+// NewACLSuggestCommand creates a new ACLSuggestCommand.
+func NewACLSuggestCommand(io ui.IO, newClient newClientFunc) *ACLSuggestCommand {
+	return &ACLSuggestCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *ACLSuggestCommand) Register(r cli.Registerer) {
+	clause := r.Command("suggest", "Suggest the narrowest access rules of a service, based on its audit history.")
+	clause.HelpLong("The audit log of the repository of the service is used to find the secrets the service read and wrote. " +
+		"For every directory these secrets are in, the lowest permission that covers the observed access is suggested: " +
+		"read for secrets that were only read and write for secrets that were written. " +
+		"Managing members of the repository requires admin permission on the repository. " +
+		"Rules on directories that are covered by a rule on a parent directory are left out. " +
+		"When the service has no events at all, no changes are suggested.\n\n" +
+		"The suggestions are printed as the acl set and acl rm commands that change the current access rules of the service into the suggested ones, " +
+		"or, with --output-format yaml, as the rules of an ACL file. " +
+		"As that file only contains the rules of the service, merge them into the ACL file of the repository before applying it with --prune.")
+	clause.Flags().StringVar(&cmd.since, "since", "", "Only take the access from this time on into account. Takes a date such as 2020-12-31, an RFC3339 timestamp or a duration such as 90d.")
+	clause.Flags().StringVar(&cmd.format, "output-format", formatCommands, "Specify the format in which to output the suggestions. Options are: commands and yaml.")
+	_ = clause.Cmd.RegisterFlagCompletionFunc("output-format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{formatCommands, formatYAML}, cobra.ShellCompDirectiveDefault
+	})
+
+	clause.BindAction(cmd.Run)
+	clause.BindArguments([]cli.Argument{
+		{Value: &cmd.serviceID, Name: "service-id", Required: true, Description: "The ID of the service to suggest access rules for."},
+	})
+}
+
+// Run prints the suggested access rules.
+func (cmd *ACLSuggestCommand) Run() error {
+	return cmd.run(time.Now())
+}
+
+// run prints the suggested access rules.
+func (cmd *ACLSuggestCommand) run(now time.Time) error {
+	if cmd.format != formatCommands && cmd.format != formatYAML {
+		return errNoSuchFormat(cmd.format)
+	}
+	if !cmd.serviceID.IsService() {
+		return ErrSuggestNotService(cmd.serviceID)
+	}
+
+	var since time.Time
+	var err error
+	if cmd.since != "" {
+		since, err = parseSearchTime(cmd.since, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	service, err := client.Services().Get(cmd.serviceID.Value())
+	if err != nil {
+		return err
+	}
+	repoPath := service.Repo.Path()
+
+	tree, err := client.Dirs().GetTree(repoPath.GetDirPath().Value(), -1, false)
+	if err != nil {
+		return err
+	}
+
+	events, err := cmd.serviceEvents(client, repoPath, since)
+	if err != nil {
+		return err
+	}
+
+	// Suggesting to remove every rule would lock out a service that just did not run in the period.
+	if len(events) == 0 {
+		fmt.Fprintf(cmd.io.Output(), "# No events of %s were found in the audit log of %s. "+
+			"No changes are suggested, as the service may not have run in that period.\n", cmd.serviceID, repoPath)
+		return nil
+	}
+
+	suggested, err := suggestACL(events, tree, repoPath)
+	if err != nil {
+		return err
+	}
+
+	if cmd.format == formatYAML {
+		file := aclFile{Rules: []aclFileRule{}}
+		for _, rule := range suggested {
+			file.Rules = append(file.Rules, aclFileRule{
+				Path:       rule.path.Value(),
+				Account:    cmd.serviceID.Value(),
+				Permission: rule.permission.String(),
+			})
+		}
+
+		out, err := yaml.Marshal(file)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.io.Output(), "# The access rules of %s, based on %s in the audit log of %s.\n", cmd.serviceID, pluralize("event", "events", len(events)), repoPath)
+		_, err = cmd.io.Output().Write(out)
+		return err
+	}
+
+	rules, err := client.AccessRules().List(repoPath.Value(), -1, false)
+	if err != nil {
+		return err
+	}
+
+	current := make(map[string]api.Permission)
+	var currentPaths []string
+	for _, rule := range rules {
+		if !strings.EqualFold(rule.Account.Name.Value(), cmd.serviceID.Value()) {
+			continue
+		}
+		dirPath, err := tree.AbsDirPath(rule.DirID)
+		if err != nil {
+			return err
+		}
+		current[strings.ToLower(dirPath.Value())] = rule.Permission
+		currentPaths = append(currentPaths, dirPath.Value())
+	}
+	sort.Strings(currentPaths)
+
+	fmt.Fprintf(cmd.io.Output(), "# Based on %s of %s in the audit log of %s.\n", pluralize("event", "events", len(events)), cmd.serviceID, repoPath)
+
+	isSuggested := make(map[string]bool, len(suggested))
+	changed := false
+	for _, rule := range suggested {
+		key := strings.ToLower(rule.path.Value())
+		isSuggested[key] = true
+		if permission, ok := current[key]; ok && permission == rule.permission {
+			continue
+		}
+		fmt.Fprintf(cmd.io.Output(), "secrethub acl set %s %s %s\n", rule.path, cmd.serviceID, rule.permission)
+		changed = true
+	}
+	for _, path := range currentPaths {
+		if isSuggested[strings.ToLower(path)] {
+			continue
+		}
+		fmt.Fprintf(cmd.io.Output(), "secrethub acl rm %s %s\n", path, cmd.serviceID)
+		changed = true
+	}
+
+	if !changed {
+		fmt.Fprintf(cmd.io.Output(), "# The access rules of %s already match its access.\n", cmd.serviceID)
+	}
+	return nil
+}
+
+// serviceEvents returns the events of the repository that were performed by the service since the given time.
+func (cmd *ACLSuggestCommand) serviceEvents(client secrethub.ClientInterface, repoPath api.RepoPath, since time.Time) ([]api.Audit, error) {
+	var events []api.Audit
+	iter := client.Repos().EventIterator(repoPath.Value(), &secrethub.AuditEventIteratorParams{})
+	for {
+		event, err := iter.Next()
+		if err == iterator.Done {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		// The events are returned most recent first.
+		if !since.IsZero() && event.LoggedAt.Before(since) {
+			break
+		}
+
+		if event.Actor.Type == "service" && event.Actor.Service != nil && strings.EqualFold(event.Actor.Service.ServiceID, cmd.serviceID.Value()) {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// suggestACL returns the narrowest access rules that cover the access in the events, sorted by path.
+func suggestACL(events []api.Audit, tree *api.Tree, repoPath api.RepoPath) ([]aclRule, error) {
+	required := make(map[string]aclRule)
+	require := func(path api.DirPath, permission api.Permission) {
+		key := strings.ToLower(path.Value())
+		if rule, ok := required[key]; !ok || rule.permission < permission {
+			required[key] = aclRule{path: path, permission: permission}
+		}
+	}
+
+	for _, event := range events {
+		switch event.Subject.Type {
+		case api.AuditSubjectSecret, api.AuditSubjectSecretVersion:
+			secretID, ok := auditSubjectSecretID(event)
+			if !ok {
+				// The secret no longer exists, so no access to it is needed.
+				continue
+			}
+			secret, ok := tree.Secrets[secretID]
+			if !ok {
+				continue
+			}
+			dirPath, err := tree.AbsDirPath(secret.DirID)
+			if err != nil {
+				return nil, err
+			}
+
+			if event.Action == api.AuditActionRead {
+				require(dirPath, api.PermissionRead)
+			} else {
+				require(dirPath, api.PermissionWrite)
+			}
+		case api.AuditSubjectUser, api.AuditSubjectService:
+			require(repoPath.GetDirPath(), api.PermissionAdmin)
+		}
+	}
+
+	var rules []aclRule
+	for _, rule := range required {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return strings.ToLower(rules[i].path.Value()) < strings.ToLower(rules[j].path.Value())
+	})
+
+	// A rule on a directory also applies to all directories below it.
+	var narrowest []aclRule
+	for _, rule := range rules {
+		covered := false
+		for _, parent := range narrowest {
+			if parent.permission >= rule.permission && strings.HasPrefix(strings.ToLower(rule.path.Value()), strings.ToLower(parent.path.Value())+"/") {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			narrowest = append(narrowest, rule)
+		}
+	}
+	return narrowest, nil
+}
@@ -0,0 +1,214 @@
+package secrethub
+
+import (
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/api/uuid"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestACLSuggestCommand_run(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	serviceID := "s-Zp3hGk9sVxQa"
+
+	rootID := uuid.New()
+	appID := uuid.New()
+	prodID := uuid.New()
+	docsID := uuid.New()
+	configID := uuid.New()
+	dbID := uuid.New()
+	tree := &api.Tree{
+		ParentPath: "namespace",
+		RootDir:    &api.Dir{DirID: rootID, Name: "repo"},
+		Dirs: map[uuid.UUID]*api.Dir{
+			rootID: {DirID: rootID, Name: "repo"},
+			appID:  {DirID: appID, ParentID: &rootID, Name: "app"},
+			prodID: {DirID: prodID, ParentID: &appID, Name: "prod"},
+			docsID: {DirID: docsID, ParentID: &rootID, Name: "docs"},
+		},
+		Secrets: map[uuid.UUID]*api.Secret{
+			configID: {SecretID: configID, DirID: appID, Name: "config"},
+			dbID:     {SecretID: dbID, DirID: prodID, Name: "db"},
+		},
+	}
+
+	service := api.AuditActor{Type: "service", Service: &api.Service{ServiceID: serviceID}}
+	event := func(actor api.AuditActor, action api.AuditAction, secretID uuid.UUID, loggedAt time.Time) api.Audit {
+		return api.Audit{
+			Action:   action,
+			Actor:    actor,
+			LoggedAt: loggedAt,
+			Subject: api.AuditSubject{
+				Type:          api.AuditSubjectSecretVersion,
+				SecretVersion: &api.SecretVersion{Secret: &api.Secret{SecretID: secretID}},
+			},
+		}
+	}
+	writeDB := event(service, api.AuditActionCreate, dbID, time.Date(2026, 5, 20, 0, 0, 0, 0, time.UTC))
+	readDB := event(service, api.AuditActionRead, dbID, time.Date(2026, 5, 10, 0, 0, 0, 0, time.UTC))
+	readConfig := event(service, api.AuditActionRead, configID, time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC))
+	readByUser := event(api.AuditActor{Type: "user", User: &api.User{Username: "dev1"}}, api.AuditActionRead, dbID, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC))
+	readDeleted := api.Audit{
+		Action:   api.AuditActionRead,
+		Actor:    service,
+		LoggedAt: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		Subject:  api.AuditSubject{Type: api.AuditSubjectSecretVersion, Deleted: true},
+	}
+	createService := api.Audit{
+		Action:   api.AuditActionCreate,
+		Actor:    service,
+		LoggedAt: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		Subject:  api.AuditSubject{Type: api.AuditSubjectService, Service: &api.Service{ServiceID: "s-0123456789ab"}},
+	}
+
+	rules := []*api.AccessRule{
+		{Account: &api.Account{Name: api.AccountName(serviceID)}, DirID: rootID, Permission: api.PermissionAdmin},
+		{Account: &api.Account{Name: api.AccountName(serviceID)}, DirID: docsID, Permission: api.PermissionRead},
+		{Account: &api.Account{Name: "dev1"}, DirID: appID, Permission: api.PermissionRead},
+	}
+
+	cases := map[string]struct {
+		cmd    ACLSuggestCommand
+		events []api.Audit
+		rules  []*api.AccessRule
+		out    string
+		err    error
+	}{
+		"commands": {
+			cmd: ACLSuggestCommand{
+				format: formatCommands,
+			},
+			events: []api.Audit{writeDB, readDB, readConfig, readByUser, readDeleted},
+			rules:  rules,
+			out: "# Based on 4 events of s-Zp3hGk9sVxQa in the audit log of namespace/repo.\n" +
+				"secrethub acl set namespace/repo/app s-Zp3hGk9sVxQa read\n" +
+				"secrethub acl set namespace/repo/app/prod s-Zp3hGk9sVxQa write\n" +
+				"secrethub acl rm namespace/repo s-Zp3hGk9sVxQa\n" +
+				"secrethub acl rm namespace/repo/docs s-Zp3hGk9sVxQa\n",
+		},
+		"covered by parent": {
+			cmd: ACLSuggestCommand{
+				format: formatCommands,
+			},
+			events: []api.Audit{readDB, readConfig},
+			rules:  rules,
+			out: "# Based on 2 events of s-Zp3hGk9sVxQa in the audit log of namespace/repo.\n" +
+				"secrethub acl set namespace/repo/app s-Zp3hGk9sVxQa read\n" +
+				"secrethub acl rm namespace/repo s-Zp3hGk9sVxQa\n" +
+				"secrethub acl rm namespace/repo/docs s-Zp3hGk9sVxQa\n",
+		},
+		"members require admin": {
+			cmd: ACLSuggestCommand{
+				format: formatCommands,
+			},
+			events: []api.Audit{writeDB, readConfig, createService},
+			rules:  rules[:1],
+			out: "# Based on 3 events of s-Zp3hGk9sVxQa in the audit log of namespace/repo.\n" +
+				"# The access rules of s-Zp3hGk9sVxQa already match its access.\n",
+		},
+		"since": {
+			cmd: ACLSuggestCommand{
+				format: formatCommands,
+				since:  "2026-05-05",
+			},
+			events: []api.Audit{writeDB, readDB, readConfig, createService},
+			rules:  rules[:1],
+			out: "# Based on 2 events of s-Zp3hGk9sVxQa in the audit log of namespace/repo.\n" +
+				"secrethub acl set namespace/repo/app/prod s-Zp3hGk9sVxQa write\n" +
+				"secrethub acl rm namespace/repo s-Zp3hGk9sVxQa\n",
+		},
+		"yaml": {
+			cmd: ACLSuggestCommand{
+				format: formatYAML,
+			},
+			events: []api.Audit{writeDB, readConfig},
+			out: "# The access rules of s-Zp3hGk9sVxQa, based on 2 events in the audit log of namespace/repo.\n" +
+				"rules:\n" +
+				"- path: namespace/repo/app\n" +
+				"  account: s-Zp3hGk9sVxQa\n" +
+				"  permission: read\n" +
+				"- path: namespace/repo/app/prod\n" +
+				"  account: s-Zp3hGk9sVxQa\n" +
+				"  permission: write\n",
+		},
+		"no events": {
+			cmd: ACLSuggestCommand{
+				format: formatCommands,
+				since:  "2026-05-05",
+			},
+			events: []api.Audit{readConfig, createService},
+			rules:  rules,
+			out: "# No events of s-Zp3hGk9sVxQa were found in the audit log of namespace/repo. " +
+				"No changes are suggested, as the service may not have run in that period.\n",
+		},
+		"yaml without events": {
+			cmd: ACLSuggestCommand{
+				format: formatYAML,
+			},
+			out: "# No events of s-Zp3hGk9sVxQa were found in the audit log of namespace/repo. " +
+				"No changes are suggested, as the service may not have run in that period.\n",
+		},
+		"user": {
+			cmd: ACLSuggestCommand{
+				serviceID: "dev1",
+				format:    formatCommands,
+			},
+			err: ErrSuggestNotService("dev1"),
+		},
+		"invalid format": {
+			cmd: ACLSuggestCommand{
+				format: "json",
+			},
+			err: errNoSuchFormat("json"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			io := fakeui.NewIO(t)
+			tc.cmd.io = io
+			if tc.cmd.serviceID == "" {
+				tc.cmd.serviceID = api.AccountName(serviceID)
+			}
+			tc.cmd.newClient = func() (secrethub.ClientInterface, error) {
+				return fakeclient.Client{
+					ServiceService: &fakeclient.ServiceService{
+						GetFunc: func(id string) (*api.Service, error) {
+							assert.Equal(t, id, serviceID)
+							return &api.Service{ServiceID: serviceID, Repo: &api.Repo{Owner: "namespace", Name: "repo"}}, nil
+						},
+					},
+					DirService: &fakeclient.DirService{
+						GetTreeFunc: func(path string, depth int, ancestors bool) (*api.Tree, error) {
+							return tree, nil
+						},
+					},
+					AccessRuleService: &fakeclient.AccessRuleService{
+						ListFunc: func(path string, depth int, ancestors bool) ([]*api.AccessRule, error) {
+							return tc.rules, nil
+						},
+					},
+					RepoService: &fakeclient.RepoService{
+						AuditEventIterator: &fakeclient.AuditEventIterator{
+							Events: tc.events,
+						},
+					},
+				}, nil
+			}
+
+			// Act
+			err := tc.cmd.run(now)
+
+			// Assert
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, io.Out.String(), tc.out)
+		})
+	}
+}
@@ -0,0 +1,386 @@
+package secrethub
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secretpath"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Errors
+var (
+	ErrInvalidAnnotationsFile = errMain.Code("invalid_annotations_file").ErrorPref("cannot read the annotations in %s: %v")
+	ErrInvalidAnnotationKey   = errMain.Code("invalid_annotation_key").ErrorPref("invalid annotation key %s: keys may only contain letters, numbers, dashes (-), underscores (_) and dots (.)")
+	ErrInvalidAnnotation      = errMain.Code("invalid_annotation").ErrorPref("invalid annotation %s: use key=value or key")
+	ErrInvalidExpiryDate      = errMain.Code("invalid_expiry_date").ErrorPref("invalid expiry date %s for %s: use a date such as 2020-12-31")
+)
+
+const (
+	// annotationsFileName is the name of the secret that holds the annotations
+	// of the secrets in a directory.
+	annotationsFileName = ".annotations"
+	// annotationExpires is the annotation that holds the date on which a secret
+	// must have been rotated.
+	annotationExpires = "expires"
+	// expiryDateLayout is the format of the expires annotation.
+	expiryDateLayout = "2006-01-02"
+)
+
+var annotationKeyPattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+// AnnotateCommand sets, removes and shows the annotations of a secret.
+type AnnotateCommand struct {
+	io        ui.IO
+	path      api.SecretPath
+	set       map[string]string
+	remove    []string
+	newClient newClientFunc
+}
+
+// NewAnnotateCommand creates a new AnnotateCommand.
+func NewAnnotateCommand(io ui.IO, newClient newClientFunc) *AnnotateCommand {
+	return &AnnotateCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *AnnotateCommand) Register(r cli.Registerer) {
+	clause := r.Command("annotate", "Set, remove or show the annotations of a secret.")
+	clause.HelpLong("Annotations are key-value pairs to keep track of e.g. the owner or the origin of a secret. " +
+		"They are shown by inspect and can be used to filter the output of ls. " +
+		"The annotations of all secrets in a directory are stored encrypted in a secret named " + annotationsFileName + " in that directory. " +
+		"They are carried over when a secret is copied or moved and removed together with the secret.\n\n" +
+		"The " + annotationExpires + " annotation takes a date such as 2020-12-31 on which the secret must have been rotated. " +
+		"Use the expiring command to list the secrets that expire soon.\n\n" +
+		"Without flags, the annotations of the secret are shown.")
+	clause.Flags().StringToStringVar(&cmd.set, "set", nil, "Set an annotation with `KEY=VALUE`, e.g. --set owner=team-db. Can be repeated.")
+	clause.Flags().StringSliceVar(&cmd.remove, "rm", nil, "Remove the annotation with this key. Can be repeated.")
+
+	clause.BindAction(cmd.Run)
+	clause.BindArguments([]cli.Argument{{Value: &cmd.path, Name: "secret-path", Required: true, Placeholder: secretPathPlaceHolder, Description: "The path to the secret to annotate."}})
+}
+
+// Run updates or shows the annotations of the secret.
+func (cmd *AnnotateCommand) Run() error {
+	if cmd.path.HasVersion() {
+		return errCannotWriteToVersion
+	}
+
+	for key, value := range cmd.set {
+		err := validateAnnotation(cmd.path.Value(), key, value)
+		if err != nil {
+			return err
+		}
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	exists, err := client.Secrets().Exists(cmd.path.Value())
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrResourceNotFound(cmd.path)
+	}
+
+	dirPath := secretpath.Parent(cmd.path.Value())
+	annotations, err := readAnnotations(client, dirPath)
+	if err != nil {
+		return err
+	}
+
+	name := cmd.path.GetSecret()
+	if len(cmd.set) == 0 && len(cmd.remove) == 0 {
+		return printAnnotations(cmd.io, cmd.path.Value(), annotations.of(name))
+	}
+
+	annotations.update(name, cmd.set, cmd.remove)
+
+	err = writeAnnotations(client, dirPath, annotations)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Updated the annotations of %s.\n", cmd.path)
+	return nil
+}
+
+// printAnnotations prints the annotations of a secret in a table, sorted by key.
+func printAnnotations(io ui.IO, path string, annotations map[string]string) error {
+	if len(annotations) == 0 {
+		fmt.Fprintf(io.Output(), "%s has no annotations.\n", path)
+		return nil
+	}
+
+	keys := make([]string, 0, len(annotations))
+	for key := range annotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	tw := tabwriter.NewWriter(io.Output(), 0, 4, 4, ' ', 0)
+	fmt.Fprintf(tw, "%s\t%s\n", "KEY", "VALUE")
+	for _, key := range keys {
+		fmt.Fprintf(tw, "%s\t%s\n", key, annotations[key])
+	}
+	return tw.Flush()
+}
+
+// validateAnnotation returns an error when the annotation cannot be set on a secret.
+func validateAnnotation(path string, key string, value string) error {
+	if !annotationKeyPattern.MatchString(key) {
+		return ErrInvalidAnnotationKey(key)
+	}
+	if key == annotationExpires {
+		_, err := parseExpiryDate(path, value)
+		return err
+	}
+	return nil
+}
+
+// parseExpiryDate parses the value of the expires annotation of the secret at the given path.
+func parseExpiryDate(path string, value string) (time.Time, error) {
+	t, err := time.ParseInLocation(expiryDateLayout, value, time.Local)
+	if err != nil {
+		return time.Time{}, ErrInvalidExpiryDate(value, path)
+	}
+	return t, nil
+}
+
+// dirAnnotations holds the annotations of the secrets in a directory,
+// by the lowercase name of the secret, as secret names are case insensitive.
+type dirAnnotations map[string]map[string]string
+
+// of returns the annotations of the secret with the given name.
+func (a dirAnnotations) of(name string) map[string]string {
+	return a[strings.ToLower(name)]
+}
+
+// update sets and removes annotations of the secret with the given name.
+func (a dirAnnotations) update(name string, set map[string]string, remove []string) {
+	name = strings.ToLower(name)
+	annotations := a[name]
+	if annotations == nil {
+		annotations = make(map[string]string, len(set))
+	}
+
+	for key, value := range set {
+		annotations[key] = value
+	}
+	for _, key := range remove {
+		delete(annotations, key)
+	}
+
+	if len(annotations) == 0 {
+		delete(a, name)
+	} else {
+		a[name] = annotations
+	}
+}
+
+// set replaces the annotations of the secret with the given name.
+func (a dirAnnotations) set(name string, annotations map[string]string) {
+	name = strings.ToLower(name)
+	if len(annotations) == 0 {
+		delete(a, name)
+		return
+	}
+
+	copied := make(map[string]string, len(annotations))
+	for key, value := range annotations {
+		copied[key] = value
+	}
+	a[name] = copied
+}
+
+// readAnnotations reads the annotations of the secrets in the directory.
+// A directory without annotations returns an empty set of annotations.
+func readAnnotations(client secrethub.ClientInterface, dirPath string) (dirAnnotations, error) {
+	path := secretpath.Join(dirPath, annotationsFileName)
+	version, err := client.Secrets().Versions().GetWithData(path)
+	if api.IsErrNotFound(err) {
+		return dirAnnotations{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	annotations := dirAnnotations{}
+	err = yaml.UnmarshalStrict(version.Data, &annotations)
+	if err != nil {
+		return nil, ErrInvalidAnnotationsFile(path, err)
+	}
+	return annotations, nil
+}
+
+// writeAnnotations writes the annotations of the secrets in the directory.
+func writeAnnotations(client secrethub.ClientInterface, dirPath string, annotations dirAnnotations) error {
+	data, err := yaml.Marshal(annotations)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Secrets().Write(secretpath.Join(dirPath, annotationsFileName), data)
+	return err
+}
+
+// annotationsCache reads the annotations of every directory once,
+// so the annotations of multiple secrets can be changed with a single write per directory.
+type annotationsCache struct {
+	client  secrethub.ClientInterface
+	dirs    map[string]dirAnnotations
+	changed map[string]bool
+}
+
+// newAnnotationsCache creates an empty annotationsCache.
+func newAnnotationsCache(client secrethub.ClientInterface) *annotationsCache {
+	return &annotationsCache{
+		client:  client,
+		dirs:    make(map[string]dirAnnotations),
+		changed: make(map[string]bool),
+	}
+}
+
+// get returns the annotations of the secrets in the directory.
+func (c *annotationsCache) get(dirPath string) (dirAnnotations, error) {
+	annotations, ok := c.dirs[dirPath]
+	if ok {
+		return annotations, nil
+	}
+
+	annotations, err := readAnnotations(c.client, dirPath)
+	if err != nil {
+		return nil, err
+	}
+	c.dirs[dirPath] = annotations
+	return annotations, nil
+}
+
+// write writes the annotations of the directories that were changed.
+func (c *annotationsCache) write() error {
+	dirPaths := make([]string, 0, len(c.changed))
+	for dirPath := range c.changed {
+		dirPaths = append(dirPaths, dirPath)
+	}
+	sort.Strings(dirPaths)
+
+	for _, dirPath := range dirPaths {
+		err := writeAnnotations(c.client, dirPath, c.dirs[dirPath])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyAnnotations gives the copied secrets the annotations of their source.
+// The annotations of a secret that is overwritten are kept when the source has no annotations.
+func copyAnnotations(client secrethub.ClientInterface, copies []secretCopy) error {
+	cache := newAnnotationsCache(client)
+	for _, secret := range copies {
+		src, err := cache.get(secretpath.Parent(secret.src))
+		if err != nil {
+			return err
+		}
+		annotations := src.of(secretpath.Base(secret.src))
+		if len(annotations) == 0 {
+			continue
+		}
+
+		dstDir := secretpath.Parent(secret.dst)
+		dst, err := cache.get(dstDir)
+		if err != nil {
+			return err
+		}
+		dst.set(secretpath.Base(secret.dst), annotations)
+		cache.changed[dstDir] = true
+	}
+	return cache.write()
+}
+
+// removeAnnotations removes the annotations of the secrets at the given paths.
+func removeAnnotations(client secrethub.ClientInterface, paths ...string) error {
+	cache := newAnnotationsCache(client)
+	for _, path := range paths {
+		name := secretpath.Base(path)
+		if strings.EqualFold(name, annotationsFileName) {
+			continue
+		}
+
+		dirPath := secretpath.Parent(path)
+		annotations, err := cache.get(dirPath)
+		if err != nil {
+			return err
+		}
+		if annotations.of(name) == nil {
+			continue
+		}
+		annotations.set(name, nil)
+		cache.changed[dirPath] = true
+	}
+	return cache.write()
+}
+
+// annotationFilter selects secrets by their annotations.
+// A filter without a value matches every secret that has the key.
+type annotationFilter struct {
+	key      string
+	value    string
+	hasValue bool
+}
+
+// parseAnnotationFilters parses filters in the key=value or key format.
+func parseAnnotationFilters(raw []string) ([]annotationFilter, error) {
+	filters := make([]annotationFilter, len(raw))
+	for i, filter := range raw {
+		parts := strings.SplitN(filter, "=", 2)
+		if parts[0] == "" {
+			return nil, ErrInvalidAnnotation(filter)
+		}
+		filters[i] = annotationFilter{key: parts[0]}
+		if len(parts) == 2 {
+			filters[i].value = parts[1]
+			filters[i].hasValue = true
+		}
+	}
+	return filters, nil
+}
+
+// matchesAnnotations returns whether the annotations match all filters.
+func matchesAnnotations(annotations map[string]string, filters []annotationFilter) bool {
+	for _, filter := range filters {
+		value, ok := annotations[filter.key]
+		if !ok || (filter.hasValue && value != filter.value) {
+			return false
+		}
+	}
+	return true
+}
+
+// filterDirByAnnotations removes the secrets of which the annotations do not match
+// the filters from the directory. Subdirectories have no annotations, so they are removed too.
+func filterDirByAnnotations(dir *api.Dir, annotations dirAnnotations, filters []annotationFilter) {
+	secrets := make([]*api.Secret, 0, len(dir.Secrets))
+	for _, secret := range dir.Secrets {
+		if matchesAnnotations(annotations.of(secret.Name), filters) {
+			secrets = append(secrets, secret)
+		}
+	}
+	dir.Secrets = secrets
+	dir.SubDirs = nil
+}
@@ -0,0 +1,202 @@
+package secrethub
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestAnnotateCommand_Run(t *testing.T) {
+	existing := "db_password:\n  owner: team-db\n  origin: aws\napi_key:\n  owner: team-web\n"
+
+	cases := map[string]struct {
+		cmd             AnnotateCommand
+		annotations     string
+		exists          bool
+		expectedWritten string
+		expectedOut     string
+		expectedErr     error
+	}{
+		"show": {
+			cmd: AnnotateCommand{
+				path: "namespace/repo/DB_Password",
+			},
+			annotations: existing,
+			exists:      true,
+			expectedOut: "KEY       VALUE\n" +
+				"origin    aws\n" +
+				"owner     team-db\n",
+		},
+		"show none": {
+			cmd: AnnotateCommand{
+				path: "namespace/repo/db_password",
+			},
+			exists:      true,
+			expectedOut: "namespace/repo/db_password has no annotations.\n",
+		},
+		"set and remove": {
+			cmd: AnnotateCommand{
+				path:   "namespace/repo/db_password",
+				set:    map[string]string{"owner": "team-ops"},
+				remove: []string{"origin"},
+			},
+			annotations: existing,
+			exists:      true,
+			expectedWritten: "api_key:\n  owner: team-web\n" +
+				"db_password:\n  owner: team-ops\n",
+			expectedOut: "Updated the annotations of namespace/repo/db_password.\n",
+		},
+		"remove last": {
+			cmd: AnnotateCommand{
+				path:   "namespace/repo/api_key",
+				remove: []string{"owner"},
+			},
+			annotations:     existing,
+			exists:          true,
+			expectedWritten: "db_password:\n  origin: aws\n  owner: team-db\n",
+			expectedOut:     "Updated the annotations of namespace/repo/api_key.\n",
+		},
+		"invalid key": {
+			cmd: AnnotateCommand{
+				path: "namespace/repo/db_password",
+				set:  map[string]string{"owner name": "team-db"},
+			},
+			expectedErr: ErrInvalidAnnotationKey("owner name"),
+		},
+		"invalid expiry date": {
+			cmd: AnnotateCommand{
+				path: "namespace/repo/db_password",
+				set:  map[string]string{"expires": "next week"},
+			},
+			expectedErr: ErrInvalidExpiryDate("next week", "namespace/repo/db_password"),
+		},
+		"secret not found": {
+			cmd: AnnotateCommand{
+				path: "namespace/repo/unknown",
+				set:  map[string]string{"owner": "team-db"},
+			},
+			expectedErr: ErrResourceNotFound(api.SecretPath("namespace/repo/unknown")),
+		},
+		"version": {
+			cmd: AnnotateCommand{
+				path: "namespace/repo/db_password:1",
+			},
+			expectedErr: errCannotWriteToVersion,
+		},
+		"invalid annotations file": {
+			cmd: AnnotateCommand{
+				path: "namespace/repo/db_password",
+			},
+			annotations: "db_password: team-db\n",
+			exists:      true,
+			expectedErr: ErrInvalidAnnotationsFile("namespace/repo/.annotations", errors.New("yaml: unmarshal errors:\n  line 1: cannot unmarshal !!str `team-db` into map[string]string")),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var written string
+
+			io := fakeui.NewIO(t)
+			tc.cmd.io = io
+			tc.cmd.newClient = func() (secrethub.ClientInterface, error) {
+				return fakeclient.Client{
+					SecretService: &fakeclient.SecretService{
+						ExistsFunc: func(path string) (bool, error) {
+							return tc.exists, nil
+						},
+						WriteFunc: func(path string, data []byte) (*api.SecretVersion, error) {
+							assert.Equal(t, path, "namespace/repo/.annotations")
+							written = string(data)
+							return &api.SecretVersion{}, nil
+						},
+						VersionService: &fakeclient.SecretVersionService{
+							GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+								assert.Equal(t, path, "namespace/repo/.annotations")
+								if tc.annotations == "" {
+									return nil, api.ErrSecretNotFound
+								}
+								return &api.SecretVersion{Data: []byte(tc.annotations)}, nil
+							},
+						},
+					},
+				}, nil
+			}
+
+			err := tc.cmd.Run()
+
+			assert.Equal(t, err, tc.expectedErr)
+			assert.Equal(t, written, tc.expectedWritten)
+			assert.Equal(t, io.Out.String(), tc.expectedOut)
+		})
+	}
+}
+
+func TestFilterDirByAnnotations(t *testing.T) {
+	annotations := dirAnnotations{
+		"db_password": {"owner": "team-db", "origin": "aws"},
+		"api_key":     {"owner": "team-web"},
+	}
+
+	cases := map[string]struct {
+		filters  []string
+		expected []string
+		err      error
+	}{
+		"key and value": {
+			filters:  []string{"owner=team-db"},
+			expected: []string{"DB_PASSWORD"},
+		},
+		"key": {
+			filters:  []string{"owner"},
+			expected: []string{"DB_PASSWORD", "api_key"},
+		},
+		"multiple": {
+			filters:  []string{"owner", "origin=gcp"},
+			expected: []string{},
+		},
+		"empty value": {
+			filters:  []string{"origin="},
+			expected: []string{},
+		},
+		"no key": {
+			filters: []string{"=team-db"},
+			err:     ErrInvalidAnnotation("=team-db"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir := &api.Dir{
+				SubDirs: []*api.Dir{{Name: "sub"}},
+				Secrets: []*api.Secret{
+					{Name: "DB_PASSWORD"},
+					{Name: "api_key"},
+					{Name: "token"},
+					{Name: annotationsFileName},
+				},
+			}
+
+			filters, err := parseAnnotationFilters(tc.filters)
+			assert.Equal(t, err, tc.err)
+			if err != nil {
+				return
+			}
+
+			filterDirByAnnotations(dir, annotations, filters)
+
+			names := make([]string, len(dir.Secrets))
+			for i, secret := range dir.Secrets {
+				names[i] = secret.Name
+			}
+			assert.Equal(t, names, tc.expected)
+			assert.Equal(t, len(dir.SubDirs), 0)
+		})
+	}
+}
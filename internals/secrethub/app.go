@@ -49,7 +49,6 @@ var (
 	ErrSecretVersionNotFound    = errMain.Code("version_not_found").ErrorPref("version %s of secret %s does not exist")
 	ErrResourceNotFound         = errMain.Code("resource_not_found").ErrorPref("the resource at path %s does not exist")
 	ErrCannotAuditSecretVersion = errMain.Code("cannot_audit_version").Error("auditing a specific version of a secret is not yet supported")
-	ErrCannotAuditDir           = errMain.Code("cannot_audit_dir").Error("auditing a specific directory is not yet supported")
 	ErrInvalidAuditActor        = errMain.Code("invalid_audit_actor").Error("received an invalid audit actor")
 	ErrInvalidAuditSubject      = errMain.Code("invalid_audit_subject").Error("received an invalid audit subject")
 	ErrNoValidRepoOrDirPath     = errMain.Code("no_repo_or_dir").Error("no valid path to a repository or a directory was given")
@@ -149,6 +148,7 @@ func (app *App) registerCommands() {
 	NewCredentialCommand(app.io, app.clientFactory, app.credentialStore).Register(app.cli)
 	NewConfigCommand(app.io, app.credentialStore).Register(app.cli)
 	NewEnvCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewPolicyCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
 
 	// Commands
 	NewMigrateCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
@@ -160,9 +160,19 @@ func (app *App) registerCommands() {
 	NewLsCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
 	NewMkDirCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
 	NewRmCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewDiffCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewRollbackCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewRotateCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewCpCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewMvCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
 	NewTreeCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewSearchCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewAnnotateCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewExpiringCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewSyncCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
 	NewInspectCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
 	NewAuditCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewOffboardCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
 	NewInjectCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
 	NewRunCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
 	NewPrintEnvCommand(app.cli, app.io).Register(app.cli)
@@ -3,6 +3,9 @@ package secrethub
 import (
 	"fmt"
 	"io"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/secrethub/secrethub-cli/internals/cli"
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
@@ -10,9 +13,11 @@ import (
 	"github.com/secrethub/secrethub-cli/internals/secrethub/pager"
 
 	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/api/uuid"
 	"github.com/secrethub/secrethub-go/internals/errio"
 	"github.com/secrethub/secrethub-go/pkg/secrethub"
 	"github.com/secrethub/secrethub-go/pkg/secrethub/iterator"
+	"github.com/secrethub/secrethub-go/pkg/secretpath"
 
 	"github.com/spf13/cobra"
 	"golang.org/x/crypto/ssh/terminal"
@@ -21,6 +26,10 @@ import (
 var (
 	errAudit        = errio.Namespace("audit")
 	errNoSuchFormat = errAudit.Code("invalid_format").ErrorPref("invalid format: %s")
+
+	ErrAuditDirConflict  = errAudit.Code("dir_conflict").Error("a directory can be given either as the path or with --dir, not both")
+	ErrAuditDirNotInRepo = errAudit.Code("dir_not_in_repo").ErrorPref("the directory %s is not in the audited repository %s")
+	ErrAuditDirOnSecret  = errAudit.Code("dir_on_secret").Error("--dir can only be used when auditing a repository or a directory")
 )
 
 const (
@@ -41,7 +50,19 @@ type AuditCommand struct {
 	terminalWidth      func(int) (int, error)
 	perPage            int
 	maxResults         int
+	maxResultsFlag     *cli.Flag
 	format             string
+	actor              string
+	action             string
+	since              string
+	until              string
+	dir                string
+	sinceCursor        string
+	stateFile          string
+	org                string
+	follow             bool
+	pollInterval       time.Duration
+	wait               func(time.Duration) bool
 }
 
 // NewAuditCommand creates a new audit command.
@@ -54,6 +75,10 @@ func NewAuditCommand(io ui.IO, newClient newClientFunc) *AuditCommand {
 			w, _, err := terminal.GetSize(fd)
 			return w, err
 		},
+		wait: func(d time.Duration) bool {
+			time.Sleep(d)
+			return true
+		},
 	}
 }
 
@@ -65,19 +90,44 @@ func (cmd *AuditCommand) Register(r cli.Registerer) {
 	}
 
 	clause := r.Command("audit", "Show the audit log.")
+	clause.HelpLong("Show the audit log of a repository, a directory or a secret, most recent events first.\n\n" +
+		"The log can be filtered on the account that performed an event, the action, e.g. read.secret_version or only read, " +
+		"a time window and the directory the secret an event is about is in. " +
+		"The times take a date such as 2020-12-31, an RFC3339 timestamp or a duration such as 7d, which is counted back from now. " +
+		"The filters are applied while the log is read, so the log does not have to be exported first.\n\n" +
+		"To export new events only, e.g. from a cron job, pass the ID of the most recent event that was exported before with --since-cursor, " +
+		"or let the command keep track of it with --state-file.\n\n" +
+		"Organization admins can audit all repositories of an organization at once with --org. " +
+		"The events of the repositories are merged into one log.\n\n" +
+		"With --follow, the most recent events are shown oldest first and the log is then checked for new events until the command is interrupted, like tail -f. " +
+		"To follow the events on the secrets in a directory, follow its repository with --dir.")
 	clause.Flags().IntVar(&cmd.perPage, "per-page", 20, "Number of audit events shown per page")
 	clause.Cmd.Flag("per-page").Hidden = true
-	clause.Flags().StringVar(&cmd.format, "output-format", "table", "Specify the format in which to output the log. Options are: table and json. If the output of the command is parsed by a script an alternative of the table format must be used.")
+	clause.Flags().StringVar(&cmd.format, "output-format", "table", "Specify the format in which to output the log. Options are: table, json, jsonl, csv and cef. If the output of the command is parsed by a script an alternative of the table format must be used. The jsonl, csv and cef formats include the ID of every event and are meant to export the log to other systems, such as a SIEM.")
 	_ = clause.Cmd.RegisterFlagCompletionFunc("output-format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		return []string{"table", "json"}, cobra.ShellCompDirectiveDefault
+		return []string{"table", "json", formatJSONL, formatCSV, formatCEF}, cobra.ShellCompDirectiveDefault
 	})
-	clause.Flags().IntVar(&cmd.maxResults, "max-results", defaultLimit, "Specify the number of entries to list. If maxResults < 0 all entries are displayed. If the output of the command is piped, maxResults defaults to 1000.")
+	cmd.maxResultsFlag = clause.Flags().IntVar(&cmd.maxResults, "max-results", defaultLimit, "Specify the number of entries to list. If maxResults < 0 all entries are displayed. If the output of the command is piped, maxResults defaults to 1000.")
+	clause.Flags().StringVar(&cmd.actor, "actor", "", "Only show the events performed by the user or service with this username or service ID.")
+	clause.Flags().StringVar(&cmd.action, "action", "", "Only show the events with this action, e.g. read.secret_version, or read for reads of any kind.")
+	clause.Flags().StringVar(&cmd.since, "since", "", "Only show the events that were logged at or after this time.")
+	clause.Flags().StringVar(&cmd.until, "until", "", "Only show the events that were logged before this time. Cannot be used with --since-cursor or --state-file.")
+	clause.Flags().StringVar(&cmd.dir, "dir", "", "Only show the events on secrets in this directory. The path argument can be left out, in which case the repository of the directory is audited.")
+	clause.Flags().StringVar(&cmd.sinceCursor, "since-cursor", "", "Only show the events that were logged after the event with this ID. All events after it are shown, regardless of --max-results.")
+	clause.Flags().StringVar(&cmd.stateFile, "state-file", "", "Read the cursor from this file and, after all new events are shown, record the ID of the most recent event in it. "+
+		"Use this to export only the new events on every run. All events after the cursor are shown, regardless of --max-results.")
+	clause.Flags().StringVar(&cmd.org, "org", "", "Audit all repositories of this organization. The path argument must be left out.")
+	clause.Flags().BoolVarP(&cmd.follow, "follow", "f", false, "Keep checking the log and show new events as they are logged. "+
+		"Before that, the most recent events are shown oldest first. Unless --max-results is set, 10 events are shown.")
+	clause.Flags().DurationVar(&cmd.pollInterval, "poll-interval", 10*time.Second, "How often the log is checked for new events when following it.")
 	registerTimestampFlag(clause, &cmd.useTimestamps)
 
 	clause.BindAction(cmd.Run)
 	clause.BindArguments([]cli.Argument{
-		{Value: &cmd.path, Name: "path", Required: true, Description: "Path to the repository or the secret to audit " + repoPathPlaceHolder + " or " + secretPathPlaceHolder, Placeholder: optionalSecretPathPlaceHolder},
+		{Value: &cmd.path, Name: "path", Required: false, Description: "Path to the repository, the directory or the secret to audit " + repoPathPlaceHolder + " or " + secretPathPlaceHolder, Placeholder: optionalSecretPathPlaceHolder},
 	})
+
+	NewAuditReportCommand(cmd.io, cmd.newClient).Register(clause)
 }
 
 // Run prints all audit events for the given repository or secret.
@@ -88,6 +138,12 @@ func (cmd *AuditCommand) Run() error {
 
 // beforeRun configures the command using the flag values.
 func (cmd *AuditCommand) beforeRun() {
+	// When following the log, only the most recent events are shown before the new events,
+	// regardless of whether the output is piped.
+	if cmd.follow && !cmd.maxResultsFlag.Changed() {
+		cmd.maxResults = followLineLimit
+	}
+
 	if cmd.format == formatJSON {
 		cmd.timeFormatter = NewTimeFormatter(true)
 	} else {
@@ -101,18 +157,64 @@ func (cmd *AuditCommand) run() error {
 		return fmt.Errorf("per-page should be positive, got %d", cmd.perPage)
 	}
 
-	iter, auditTable, err := cmd.iterAndAuditTable()
+	filter, err := cmd.filter(time.Now())
 	if err != nil {
 		return err
 	}
 
-	paginatedWriter, err := cmd.newPaginatedWriter(cmd.io.Output())
-	if err != nil {
-		return err
+	cursor := cmd.sinceCursor
+	if cursor != "" && uuid.Validate(cursor) != nil {
+		return ErrInvalidAuditCursor(cursor)
+	}
+	if cmd.stateFile != "" {
+		if cursor != "" {
+			return ErrFlagsConflict("--since-cursor and --state-file")
+		}
+		cursor, err = readAuditCursor(cmd.stateFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Events after --until are not shown, so moving the cursor past them would skip them on the next run.
+	if cmd.until != "" && (cursor != "" || cmd.stateFile != "") {
+		return ErrFlagsConflict("--until and --since-cursor or --state-file")
+	}
+
+	if cmd.follow && cursor != "" {
+		return ErrFlagsConflict("--follow and --since-cursor or --state-file")
+	}
+
+	var iter secrethub.AuditEventIterator
+	var auditTable auditTable
+	var orgLog *orgAuditLog
+	if cmd.org != "" || cmd.follow {
+		orgLog, err = cmd.orgAuditLog(filter)
+		if err != nil {
+			return err
+		}
+		iter, auditTable = orgLog, orgLog
+	} else {
+		iter, auditTable, err = cmd.iterAndAuditTable(filter)
+		if err != nil {
+			return err
+		}
+	}
+
+	var paginatedWriter io.WriteCloser
+	if cmd.follow {
+		// A pager would wait for the log to end before it can be quit.
+		paginatedWriter = nopWriteCloser{cmd.io.Output()}
+	} else {
+		paginatedWriter, err = cmd.newPaginatedWriter(cmd.io.Output())
+		if err != nil {
+			return err
+		}
 	}
 	defer paginatedWriter.Close()
 
 	var formatter listFormatter
+	recordFormatter, isRecordFormat := newAuditRecordFormatter(paginatedWriter, cmd.format)
 	if cmd.format == formatJSON {
 		formatter = newJSONFormatter(paginatedWriter, auditTable.header())
 	} else if cmd.format == formatTable && cmd.io.IsOutputPiped() {
@@ -123,11 +225,28 @@ func (cmd *AuditCommand) run() error {
 			terminalWidth = defaultTerminalWidth
 		}
 		formatter = newTableFormatter(paginatedWriter, terminalWidth, auditTable.columns())
-	} else {
+	} else if !isRecordFormat {
 		return errNoSuchFormat(cmd.format)
 	}
 
-	for lineCount := 0; lineCount != cmd.maxResults; lineCount++ {
+	out := auditWriter{
+		table:           auditTable,
+		formatter:       formatter,
+		recordFormatter: recordFormatter,
+	}
+
+	// With a cursor, all events after it are shown, so no events are skipped on the next run.
+	maxResults := cmd.maxResults
+	if cursor != "" || cmd.stateFile != "" {
+		maxResults = -1
+	}
+
+	// When following the log, the events are shown oldest first, so they are collected first.
+	var history []api.Audit
+
+	// The events are returned most recent first, so the first event is the next cursor.
+	nextCursor := ""
+	for lineCount := 0; lineCount != maxResults; {
 		event, err := iter.Next()
 		if err == iterator.Done {
 			break
@@ -135,42 +254,201 @@ func (cmd *AuditCommand) run() error {
 			return err
 		}
 
-		row, err := auditTable.row(event)
+		eventID := event.EventID.String()
+		if cursor != "" && eventID == cursor {
+			break
+		}
+		if nextCursor == "" {
+			nextCursor = eventID
+		}
+		if filter.ended(event) {
+			break
+		}
+
+		match, err := filter.matches(event)
 		if err != nil {
 			return err
 		}
+		if !match {
+			continue
+		}
+		lineCount++
+
+		if cmd.follow {
+			history = append(history, event)
+			continue
+		}
 
-		err = formatter.Write(row)
+		err = out.write(event)
 		if err == pager.ErrPagerClosed {
-			break
+			// Not all new events were shown, so the cursor is not moved.
+			return nil
 		} else if err != nil {
 			return err
 		}
 	}
+
+	if cmd.follow {
+		return cmd.followLog(orgLog, filter, out, history)
+	}
+
+	if cmd.stateFile != "" && nextCursor != "" {
+		return writeAuditCursor(cmd.stateFile, nextCursor)
+	}
 	return nil
 }
 
-func (cmd *AuditCommand) iterAndAuditTable() (secrethub.AuditEventIterator, auditTable, error) {
-	repoPath, err := cmd.path.ToRepoPath()
-	if err == nil {
-		client, err := cmd.newClient()
+// followLog shows the given history oldest first and then shows the new events of the log until waiting is stopped.
+func (cmd *AuditCommand) followLog(log *orgAuditLog, filter *auditFilter, out auditWriter, history []api.Audit) error {
+	for i := len(history) - 1; i >= 0; i-- {
+		err := out.write(history[i])
 		if err != nil {
-			return nil, nil, err
+			return err
 		}
-		tree, err := client.Dirs().GetTree(repoPath.GetDirPath().Value(), -1, false)
+	}
+
+	for cmd.wait(cmd.pollInterval) {
+		events, err := log.poll()
+		if err != nil {
+			return err
+		}
+		if filter.dir != "" {
+			// The tree is refetched when there are new events, as they can be about new secrets in the directory.
+			filter.tree = log.repos[0].tree
+		}
+
+		for _, event := range events {
+			match, err := filter.matches(event)
+			if err != nil {
+				return err
+			}
+			if !match {
+				continue
+			}
+
+			err = out.write(event)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// orgAuditLog returns the merged log of all repositories of the organization,
+// or the log of the audited repository when following it.
+// When following the events in a directory, the filter is set to the directory.
+func (cmd *AuditCommand) orgAuditLog(filter *auditFilter) (*orgAuditLog, error) {
+	if cmd.org != "" && (cmd.path != "" || cmd.dir != "") {
+		return nil, ErrAuditOrgConflict
+	}
+
+	var repoPaths []api.RepoPath
+	if cmd.org == "" {
+		path := cmd.path
+		if cmd.dir != "" {
+			dirPath, err := api.NewDirPath(cmd.dir)
+			if err != nil {
+				return nil, err
+			}
+			filter.dir = dirPath.Value()
+
+			if path == "" {
+				path = api.Path(dirPath.GetRepoPath().Value())
+			}
+		}
+
+		repoPath, err := path.ToRepoPath()
+		if err != nil {
+			return nil, ErrAuditFollowNotRepo
+		}
+		if filter.dir != "" && !strings.EqualFold(api.DirPath(filter.dir).GetRepoPath().Value(), repoPath.Value()) {
+			return nil, ErrAuditDirNotInRepo(filter.dir, repoPath)
+		}
+		repoPaths = []api.RepoPath{repoPath}
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return nil, err
+	}
+
+	if cmd.org != "" {
+		err = api.ValidateNamespace(cmd.org)
+		if err != nil {
+			return nil, err
+		}
+
+		repos, err := client.Repos().List(cmd.org)
+		if err != nil {
+			return nil, err
+		}
+		sort.Sort(api.SortRepoByName(repos))
+
+		for _, repo := range repos {
+			repoPaths = append(repoPaths, repo.Path())
+		}
+	}
+
+	log, err := newOrgAuditLog(client, repoPaths, cmd.timeFormatter)
+	if err != nil {
+		return nil, err
+	}
+	if filter.dir != "" {
+		filter.tree = log.repos[0].tree
+	}
+	return log, nil
+}
+
+// filter creates the filter from the flags.
+func (cmd *AuditCommand) filter(now time.Time) (*auditFilter, error) {
+	filter := &auditFilter{
+		actor:  cmd.actor,
+		action: cmd.action,
+	}
+
+	var err error
+	if cmd.since != "" {
+		filter.since, err = parseSearchTime(cmd.since, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if cmd.until != "" {
+		filter.until, err = parseSearchTime(cmd.until, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return filter, nil
+}
+
+func (cmd *AuditCommand) iterAndAuditTable(filter *auditFilter) (secrethub.AuditEventIterator, auditTable, error) {
+	path := cmd.path
+	if cmd.dir != "" {
+		dirPath, err := api.NewDirPath(cmd.dir)
 		if err != nil {
 			return nil, nil, err
 		}
+		filter.dir = dirPath.Value()
 
-		iter := client.Repos().EventIterator(repoPath.Value(), &secrethub.AuditEventIteratorParams{})
-		auditTable := newRepoAuditTable(tree, cmd.timeFormatter)
-		return iter, auditTable, nil
+		if path == "" {
+			path = api.Path(dirPath.GetRepoPath().Value())
+		}
+	}
 
+	repoPath, err := path.ToRepoPath()
+	if err == nil {
+		if filter.dir != "" && !strings.EqualFold(api.DirPath(filter.dir).GetRepoPath().Value(), repoPath.Value()) {
+			return nil, nil, ErrAuditDirNotInRepo(filter.dir, repoPath)
+		}
+		return cmd.repoIterAndAuditTable(repoPath, filter)
 	}
 
-	secretPath, err := cmd.path.ToSecretPath()
+	secretPath, err := path.ToSecretPath()
 	if err == nil {
-		if cmd.path.HasVersion() {
+		if path.HasVersion() {
 			return nil, nil, ErrCannotAuditSecretVersion
 		}
 
@@ -181,17 +459,76 @@ func (cmd *AuditCommand) iterAndAuditTable() (secrethub.AuditEventIterator, audi
 
 		isDir, err := client.Dirs().Exists(secretPath.Value())
 		if err == nil && isDir {
-			return nil, nil, ErrCannotAuditDir
+			// A directory is audited by filtering the events of its repository.
+			if filter.dir != "" {
+				return nil, nil, ErrAuditDirConflict
+			}
+			filter.dir = secretPath.Value()
+			return cmd.repoIterAndAuditTable(secretPath.GetRepoPath(), filter)
+		}
+		if filter.dir != "" {
+			return nil, nil, ErrAuditDirOnSecret
 		}
 
 		iter := client.Secrets().EventIterator(secretPath.Value(), &secrethub.AuditEventIteratorParams{})
-		auditTable := newSecretAuditTable(cmd.timeFormatter)
+		auditTable := newSecretAuditTable(secretPath, cmd.timeFormatter)
 		return iter, auditTable, nil
 	}
 
 	return nil, nil, ErrNoValidRepoOrSecretPath
 }
 
+// repoIterAndAuditTable returns the iterator and table to audit a repository.
+func (cmd *AuditCommand) repoIterAndAuditTable(repoPath api.RepoPath, filter *auditFilter) (secrethub.AuditEventIterator, auditTable, error) {
+	client, err := cmd.newClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	tree, err := client.Dirs().GetTree(repoPath.GetDirPath().Value(), -1, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	filter.tree = tree
+
+	iter := client.Repos().EventIterator(repoPath.Value(), &secrethub.AuditEventIteratorParams{})
+	auditTable := newRepoAuditTable(tree, cmd.timeFormatter)
+	return iter, auditTable, nil
+}
+
+// auditWriter writes audit events in the output format of the command.
+type auditWriter struct {
+	table           auditTable
+	formatter       listFormatter
+	recordFormatter auditRecordFormatter
+}
+
+// write writes the event as a record when exporting the log and as a row otherwise.
+func (w auditWriter) write(event api.Audit) error {
+	if w.recordFormatter != nil {
+		record, err := w.table.record(event)
+		if err != nil {
+			return err
+		}
+		return w.recordFormatter.WriteRecord(record)
+	}
+
+	row, err := w.table.row(event)
+	if err != nil {
+		return err
+	}
+	return w.formatter.Write(row)
+}
+
+// nopWriteCloser is a writer with a Close method that does nothing.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+// Close does nothing.
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
 type tableColumn struct {
 	name     string
 	maxWidth int
@@ -200,6 +537,7 @@ type tableColumn struct {
 type auditTable interface {
 	header() []string
 	row(event api.Audit) ([]string, error)
+	record(event api.Audit) (auditRecord, error)
 	columns() []tableColumn
 }
 
@@ -246,14 +584,16 @@ func (table baseAuditTable) columns() []tableColumn {
 	return table.tableColumns
 }
 
-func newSecretAuditTable(timeFormatter TimeFormatter) secretAuditTable {
+func newSecretAuditTable(path api.SecretPath, timeFormatter TimeFormatter) secretAuditTable {
 	return secretAuditTable{
 		baseAuditTable: newBaseAuditTable(timeFormatter),
+		path:           path,
 	}
 }
 
 type secretAuditTable struct {
 	baseAuditTable
+	path api.SecretPath
 }
 
 func (table secretAuditTable) header() []string {
@@ -264,6 +604,14 @@ func (table secretAuditTable) row(event api.Audit) ([]string, error) {
 	return table.baseAuditTable.row(event)
 }
 
+func (table secretAuditTable) record(event api.Audit) (auditRecord, error) {
+	subject := table.path.Value()
+	if event.Subject.Type == api.AuditSubjectSecretVersion && event.Subject.SecretVersion != nil {
+		subject = fmt.Sprintf("%s:%d", subject, event.Subject.SecretVersion.Version)
+	}
+	return newAuditRecord(event, subject, table.path.GetRepoPath().Value())
+}
+
 func newRepoAuditTable(tree *api.Tree, timeFormatter TimeFormatter) repoAuditTable {
 	return repoAuditTable{
 		baseAuditTable: newBaseAuditTable(timeFormatter, tableColumn{name: "event subject"}),
@@ -284,3 +632,12 @@ func (table repoAuditTable) row(event api.Audit) ([]string, error) {
 
 	return table.baseAuditTable.row(event, subject)
 }
+
+func (table repoAuditTable) record(event api.Audit) (auditRecord, error) {
+	subject, err := getAuditSubject(event, table.tree)
+	if err != nil {
+		return auditRecord{}, err
+	}
+
+	return newAuditRecord(event, subject, secretpath.Join(table.tree.ParentPath.String(), table.tree.RootDir.Name))
+}
@@ -0,0 +1,208 @@
+package secrethub
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/api/uuid"
+)
+
+// Errors
+var (
+	ErrInvalidAuditCursor    = errAudit.Code("invalid_cursor").ErrorPref("invalid cursor %s: a cursor is the ID of an audit event")
+	ErrInvalidAuditStateFile = errAudit.Code("invalid_state_file").ErrorPref("cannot read the cursor in %s: it must contain the ID of an audit event")
+)
+
+const (
+	formatJSONL = "jsonl"
+	formatCSV   = "csv"
+	formatCEF   = "cef"
+)
+
+// auditRecord is an audit event in the form in which it is exported to other systems, e.g. a SIEM.
+type auditRecord struct {
+	EventID     string `json:"event_id"`
+	LoggedAt    string `json:"logged_at"`
+	Actor       string `json:"actor"`
+	ActorType   string `json:"actor_type"`
+	Action      string `json:"action"`
+	Subject     string `json:"subject"`
+	SubjectType string `json:"subject_type"`
+	Repo        string `json:"repo"`
+	IPAddress   string `json:"ip_address"`
+}
+
+// newAuditRecord creates the record of an event.
+func newAuditRecord(event api.Audit, subject string, repo string) (auditRecord, error) {
+	actor, err := getAuditActor(event)
+	if err != nil {
+		return auditRecord{}, err
+	}
+
+	return auditRecord{
+		EventID:     event.EventID.String(),
+		LoggedAt:    event.LoggedAt.UTC().Format(time.RFC3339),
+		Actor:       actor,
+		ActorType:   event.Actor.Type,
+		Action:      getEventAction(event),
+		Subject:     subject,
+		SubjectType: string(event.Subject.Type),
+		Repo:        repo,
+		IPAddress:   event.IPAddress,
+	}, nil
+}
+
+// auditRecordFormatter writes audit records in a format that can be ingested by other systems.
+type auditRecordFormatter interface {
+	WriteRecord(record auditRecord) error
+}
+
+// newAuditRecordFormatter returns the formatter for the format,
+// or false when the format is not an export format.
+func newAuditRecordFormatter(writer io.Writer, format string) (auditRecordFormatter, bool) {
+	switch format {
+	case formatJSONL:
+		return jsonlAuditFormatter{encoder: json.NewEncoder(writer)}, true
+	case formatCSV:
+		return &csvAuditFormatter{writer: csv.NewWriter(writer)}, true
+	case formatCEF:
+		return cefAuditFormatter{writer: writer}, true
+	}
+	return nil, false
+}
+
+// jsonlAuditFormatter writes every record as a JSON object on a line of its own.
+type jsonlAuditFormatter struct {
+	encoder *json.Encoder
+}
+
+// WriteRecord writes the record as a line of JSON.
+func (f jsonlAuditFormatter) WriteRecord(record auditRecord) error {
+	return f.encoder.Encode(record)
+}
+
+// csvAuditFormatter writes the records as comma separated values, preceded by a header.
+type csvAuditFormatter struct {
+	writer        *csv.Writer
+	headerPrinted bool
+}
+
+// WriteRecord writes the record as a line of comma separated values.
+// The header is written before the first record.
+func (f *csvAuditFormatter) WriteRecord(record auditRecord) error {
+	if !f.headerPrinted {
+		err := f.writer.Write([]string{"event_id", "logged_at", "actor", "actor_type", "action", "subject", "subject_type", "repo", "ip_address"})
+		if err != nil {
+			return err
+		}
+		f.headerPrinted = true
+	}
+
+	err := f.writer.Write([]string{
+		record.EventID,
+		record.LoggedAt,
+		record.Actor,
+		record.ActorType,
+		record.Action,
+		record.Subject,
+		record.SubjectType,
+		record.Repo,
+		record.IPAddress,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Flush every record, so the pager shows it and a closed pager is noticed.
+	f.writer.Flush()
+	return f.writer.Error()
+}
+
+// cefAuditFormatter writes the records in the ArcSight Common Event Format.
+type cefAuditFormatter struct {
+	writer io.Writer
+}
+
+// WriteRecord writes the record as a CEF line.
+func (f cefAuditFormatter) WriteRecord(record auditRecord) error {
+	loggedAt, err := time.Parse(time.RFC3339, record.LoggedAt)
+	if err != nil {
+		return err
+	}
+
+	version := Version
+	if version == "" {
+		version = "dev"
+	}
+
+	extension := []string{
+		"rt=" + cefExtensionEscaper.Replace(fmt.Sprint(loggedAt.UnixNano()/int64(time.Millisecond))),
+		"externalId=" + cefExtensionEscaper.Replace(record.EventID),
+		"suser=" + cefExtensionEscaper.Replace(record.Actor),
+		"src=" + cefExtensionEscaper.Replace(record.IPAddress),
+		"cs1Label=subject",
+		"cs1=" + cefExtensionEscaper.Replace(record.Subject),
+		"cs2Label=repo",
+		"cs2=" + cefExtensionEscaper.Replace(record.Repo),
+	}
+
+	_, err = fmt.Fprintf(f.writer, "CEF:0|SecretHub|SecretHub CLI|%s|%s|%s|%d|%s\n",
+		cefHeaderEscaper.Replace(version),
+		cefHeaderEscaper.Replace(record.Action),
+		cefHeaderEscaper.Replace(strings.Replace(record.Action, ".", " ", 1)),
+		cefSeverity(record.Action),
+		strings.Join(extension, " "),
+	)
+	return err
+}
+
+var (
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, `|`, `\|`)
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`)
+)
+
+// cefSeverity returns the CEF severity, from 0 to 10, of an action.
+// Removing or revoking is more severe than changing, which is more severe than reading.
+func cefSeverity(action string) int {
+	switch strings.SplitN(action, ".", 2)[0] {
+	case string(api.AuditActionDelete), "revoke":
+		return 7
+	case string(api.AuditActionRead):
+		return 3
+	default:
+		return 5
+	}
+}
+
+// readAuditCursor reads the cursor from the state file.
+// A state file that does not exist yet has no cursor.
+func readAuditCursor(stateFile string) (string, error) {
+	raw, err := ioutil.ReadFile(stateFile)
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", ErrReadFile(stateFile, err)
+	}
+
+	cursor := strings.TrimSpace(string(raw))
+	if uuid.Validate(cursor) != nil {
+		return "", ErrInvalidAuditStateFile(stateFile)
+	}
+	return cursor, nil
+}
+
+// writeAuditCursor writes the cursor to the state file.
+func writeAuditCursor(stateFile string, cursor string) error {
+	err := ioutil.WriteFile(stateFile, []byte(cursor+"\n"), 0600)
+	if err != nil {
+		return ErrCannotWrite(stateFile, err)
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+package secrethub
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/fakes"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/api/uuid"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestAuditCommand_run_export(t *testing.T) {
+	dir, cleanup := testdata.tempDir(t)
+	defer cleanup()
+
+	rootID := uuid.New()
+	secretID := uuid.New()
+	tree := &api.Tree{
+		ParentPath: "namespace",
+		RootDir:    &api.Dir{DirID: rootID, Name: "repo"},
+		Dirs: map[uuid.UUID]*api.Dir{
+			rootID: {DirID: rootID, Name: "repo"},
+		},
+		Secrets: map[uuid.UUID]*api.Secret{
+			secretID: {SecretID: secretID, DirID: rootID, Name: "db=password"},
+		},
+	}
+
+	readID := uuid.New()
+	deleteID := uuid.New()
+	createID := uuid.New()
+	events := []api.Audit{
+		{
+			EventID:   readID,
+			Action:    api.AuditActionRead,
+			Actor:     api.AuditActor{Type: "service", Service: &api.Service{ServiceID: "s-ci"}},
+			LoggedAt:  time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC),
+			IPAddress: "10.0.0.1",
+			Subject: api.AuditSubject{
+				Type:          api.AuditSubjectSecretVersion,
+				SecretVersion: &api.SecretVersion{Version: 2, Secret: &api.Secret{SecretID: secretID}},
+			},
+		},
+		{
+			EventID:   deleteID,
+			Action:    api.AuditActionDelete,
+			Actor:     api.AuditActor{Type: "user", User: &api.User{Username: "developer"}},
+			LoggedAt:  time.Date(2026, 1, 9, 12, 0, 0, 0, time.UTC),
+			IPAddress: "10.0.0.2",
+			Subject: api.AuditSubject{
+				Type:   api.AuditSubjectSecret,
+				Secret: &api.Secret{SecretID: secretID},
+			},
+		},
+		{
+			EventID:   createID,
+			Action:    api.AuditActionCreate,
+			Actor:     api.AuditActor{Type: "user", User: &api.User{Username: "developer"}},
+			LoggedAt:  time.Date(2026, 1, 8, 12, 0, 0, 0, time.UTC),
+			IPAddress: "10.0.0.2",
+			Subject: api.AuditSubject{
+				Type: api.AuditSubjectRepo,
+				Repo: &api.Repo{Name: "repo"},
+			},
+		},
+	}
+
+	jsonlRead := `{"event_id":"` + readID.String() + `","logged_at":"2026-01-10T12:00:00Z","actor":"s-ci","actor_type":"service","action":"read.secret_version","subject":"namespace/repo/db=password:2","subject_type":"secret_version","repo":"namespace/repo","ip_address":"10.0.0.1"}` + "\n"
+	jsonlDelete := `{"event_id":"` + deleteID.String() + `","logged_at":"2026-01-09T12:00:00Z","actor":"developer","actor_type":"user","action":"delete.secret","subject":"namespace/repo/db=password","subject_type":"secret","repo":"namespace/repo","ip_address":"10.0.0.2"}` + "\n"
+	jsonlCreate := `{"event_id":"` + createID.String() + `","logged_at":"2026-01-08T12:00:00Z","actor":"developer","actor_type":"user","action":"create.repo","subject":"repo","subject_type":"repo","repo":"namespace/repo","ip_address":"10.0.0.2"}` + "\n"
+
+	cases := map[string]struct {
+		cmd           AuditCommand
+		state         string
+		err           error
+		out           string
+		expectedState string
+	}{
+		"jsonl": {
+			cmd: AuditCommand{
+				format: formatJSONL,
+			},
+			out: jsonlRead + jsonlDelete + jsonlCreate,
+		},
+		"csv": {
+			cmd: AuditCommand{
+				format:     formatCSV,
+				maxResults: 2,
+			},
+			out: "event_id,logged_at,actor,actor_type,action,subject,subject_type,repo,ip_address\n" +
+				readID.String() + ",2026-01-10T12:00:00Z,s-ci,service,read.secret_version,namespace/repo/db=password:2,secret_version,namespace/repo,10.0.0.1\n" +
+				deleteID.String() + ",2026-01-09T12:00:00Z,developer,user,delete.secret,namespace/repo/db=password,secret,namespace/repo,10.0.0.2\n",
+		},
+		"cef": {
+			cmd: AuditCommand{
+				format:     formatCEF,
+				maxResults: 2,
+			},
+			out: "CEF:0|SecretHub|SecretHub CLI|dev|read.secret_version|read secret_version|3|rt=1768046400000 externalId=" + readID.String() + " suser=s-ci src=10.0.0.1 cs1Label=subject cs1=namespace/repo/db\\=password:2 cs2Label=repo cs2=namespace/repo\n" +
+				"CEF:0|SecretHub|SecretHub CLI|dev|delete.secret|delete secret|7|rt=1767960000000 externalId=" + deleteID.String() + " suser=developer src=10.0.0.2 cs1Label=subject cs1=namespace/repo/db\\=password cs2Label=repo cs2=namespace/repo\n",
+		},
+		"since cursor": {
+			cmd: AuditCommand{
+				format:      formatJSONL,
+				sinceCursor: deleteID.String(),
+				maxResults:  1,
+			},
+			out: jsonlRead,
+		},
+		"first run with state file": {
+			cmd: AuditCommand{
+				format:     formatJSONL,
+				maxResults: 1,
+			},
+			out:           jsonlRead + jsonlDelete + jsonlCreate,
+			expectedState: readID.String() + "\n",
+		},
+		"state file": {
+			cmd: AuditCommand{
+				format: formatJSONL,
+			},
+			state:         createID.String() + "\n",
+			out:           jsonlRead + jsonlDelete,
+			expectedState: readID.String() + "\n",
+		},
+		"state file without new events": {
+			cmd: AuditCommand{
+				format: formatJSONL,
+			},
+			state:         readID.String() + "\n",
+			out:           "",
+			expectedState: readID.String() + "\n",
+		},
+		"state file with filter": {
+			cmd: AuditCommand{
+				format: formatJSONL,
+				actor:  "developer",
+			},
+			state:         createID.String(),
+			out:           jsonlDelete,
+			expectedState: readID.String() + "\n",
+		},
+		"invalid cursor": {
+			cmd: AuditCommand{
+				format:      formatJSONL,
+				sinceCursor: "latest",
+			},
+			err: ErrInvalidAuditCursor("latest"),
+		},
+		"invalid state file": {
+			cmd: AuditCommand{
+				format: formatJSONL,
+			},
+			state:         "latest",
+			err:           ErrInvalidAuditStateFile(filepath.Join(dir, "invalid state file")),
+			expectedState: "latest",
+		},
+		"cursor and state file": {
+			cmd: AuditCommand{
+				format:      formatJSONL,
+				sinceCursor: readID.String(),
+			},
+			state:         createID.String(),
+			err:           ErrFlagsConflict("--since-cursor and --state-file"),
+			expectedState: createID.String(),
+		},
+		"until and cursor": {
+			cmd: AuditCommand{
+				format:      formatJSONL,
+				sinceCursor: readID.String(),
+				until:       "2026-01-01",
+			},
+			err: ErrFlagsConflict("--until and --since-cursor or --state-file"),
+		},
+		"until and state file": {
+			cmd: AuditCommand{
+				format: formatJSONL,
+				until:  "2026-01-01",
+			},
+			state:         createID.String(),
+			err:           ErrFlagsConflict("--until and --since-cursor or --state-file"),
+			expectedState: createID.String(),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			stateFile := filepath.Join(dir, name)
+			if tc.state != "" {
+				err := ioutil.WriteFile(stateFile, []byte(tc.state), 0600)
+				assert.OK(t, err)
+			}
+			if tc.state != "" || tc.expectedState != "" {
+				tc.cmd.stateFile = stateFile
+			}
+
+			buffer := bytes.Buffer{}
+			tc.cmd.newPaginatedWriter = func(_ io.Writer) (io.WriteCloser, error) {
+				return &fakes.Pager{Buffer: &buffer}, nil
+			}
+			tc.cmd.io = fakeui.NewIO(t)
+			tc.cmd.path = "namespace/repo"
+			tc.cmd.perPage = 20
+			if tc.cmd.maxResults == 0 {
+				tc.cmd.maxResults = -1
+			}
+			tc.cmd.newClient = func() (secrethub.ClientInterface, error) {
+				return fakeclient.Client{
+					DirService: &fakeclient.DirService{
+						GetTreeFunc: func(path string, depth int, ancestors bool) (*api.Tree, error) {
+							return tree, nil
+						},
+					},
+					RepoService: &fakeclient.RepoService{
+						AuditEventIterator: &fakeclient.AuditEventIterator{
+							Events: events,
+						},
+					},
+				}, nil
+			}
+
+			// Act
+			err := tc.cmd.run()
+
+			// Assert
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, buffer.String(), tc.out)
+			if tc.expectedState != "" {
+				state, err := ioutil.ReadFile(stateFile)
+				assert.OK(t, err)
+				assert.Equal(t, string(state), tc.expectedState)
+			}
+		})
+	}
+}
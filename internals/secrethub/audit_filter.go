@@ -0,0 +1,94 @@
+package secrethub
+
+import (
+	"strings"
+	"time"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/api/uuid"
+)
+
+// auditFilter selects the audit events to show.
+// The API cannot filter events, so the filter is applied to every event returned by the iterator,
+// until an event is returned that was logged before the time window.
+type auditFilter struct {
+	actor  string
+	action string
+	since  time.Time
+	until  time.Time
+	// dir is the path of the directory the subject of an event must be in.
+	// It is only set when auditing a repository, as it needs the tree of the repository.
+	dir  string
+	tree *api.Tree
+}
+
+// ended returns whether the event was logged before the time window of the filter.
+// As the events are returned most recent first, none of the events after it match either.
+func (f *auditFilter) ended(event api.Audit) bool {
+	return !f.since.IsZero() && event.LoggedAt.Before(f.since)
+}
+
+// matches returns whether the event passes the filter.
+func (f *auditFilter) matches(event api.Audit) (bool, error) {
+	if f.ended(event) {
+		return false, nil
+	}
+	if !f.until.IsZero() && !event.LoggedAt.Before(f.until) {
+		return false, nil
+	}
+
+	if f.actor != "" {
+		actor, err := getAuditActor(event)
+		if err != nil {
+			return false, err
+		}
+		if !strings.EqualFold(actor, f.actor) {
+			return false, nil
+		}
+	}
+
+	if f.action != "" {
+		// An action without a subject type, e.g. read, matches the action on every type of subject.
+		action := getEventAction(event)
+		if !strings.EqualFold(action, f.action) && !strings.EqualFold(string(event.Action), f.action) {
+			return false, nil
+		}
+	}
+
+	if f.dir != "" {
+		secretID, ok := auditSubjectSecretID(event)
+		if !ok {
+			return false, nil
+		}
+		secretPath, err := f.tree.AbsSecretPath(secretID)
+		if err != nil {
+			// The secret is no longer in the repository.
+			return false, nil
+		}
+		if !strings.HasPrefix(strings.ToLower(secretPath.Value()), strings.ToLower(f.dir)+"/") {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// auditSubjectSecretID returns the ID of the secret the event is about,
+// or false when the subject of the event is not a secret.
+func auditSubjectSecretID(event api.Audit) (uuid.UUID, bool) {
+	if event.Subject.Deleted {
+		return uuid.UUID{}, false
+	}
+
+	switch event.Subject.Type {
+	case api.AuditSubjectSecret, api.AuditSubjectSecretMember:
+		if event.Subject.Secret != nil {
+			return event.Subject.Secret.SecretID, true
+		}
+	case api.AuditSubjectSecretVersion:
+		if event.Subject.SecretVersion != nil && event.Subject.SecretVersion.Secret != nil {
+			return event.Subject.SecretVersion.Secret.SecretID, true
+		}
+	}
+	return uuid.UUID{}, false
+}
@@ -0,0 +1,230 @@
+package secrethub
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/fakes"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/api/uuid"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestAuditCommand_run_filter(t *testing.T) {
+	rootID := uuid.New()
+	prodID := uuid.New()
+	dbID := uuid.New()
+	passwordID := uuid.New()
+	tokenID := uuid.New()
+	tree := &api.Tree{
+		ParentPath: "namespace",
+		RootDir:    &api.Dir{DirID: rootID, Name: "repo"},
+		Dirs: map[uuid.UUID]*api.Dir{
+			rootID: {DirID: rootID, Name: "repo"},
+			prodID: {DirID: prodID, ParentID: &rootID, Name: "prod"},
+			dbID:   {DirID: dbID, ParentID: &prodID, Name: "db"},
+		},
+		Secrets: map[uuid.UUID]*api.Secret{
+			passwordID: {SecretID: passwordID, DirID: dbID, Name: "password"},
+			tokenID:    {SecretID: tokenID, DirID: rootID, Name: "token"},
+		},
+	}
+
+	developer := api.AuditActor{Type: "user", User: &api.User{Username: "developer"}}
+	service := api.AuditActor{Type: "service", Service: &api.Service{ServiceID: "s-ci"}}
+	events := []api.Audit{
+		{
+			Action:   api.AuditActionRead,
+			Actor:    developer,
+			LoggedAt: time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC),
+			Subject: api.AuditSubject{
+				Type:          api.AuditSubjectSecretVersion,
+				SecretVersion: &api.SecretVersion{Version: 1, Secret: &api.Secret{SecretID: passwordID}},
+			},
+		},
+		{
+			Action:   api.AuditActionRead,
+			Actor:    service,
+			LoggedAt: time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC),
+			Subject: api.AuditSubject{
+				Type:          api.AuditSubjectSecretVersion,
+				SecretVersion: &api.SecretVersion{Version: 2, Secret: &api.Secret{SecretID: tokenID}},
+			},
+		},
+		{
+			Action:   api.AuditActionCreate,
+			Actor:    developer,
+			LoggedAt: time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC),
+			Subject: api.AuditSubject{
+				Type:   api.AuditSubjectSecret,
+				Secret: &api.Secret{SecretID: passwordID},
+			},
+		},
+		{
+			Action:   api.AuditActionCreate,
+			Actor:    developer,
+			LoggedAt: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC),
+			Subject: api.AuditSubject{
+				Type: api.AuditSubjectRepo,
+				Repo: &api.Repo{Name: "repo"},
+			},
+		},
+	}
+
+	readPassword := `{"Author":"developer","Date":"date","Event":"read.secret_version","EventSubject":"namespace/repo/prod/db/password:1","IPAddress":""}` + "\n"
+	readToken := `{"Author":"s-ci","Date":"date","Event":"read.secret_version","EventSubject":"namespace/repo/token:2","IPAddress":""}` + "\n"
+	createPassword := `{"Author":"developer","Date":"date","Event":"create.secret","EventSubject":"namespace/repo/prod/db/password","IPAddress":""}` + "\n"
+	createRepo := `{"Author":"developer","Date":"date","Event":"create.repo","EventSubject":"repo","IPAddress":""}` + "\n"
+
+	cases := map[string]struct {
+		cmd    AuditCommand
+		events []api.Audit
+		err    error
+		out    string
+	}{
+		"no filter": {
+			cmd: AuditCommand{
+				path: "namespace/repo",
+			},
+			out: readPassword + readToken + createPassword + createRepo,
+		},
+		"actor": {
+			cmd: AuditCommand{
+				path:  "namespace/repo",
+				actor: "S-CI",
+			},
+			out: readToken,
+		},
+		"action": {
+			cmd: AuditCommand{
+				path:   "namespace/repo",
+				action: "create.secret",
+			},
+			out: createPassword,
+		},
+		"action without subject type": {
+			cmd: AuditCommand{
+				path:   "namespace/repo",
+				action: "read",
+			},
+			out: readPassword + readToken,
+		},
+		"time window": {
+			cmd: AuditCommand{
+				path:  "namespace/repo",
+				since: "2025-12-20T00:00:00Z",
+				until: "2026-01-10T00:00:00Z",
+			},
+			out: readToken + createPassword,
+		},
+		"since stops reading the log": {
+			cmd: AuditCommand{
+				path:  "namespace/repo",
+				since: "2025-12-20T00:00:00Z",
+			},
+			// The events after the first event before --since are not read, even when they would match.
+			events: []api.Audit{events[1], events[2], events[3], events[0]},
+			out:    readToken + createPassword,
+		},
+		"dir": {
+			cmd: AuditCommand{
+				dir: "namespace/repo/prod",
+			},
+			out: readPassword + createPassword,
+		},
+		"dir as path": {
+			cmd: AuditCommand{
+				path:   "namespace/repo/prod/db",
+				action: "read",
+			},
+			out: readPassword,
+		},
+		"max results counts matches": {
+			cmd: AuditCommand{
+				path:       "namespace/repo",
+				actor:      "developer",
+				maxResults: 2,
+			},
+			out: readPassword + createPassword,
+		},
+		"dir not in repo": {
+			cmd: AuditCommand{
+				path: "namespace/other",
+				dir:  "namespace/repo/prod",
+			},
+			err: ErrAuditDirNotInRepo("namespace/repo/prod", api.RepoPath("namespace/other")),
+		},
+		"dir with dir path": {
+			cmd: AuditCommand{
+				path: "namespace/repo/prod",
+				dir:  "namespace/repo/prod/db",
+			},
+			err: ErrAuditDirConflict,
+		},
+		"dir with secret path": {
+			cmd: AuditCommand{
+				path: "namespace/repo/token",
+				dir:  "namespace/repo/prod",
+			},
+			err: ErrAuditDirOnSecret,
+		},
+		"invalid time": {
+			cmd: AuditCommand{
+				path:  "namespace/repo",
+				since: "last week",
+			},
+			err: ErrInvalidSearchTime("last week"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			buffer := bytes.Buffer{}
+			tc.cmd.newPaginatedWriter = func(_ io.Writer) (io.WriteCloser, error) {
+				return &fakes.Pager{Buffer: &buffer}, nil
+			}
+			tc.cmd.io = fakeui.NewIO(t)
+			tc.cmd.format = formatJSON
+			tc.cmd.perPage = 20
+			if tc.cmd.maxResults == 0 {
+				tc.cmd.maxResults = -1
+			}
+			if tc.events == nil {
+				tc.events = events
+			}
+			tc.cmd.timeFormatter = &fakes.TimeFormatter{Response: "date"}
+			tc.cmd.newClient = func() (secrethub.ClientInterface, error) {
+				return fakeclient.Client{
+					DirService: &fakeclient.DirService{
+						ExistsFunc: func(path string) (bool, error) {
+							return path != "namespace/repo/token", nil
+						},
+						GetTreeFunc: func(path string, depth int, ancestors bool) (*api.Tree, error) {
+							assert.Equal(t, path, "namespace/repo")
+							return tree, nil
+						},
+					},
+					RepoService: &fakeclient.RepoService{
+						AuditEventIterator: &fakeclient.AuditEventIterator{
+							Events: tc.events,
+						},
+					},
+				}, nil
+			}
+
+			// Act
+			err := tc.cmd.run()
+
+			// Assert
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, buffer.String(), tc.out)
+		})
+	}
+}
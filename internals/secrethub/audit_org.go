@@ -0,0 +1,166 @@
+package secrethub
+
+import (
+	"sort"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/iterator"
+)
+
+// Errors
+var (
+	ErrAuditOrgConflict   = errAudit.Code("org_conflict").Error("--org audits all repositories of the organization, so it cannot be combined with a path or --dir")
+	ErrAuditFollowNotRepo = errAudit.Code("follow_not_repo").Error("--follow can only be used when auditing a repository or an organization: use --dir to follow the events in a directory")
+)
+
+// followLineLimit is the number of events shown before following new events,
+// when the number of events to show is not set.
+const followLineLimit = 10
+
+// orgAuditLog is the audit log of multiple repositories, merged into one log.
+// It is both the iterator of the events and the table to show them in.
+type orgAuditLog struct {
+	baseAuditTable
+	client secrethub.ClientInterface
+	repos  []*auditedRepo
+	byPath map[api.RepoPath]*auditedRepo
+}
+
+// auditedRepo is a repository of which the events are merged into the log.
+type auditedRepo struct {
+	path api.RepoPath
+	tree *api.Tree
+	iter secrethub.AuditEventIterator
+	next *api.Audit
+	done bool
+	// latest is the ID of the most recent event of the repository that was read.
+	latest string
+}
+
+// newOrgAuditLog creates the log of the given repositories.
+func newOrgAuditLog(client secrethub.ClientInterface, repoPaths []api.RepoPath, timeFormatter TimeFormatter) (*orgAuditLog, error) {
+	log := &orgAuditLog{
+		baseAuditTable: newBaseAuditTable(timeFormatter, tableColumn{name: "event subject"}),
+		client:         client,
+		byPath:         make(map[api.RepoPath]*auditedRepo, len(repoPaths)),
+	}
+
+	for _, repoPath := range repoPaths {
+		tree, err := client.Dirs().GetTree(repoPath.GetDirPath().Value(), -1, false)
+		if err != nil {
+			return nil, err
+		}
+
+		repo := &auditedRepo{
+			path: repoPath,
+			tree: tree,
+			iter: client.Repos().EventIterator(repoPath.Value(), &secrethub.AuditEventIteratorParams{}),
+		}
+		log.repos = append(log.repos, repo)
+		log.byPath[repoPath] = repo
+	}
+
+	return log, nil
+}
+
+// Next returns the most recent event of all repositories that has not been returned yet.
+func (log *orgAuditLog) Next() (api.Audit, error) {
+	var latest *auditedRepo
+	for _, repo := range log.repos {
+		if repo.next == nil && !repo.done {
+			event, err := repo.iter.Next()
+			if err == iterator.Done {
+				repo.done = true
+				continue
+			} else if err != nil {
+				return api.Audit{}, err
+			}
+
+			if repo.latest == "" {
+				repo.latest = event.EventID.String()
+			}
+			repo.setEventRepo(&event)
+			repo.next = &event
+		}
+
+		if repo.next != nil && (latest == nil || repo.next.LoggedAt.After(latest.next.LoggedAt)) {
+			latest = repo
+		}
+	}
+
+	if latest == nil {
+		return api.Audit{}, iterator.Done
+	}
+
+	event := *latest.next
+	latest.next = nil
+	return event, nil
+}
+
+// poll returns the events that were logged since the events were last read, oldest first.
+func (log *orgAuditLog) poll() ([]api.Audit, error) {
+	var events []api.Audit
+	for _, repo := range log.repos {
+		iter := log.client.Repos().EventIterator(repo.path.Value(), &secrethub.AuditEventIteratorParams{})
+
+		var repoEvents []api.Audit
+		for {
+			event, err := iter.Next()
+			if err == iterator.Done {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+
+			if event.EventID.String() == repo.latest {
+				break
+			}
+			repo.setEventRepo(&event)
+			repoEvents = append(repoEvents, event)
+		}
+
+		if len(repoEvents) == 0 {
+			continue
+		}
+		repo.latest = repoEvents[0].EventID.String()
+
+		// The new events can be about secrets that were created after the tree was fetched.
+		tree, err := log.client.Dirs().GetTree(repo.path.GetDirPath().Value(), -1, false)
+		if err != nil {
+			return nil, err
+		}
+		repo.tree = tree
+
+		events = append(events, repoEvents...)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].LoggedAt.Before(events[j].LoggedAt)
+	})
+	return events, nil
+}
+
+// setEventRepo sets the repository of the event, so the event can be traced back to it after merging.
+func (repo *auditedRepo) setEventRepo(event *api.Audit) {
+	event.Repo.Owner, event.Repo.Name = repo.path.GetNamespaceAndRepoName()
+}
+
+func (log *orgAuditLog) row(event api.Audit) ([]string, error) {
+	subject, err := getAuditSubject(event, log.byPath[event.Repo.Path()].tree)
+	if err != nil {
+		return nil, err
+	}
+
+	return log.baseAuditTable.row(event, subject)
+}
+
+func (log *orgAuditLog) record(event api.Audit) (auditRecord, error) {
+	repo := log.byPath[event.Repo.Path()]
+	subject, err := getAuditSubject(event, repo.tree)
+	if err != nil {
+		return auditRecord{}, err
+	}
+
+	return newAuditRecord(event, subject, repo.path.Value())
+}
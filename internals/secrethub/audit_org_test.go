@@ -0,0 +1,455 @@
+package secrethub
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/fakes"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/api/uuid"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+// auditOrgClient is a fake client that returns the events of the repository an iterator is requested for.
+type auditOrgClient struct {
+	fakeclient.Client
+	repos auditOrgRepoService
+}
+
+func (c auditOrgClient) Repos() secrethub.RepoService {
+	return c.repos
+}
+
+type auditOrgRepoService struct {
+	*fakeclient.RepoService
+	events func(path string) []api.Audit
+}
+
+func (s auditOrgRepoService) EventIterator(path string, _ *secrethub.AuditEventIteratorParams) secrethub.AuditEventIterator {
+	return &fakeclient.AuditEventIterator{Events: s.events(path)}
+}
+
+func TestAuditCommand_run_org(t *testing.T) {
+	apiRootID := uuid.New()
+	keyID := uuid.New()
+	webRootID := uuid.New()
+	tokenID := uuid.New()
+	trees := map[string]*api.Tree{
+		"company/api": {
+			ParentPath: "company",
+			RootDir:    &api.Dir{DirID: apiRootID, Name: "api"},
+			Dirs:       map[uuid.UUID]*api.Dir{apiRootID: {DirID: apiRootID, Name: "api"}},
+			Secrets:    map[uuid.UUID]*api.Secret{keyID: {SecretID: keyID, DirID: apiRootID, Name: "key"}},
+		},
+		"company/web": {
+			ParentPath: "company",
+			RootDir:    &api.Dir{DirID: webRootID, Name: "web"},
+			Dirs:       map[uuid.UUID]*api.Dir{webRootID: {DirID: webRootID, Name: "web"}},
+			Secrets:    map[uuid.UUID]*api.Secret{tokenID: {SecretID: tokenID, DirID: webRootID, Name: "token"}},
+		},
+	}
+
+	developer := api.AuditActor{Type: "user", User: &api.User{Username: "developer"}}
+	readKeyID := uuid.New()
+	readTokenID := uuid.New()
+	createKeyID := uuid.New()
+	events := map[string][]api.Audit{
+		"company/api": {
+			{
+				EventID:  readKeyID,
+				Action:   api.AuditActionRead,
+				Actor:    developer,
+				LoggedAt: time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC),
+				Subject: api.AuditSubject{
+					Type:          api.AuditSubjectSecretVersion,
+					SecretVersion: &api.SecretVersion{Version: 1, Secret: &api.Secret{SecretID: keyID}},
+				},
+			},
+			{
+				EventID:  createKeyID,
+				Action:   api.AuditActionCreate,
+				Actor:    developer,
+				LoggedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+				Subject: api.AuditSubject{
+					Type:   api.AuditSubjectSecret,
+					Secret: &api.Secret{SecretID: keyID},
+				},
+			},
+		},
+		"company/web": {
+			{
+				EventID:  readTokenID,
+				Action:   api.AuditActionRead,
+				Actor:    developer,
+				LoggedAt: time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC),
+				Subject: api.AuditSubject{
+					Type:          api.AuditSubjectSecretVersion,
+					SecretVersion: &api.SecretVersion{Version: 3, Secret: &api.Secret{SecretID: tokenID}},
+				},
+			},
+		},
+	}
+
+	readKey := `{"Author":"developer","Date":"date","Event":"read.secret_version","EventSubject":"company/api/key:1","IPAddress":""}` + "\n"
+	readToken := `{"Author":"developer","Date":"date","Event":"read.secret_version","EventSubject":"company/web/token:3","IPAddress":""}` + "\n"
+	createKey := `{"Author":"developer","Date":"date","Event":"create.secret","EventSubject":"company/api/key","IPAddress":""}` + "\n"
+
+	cases := map[string]struct {
+		cmd AuditCommand
+		err error
+		out string
+	}{
+		"merged chronologically": {
+			cmd: AuditCommand{
+				org:    "company",
+				format: formatJSON,
+			},
+			out: readKey + readToken + createKey,
+		},
+		"max results": {
+			cmd: AuditCommand{
+				org:        "company",
+				format:     formatJSON,
+				maxResults: 2,
+			},
+			out: readKey + readToken,
+		},
+		"filter": {
+			cmd: AuditCommand{
+				org:    "company",
+				format: formatJSON,
+				action: "create",
+			},
+			out: createKey,
+		},
+		"export": {
+			cmd: AuditCommand{
+				org:        "company",
+				format:     formatJSONL,
+				maxResults: 1,
+			},
+			out: `{"event_id":"` + readKeyID.String() + `","logged_at":"2026-01-10T00:00:00Z","actor":"developer","actor_type":"user","action":"read.secret_version","subject":"company/api/key:1","subject_type":"secret_version","repo":"company/api","ip_address":""}` + "\n",
+		},
+		"since cursor": {
+			cmd: AuditCommand{
+				org:         "company",
+				format:      formatJSON,
+				sinceCursor: readTokenID.String(),
+			},
+			out: readKey,
+		},
+		"org with path": {
+			cmd: AuditCommand{
+				org:    "company",
+				path:   "company/api",
+				format: formatJSON,
+			},
+			err: ErrAuditOrgConflict,
+		},
+		"org with dir": {
+			cmd: AuditCommand{
+				org:    "company",
+				dir:    "company/api/prod",
+				format: formatJSON,
+			},
+			err: ErrAuditOrgConflict,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			buffer := bytes.Buffer{}
+			tc.cmd.newPaginatedWriter = func(_ io.Writer) (io.WriteCloser, error) {
+				return &fakes.Pager{Buffer: &buffer}, nil
+			}
+			tc.cmd.io = fakeui.NewIO(t)
+			tc.cmd.perPage = 20
+			if tc.cmd.maxResults == 0 {
+				tc.cmd.maxResults = -1
+			}
+			tc.cmd.timeFormatter = &fakes.TimeFormatter{Response: "date"}
+			tc.cmd.newClient = func() (secrethub.ClientInterface, error) {
+				return auditOrgClient{
+					Client: fakeclient.Client{
+						DirService: &fakeclient.DirService{
+							GetTreeFunc: func(path string, depth int, ancestors bool) (*api.Tree, error) {
+								return trees[path], nil
+							},
+						},
+					},
+					repos: auditOrgRepoService{
+						RepoService: &fakeclient.RepoService{
+							ListFunc: func(namespace string) ([]*api.Repo, error) {
+								assert.Equal(t, namespace, "company")
+								return []*api.Repo{
+									{Owner: "company", Name: "web"},
+									{Owner: "company", Name: "api"},
+								}, nil
+							},
+						},
+						events: func(path string) []api.Audit {
+							return events[path]
+						},
+					},
+				}, nil
+			}
+
+			// Act
+			err := tc.cmd.run()
+
+			// Assert
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, buffer.String(), tc.out)
+		})
+	}
+}
+
+func TestAuditCommand_run_follow(t *testing.T) {
+	rootID := uuid.New()
+	prodID := uuid.New()
+	oldID := uuid.New()
+	newID := uuid.New()
+	keyID := uuid.New()
+	tree := &api.Tree{
+		ParentPath: "company",
+		RootDir:    &api.Dir{DirID: rootID, Name: "api"},
+		Dirs: map[uuid.UUID]*api.Dir{
+			rootID: {DirID: rootID, Name: "api"},
+			prodID: {DirID: prodID, ParentID: &rootID, Name: "prod"},
+		},
+		Secrets: map[uuid.UUID]*api.Secret{oldID: {SecretID: oldID, DirID: rootID, Name: "old"}},
+	}
+	// The secret created while following is only in the tree that is fetched after it was created.
+	newTree := &api.Tree{
+		ParentPath: tree.ParentPath,
+		RootDir:    tree.RootDir,
+		Dirs:       tree.Dirs,
+		Secrets: map[uuid.UUID]*api.Secret{
+			oldID: tree.Secrets[oldID],
+			newID: {SecretID: newID, DirID: rootID, Name: "new"},
+			keyID: {SecretID: keyID, DirID: prodID, Name: "key"},
+		},
+	}
+
+	developer := api.AuditActor{Type: "user", User: &api.User{Username: "developer"}}
+	event := func(loggedAt time.Time, action api.AuditAction, secretID uuid.UUID) api.Audit {
+		return api.Audit{
+			EventID:  uuid.New(),
+			Action:   action,
+			Actor:    developer,
+			LoggedAt: loggedAt,
+			Subject: api.AuditSubject{
+				Type:   api.AuditSubjectSecret,
+				Secret: &api.Secret{SecretID: secretID},
+			},
+		}
+	}
+	readOld := event(time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC), api.AuditActionRead, oldID)
+	updateOld := event(time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), api.AuditActionUpdate, oldID)
+	createOld := event(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), api.AuditActionCreate, oldID)
+	createNew := event(time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC), api.AuditActionCreate, newID)
+	readNew := event(time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC), api.AuditActionRead, newID)
+	createKey := event(time.Date(2026, 1, 6, 0, 0, 0, 0, time.UTC), api.AuditActionCreate, keyID)
+
+	line := func(action string, subject string) string {
+		return `{"Author":"developer","Date":"date","Event":"` + action + `","EventSubject":"` + subject + `","IPAddress":""}` + "\n"
+	}
+
+	cases := map[string]struct {
+		cmd   AuditCommand
+		polls [][]api.Audit
+		err   error
+		out   string
+	}{
+		"history oldest first": {
+			cmd: AuditCommand{
+				path:       "company/api",
+				maxResults: 2,
+			},
+			polls: [][]api.Audit{
+				{readOld, updateOld, createOld},
+			},
+			out: line("update.secret", "company/api/old") +
+				line("read.secret", "company/api/old"),
+		},
+		"new events": {
+			cmd: AuditCommand{
+				path: "company/api",
+			},
+			polls: [][]api.Audit{
+				{readOld, updateOld, createOld},
+				{readOld, updateOld, createOld},
+				{readNew, createNew, readOld, updateOld, createOld},
+			},
+			out: line("create.secret", "company/api/old") +
+				line("update.secret", "company/api/old") +
+				line("read.secret", "company/api/old") +
+				line("create.secret", "company/api/new") +
+				line("read.secret", "company/api/new"),
+		},
+		"new events filtered": {
+			cmd: AuditCommand{
+				path:   "company/api",
+				action: "read",
+			},
+			polls: [][]api.Audit{
+				{readOld, updateOld, createOld},
+				{readNew, createNew, readOld, updateOld, createOld},
+			},
+			out: line("read.secret", "company/api/old") +
+				line("read.secret", "company/api/new"),
+		},
+		"empty log": {
+			cmd: AuditCommand{
+				path: "company/api",
+			},
+			polls: [][]api.Audit{
+				{},
+				{createOld},
+			},
+			out: line("create.secret", "company/api/old"),
+		},
+		"secret": {
+			cmd: AuditCommand{
+				path: "company/api/old",
+			},
+			err: ErrAuditFollowNotRepo,
+		},
+		"dir": {
+			cmd: AuditCommand{
+				path: "company/api",
+				dir:  "company/api/prod",
+			},
+			polls: [][]api.Audit{
+				{readOld, updateOld, createOld},
+				{createKey, readNew, createNew, readOld, updateOld, createOld},
+			},
+			out: line("create.secret", "company/api/prod/key"),
+		},
+		"dir without path": {
+			cmd: AuditCommand{
+				dir: "company/api/prod",
+			},
+			polls: [][]api.Audit{
+				{readOld, updateOld, createOld},
+				{createKey, readNew, createNew, readOld, updateOld, createOld},
+			},
+			out: line("create.secret", "company/api/prod/key"),
+		},
+		"dir not in repo": {
+			cmd: AuditCommand{
+				path: "company/web",
+				dir:  "company/api/prod",
+			},
+			err: ErrAuditDirNotInRepo("company/api/prod", api.RepoPath("company/web")),
+		},
+		"cursor": {
+			cmd: AuditCommand{
+				path:        "company/api",
+				sinceCursor: readOld.EventID.String(),
+			},
+			err: ErrFlagsConflict("--follow and --since-cursor or --state-file"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			io := fakeui.NewIO(t)
+			tc.cmd.io = io
+			tc.cmd.follow = true
+			tc.cmd.format = formatJSON
+			tc.cmd.perPage = 20
+			if tc.cmd.maxResults == 0 {
+				tc.cmd.maxResults = -1
+			}
+			tc.cmd.pollInterval = time.Minute
+			tc.cmd.timeFormatter = &fakes.TimeFormatter{Response: "date"}
+
+			poll := 0
+			tc.cmd.wait = func(d time.Duration) bool {
+				assert.Equal(t, d, time.Minute)
+				poll++
+				return poll < len(tc.polls)
+			}
+			tc.cmd.newClient = func() (secrethub.ClientInterface, error) {
+				return auditOrgClient{
+					Client: fakeclient.Client{
+						DirService: &fakeclient.DirService{
+							GetTreeFunc: func(path string, depth int, ancestors bool) (*api.Tree, error) {
+								if poll > 0 {
+									return newTree, nil
+								}
+								return tree, nil
+							},
+						},
+					},
+					repos: auditOrgRepoService{
+						RepoService: &fakeclient.RepoService{},
+						events: func(path string) []api.Audit {
+							assert.Equal(t, path, "company/api")
+							return tc.polls[poll]
+						},
+					},
+				}, nil
+			}
+
+			// Act
+			err := tc.cmd.run()
+
+			// Assert
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, io.Out.String(), tc.out)
+		})
+	}
+}
+
+func TestAuditCommand_beforeRun_follow(t *testing.T) {
+	cases := map[string]struct {
+		args     []string
+		expected int
+	}{
+		"follow": {
+			args:     []string{"--follow"},
+			expected: followLineLimit,
+		},
+		"follow with max results": {
+			args:     []string{"--follow", "--max-results", "50"},
+			expected: 50,
+		},
+		"follow with default max results": {
+			args:     []string{"--follow", "--max-results", "1000"},
+			expected: pipedOutputLineLimit,
+		},
+		"no follow": {
+			expected: pipedOutputLineLimit,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			io := fakeui.NewIO(t)
+			io.Out.Piped = true
+			app := cli.NewApp("secrethub", "")
+			cmd := NewAuditCommand(io, nil)
+			cmd.Register(app)
+			err := app.Root.Cmd.Commands()[0].ParseFlags(tc.args)
+			assert.OK(t, err)
+
+			// Act
+			cmd.beforeRun()
+
+			// Assert
+			assert.Equal(t, cmd.maxResults, tc.expected)
+		})
+	}
+}
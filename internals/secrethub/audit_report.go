@@ -0,0 +1,408 @@
+package secrethub
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/policy"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/api/uuid"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/iterator"
+
+	"github.com/spf13/cobra"
+)
+
+// Errors
+var (
+	ErrInvalidBurstReads = errAudit.Code("invalid_burst_reads").ErrorPref("--burst-reads must be at least 2, got %d")
+)
+
+const (
+	anomalyFirstRead         = "first read"
+	anomalyReadBurst         = "read burst"
+	anomalyDormantService    = "dormant service"
+	anomalyUnusedWriteAccess = "unused write access"
+)
+
+// AuditReportCommand analyzes the audit log of a repository for unusual access and unused accounts.
+type AuditReportCommand struct {
+	io            ui.IO
+	path          api.RepoPath
+	since         string
+	history       string
+	burstReads    int
+	burstWindow   string
+	inactiveFor   string
+	format        string
+	useTimestamps bool
+	timeFormatter TimeFormatter
+	newClient     newClientFunc
+}
+
+// NewAuditReportCommand creates a new AuditReportCommand.
+func NewAuditReportCommand(io ui.IO, newClient newClientFunc) *AuditReportCommand {
+	return &AuditReportCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *AuditReportCommand) Register(r cli.Registerer) {
+	clause := r.Command("report", "Report unusual reads and unused accounts in the audit log of a repository.")
+	clause.HelpLong("The report analyzes the audit log of the repository and shows:\n\n" +
+		"  first read            an account read a secret it never read before\n" +
+		"  read burst            an account read many secrets in a short time\n" +
+		"  dormant service       a service has not been used for a while\n" +
+		"  unused write access   a user has write access but never wrote anything\n\n" +
+		"First reads and read bursts are only reported for the period given with --since; the events before it serve as the history to compare with. " +
+		"Services and access rules created within the period given with --inactive-for are not reported, as they have not had the chance to be used yet.\n\n" +
+		"The log is read back to the period given with --history before --since, or further back when --inactive-for requires it, " +
+		"so that the complete log does not have to be read.")
+	clause.Flags().StringVar(&cmd.since, "since", "30d", "Report first reads and read bursts from this time on. Takes a date such as 2020-12-31, an RFC3339 timestamp or a duration such as 30d.")
+	clause.Flags().StringVar(&cmd.history, "history", "180d", "How far before --since the log is read to find out whether a secret was read before. Use all to read the complete log.")
+	clause.Flags().IntVar(&cmd.burstReads, "burst-reads", 20, "The number of reads by a single account that counts as a burst.")
+	clause.Flags().StringVar(&cmd.burstWindow, "burst-window", "1h", "The period in which the reads of a burst take place, e.g. 10m or 1h.")
+	clause.Flags().StringVar(&cmd.inactiveFor, "inactive-for", "90d", "Report services that have not been used for this period.")
+	clause.Flags().StringVar(&cmd.format, "output-format", formatTable, "Specify the format in which to output the report. Options are: table and json.")
+	_ = clause.Cmd.RegisterFlagCompletionFunc("output-format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{formatTable, formatJSON}, cobra.ShellCompDirectiveDefault
+	})
+	registerTimestampFlag(clause, &cmd.useTimestamps)
+
+	clause.BindAction(cmd.Run)
+	clause.BindArguments([]cli.Argument{
+		{Value: &cmd.path, Name: "repo-path", Required: true, Placeholder: repoPathPlaceHolder, Description: "The path of the repository to report on."},
+	})
+}
+
+// Run analyzes the audit log and prints the anomalies.
+func (cmd *AuditReportCommand) Run() error {
+	cmd.beforeRun()
+	return cmd.run(time.Now())
+}
+
+// beforeRun configures the command using the flag values.
+func (cmd *AuditReportCommand) beforeRun() {
+	cmd.timeFormatter = NewTimeFormatter(cmd.useTimestamps || cmd.format == formatJSON)
+}
+
+// auditAnomaly is a finding in the audit log that should be reviewed.
+type auditAnomaly struct {
+	kind    string
+	account string
+	subject string
+	date    time.Time
+	details string
+}
+
+// auditReportConfig contains the thresholds of the analysis.
+type auditReportConfig struct {
+	since time.Time
+	// from is the time from which on the log is read. When it is zero, the complete log is read.
+	from        time.Time
+	burstReads  int
+	burstWindow time.Duration
+	inactiveFor time.Duration
+	now         time.Time
+}
+
+// run analyzes the audit log and prints the anomalies.
+func (cmd *AuditReportCommand) run(now time.Time) error {
+	if cmd.format != formatTable && cmd.format != formatJSON {
+		return errNoSuchFormat(cmd.format)
+	}
+
+	config, err := cmd.config(now)
+	if err != nil {
+		return err
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	tree, err := client.Dirs().GetTree(cmd.path.GetDirPath().Value(), -1, false)
+	if err != nil {
+		return err
+	}
+
+	services, err := client.Services().List(cmd.path.Value())
+	if err != nil {
+		return err
+	}
+
+	rules, err := client.AccessRules().List(cmd.path.Value(), -1, false)
+	if err != nil {
+		return err
+	}
+
+	// The log is analyzed oldest first, while the iterator returns the most recent events first.
+	var events []api.Audit
+	iter := client.Repos().EventIterator(cmd.path.Value(), &secrethub.AuditEventIteratorParams{})
+	for {
+		event, err := iter.Next()
+		if err == iterator.Done {
+			break
+		} else if err != nil {
+			return err
+		}
+		if !config.from.IsZero() && event.LoggedAt.Before(config.from) {
+			break
+		}
+		events = append(events, event)
+	}
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+
+	anomalies, err := analyzeAuditLog(events, tree, services, rules, config)
+	if err != nil {
+		return err
+	}
+
+	if cmd.format == formatJSON {
+		formatter := newJSONFormatter(cmd.io.Output(), []string{"type", "account", "subject", "date", "details"})
+		for _, anomaly := range anomalies {
+			err = formatter.Write(cmd.row(anomaly))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(anomalies) == 0 {
+		fmt.Fprintf(cmd.io.Output(), "No anomalies found in the audit log of %s.\n", cmd.path)
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(cmd.io.Output(), 0, 4, 4, ' ', 0)
+	fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", "TYPE", "ACCOUNT", "SUBJECT", "DATE", "DETAILS")
+	for _, anomaly := range anomalies {
+		fmt.Fprintln(tw, strings.Join(cmd.row(anomaly), "\t"))
+	}
+	return tw.Flush()
+}
+
+// row returns the fields of an anomaly to print.
+func (cmd *AuditReportCommand) row(anomaly auditAnomaly) []string {
+	date := ""
+	if !anomaly.date.IsZero() {
+		date = cmd.timeFormatter.Format(anomaly.date.Local())
+	}
+	return []string{anomaly.kind, anomaly.account, anomaly.subject, date, anomaly.details}
+}
+
+// config creates the thresholds of the analysis from the flags.
+func (cmd *AuditReportCommand) config(now time.Time) (auditReportConfig, error) {
+	since, err := parseSearchTime(cmd.since, now)
+	if err != nil {
+		return auditReportConfig{}, err
+	}
+
+	if cmd.burstReads < 2 {
+		return auditReportConfig{}, ErrInvalidBurstReads(cmd.burstReads)
+	}
+
+	burstWindow, err := policy.ParseDuration(cmd.burstWindow)
+	if err != nil || burstWindow <= 0 {
+		return auditReportConfig{}, ErrInvalidWithin(cmd.burstWindow)
+	}
+
+	inactiveFor, err := policy.ParseDuration(cmd.inactiveFor)
+	if err != nil || inactiveFor < 0 {
+		return auditReportConfig{}, ErrInvalidWithin(cmd.inactiveFor)
+	}
+
+	var from time.Time
+	if cmd.history != "" && cmd.history != "all" {
+		history, err := policy.ParseDuration(cmd.history)
+		if err != nil || history < 0 {
+			return auditReportConfig{}, ErrInvalidWithin(cmd.history)
+		}
+		from = since.Add(-history)
+		if inactiveSince := now.Add(-inactiveFor); inactiveSince.Before(from) {
+			from = inactiveSince
+		}
+	}
+
+	return auditReportConfig{
+		since:       since,
+		from:        from,
+		burstReads:  cmd.burstReads,
+		burstWindow: burstWindow,
+		inactiveFor: inactiveFor,
+		now:         now,
+	}, nil
+}
+
+// analyzeAuditLog returns the anomalies in the events of a repository, which must be ordered oldest first.
+func analyzeAuditLog(events []api.Audit, tree *api.Tree, services []*api.Service, rules []*api.AccessRule, config auditReportConfig) ([]auditAnomaly, error) {
+	var anomalies []auditAnomaly
+
+	secretsRead := make(map[string]map[uuid.UUID]bool)
+	recentReads := make(map[string][]api.Audit)
+	var readers []string
+	lastUsed := make(map[string]time.Time)
+	wrote := make(map[string]bool)
+
+	for _, event := range events {
+		actor, err := getAuditActor(event)
+		if err != nil {
+			return nil, err
+		}
+		actorKey := strings.ToLower(actor)
+		lastUsed[actorKey] = event.LoggedAt
+
+		if event.Action != api.AuditActionRead {
+			wrote[actorKey] = true
+			continue
+		}
+
+		secretID, ok := auditSubjectSecretID(event)
+		if !ok {
+			continue
+		}
+
+		recent := !event.LoggedAt.Before(config.since)
+		if recent {
+			if _, ok := recentReads[actorKey]; !ok {
+				readers = append(readers, actorKey)
+			}
+			recentReads[actorKey] = append(recentReads[actorKey], event)
+		}
+
+		if secretsRead[actorKey] == nil {
+			secretsRead[actorKey] = make(map[uuid.UUID]bool)
+		}
+		if !secretsRead[actorKey][secretID] && recent {
+			anomalies = append(anomalies, auditAnomaly{
+				kind:    anomalyFirstRead,
+				account: actor,
+				subject: auditReportSecretPath(tree, secretID),
+				date:    event.LoggedAt,
+				details: "first read of the secret by the account",
+			})
+		}
+		secretsRead[actorKey][secretID] = true
+	}
+
+	for _, reader := range readers {
+		anomalies = append(anomalies, findReadBursts(recentReads[reader], config)...)
+	}
+
+	inactiveSince := config.now.Add(-config.inactiveFor)
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].ServiceID < services[j].ServiceID
+	})
+	for _, service := range services {
+		if service.CreatedAt.After(inactiveSince) {
+			continue
+		}
+
+		used, ok := lastUsed[strings.ToLower(service.ServiceID)]
+		if !ok && !config.from.IsZero() {
+			anomalies = append(anomalies, auditAnomaly{
+				kind:    anomalyDormantService,
+				account: service.ServiceID,
+				details: "not used since " + config.from.UTC().Format("2006-01-02"),
+			})
+		} else if !ok {
+			anomalies = append(anomalies, auditAnomaly{
+				kind:    anomalyDormantService,
+				account: service.ServiceID,
+				details: "never used",
+			})
+		} else if used.Before(inactiveSince) {
+			anomalies = append(anomalies, auditAnomaly{
+				kind:    anomalyDormantService,
+				account: service.ServiceID,
+				date:    used,
+				details: "not used since " + used.UTC().Format("2006-01-02"),
+			})
+		}
+	}
+
+	var unused []auditAnomaly
+	for _, rule := range rules {
+		if rule.Account == nil || !rule.Account.Name.IsUser() || rule.Permission < api.PermissionWrite {
+			continue
+		}
+		if rule.CreatedAt.After(inactiveSince) || wrote[strings.ToLower(rule.Account.Name.String())] {
+			continue
+		}
+
+		dirPath, err := tree.AbsDirPath(rule.DirID)
+		if err != nil {
+			return nil, err
+		}
+		details := rule.Permission.String() + " permission, but never wrote"
+		if !config.from.IsZero() {
+			details = rule.Permission.String() + " permission, but did not write since " + config.from.UTC().Format("2006-01-02")
+		}
+		unused = append(unused, auditAnomaly{
+			kind:    anomalyUnusedWriteAccess,
+			account: rule.Account.Name.String(),
+			subject: dirPath.String(),
+			date:    rule.CreatedAt,
+			details: details,
+		})
+	}
+	sort.Slice(unused, func(i, j int) bool {
+		if unused[i].subject != unused[j].subject {
+			return unused[i].subject < unused[j].subject
+		}
+		return unused[i].account < unused[j].account
+	})
+
+	return append(anomalies, unused...), nil
+}
+
+// findReadBursts returns a read burst for every series of reads of a single account
+// in which at least the configured number of reads take place within the burst window.
+func findReadBursts(reads []api.Audit, config auditReportConfig) []auditAnomaly {
+	var bursts []auditAnomaly
+	for i := 0; i < len(reads); {
+		j := i
+		secrets := make(map[uuid.UUID]bool)
+		for j < len(reads) && reads[j].LoggedAt.Sub(reads[i].LoggedAt) <= config.burstWindow {
+			secretID, _ := auditSubjectSecretID(reads[j])
+			secrets[secretID] = true
+			j++
+		}
+
+		if j-i < config.burstReads {
+			i++
+			continue
+		}
+
+		actor, _ := getAuditActor(reads[i])
+		bursts = append(bursts, auditAnomaly{
+			kind:    anomalyReadBurst,
+			account: actor,
+			subject: pluralize("secret", "secrets", len(secrets)),
+			date:    reads[i].LoggedAt,
+			details: fmt.Sprintf("%d reads within %s", j-i, reads[j-1].LoggedAt.Sub(reads[i].LoggedAt)),
+		})
+		i = j
+	}
+	return bursts
+}
+
+// auditReportSecretPath returns the path of the secret, or its ID when it is no longer in the repository.
+func auditReportSecretPath(tree *api.Tree, secretID uuid.UUID) string {
+	secretPath, err := tree.AbsSecretPath(secretID)
+	if err != nil {
+		return secretID.String()
+	}
+	return secretPath.String()
+}
@@ -0,0 +1,253 @@
+package secrethub
+
+import (
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/fakes"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/api/uuid"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestAuditReportCommand_run(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	longAgo := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	rootID := uuid.New()
+	prodID := uuid.New()
+	passwordID := uuid.New()
+	tokenID := uuid.New()
+	tree := &api.Tree{
+		ParentPath: "namespace",
+		RootDir:    &api.Dir{DirID: rootID, Name: "repo"},
+		Dirs: map[uuid.UUID]*api.Dir{
+			rootID: {DirID: rootID, Name: "repo"},
+			prodID: {DirID: prodID, ParentID: &rootID, Name: "prod"},
+		},
+		Secrets: map[uuid.UUID]*api.Secret{
+			passwordID: {SecretID: passwordID, DirID: prodID, Name: "password"},
+			tokenID:    {SecretID: tokenID, DirID: rootID, Name: "token"},
+		},
+	}
+
+	user := func(username string) api.AuditActor {
+		return api.AuditActor{Type: "user", User: &api.User{Username: username}}
+	}
+	service := func(serviceID string) api.AuditActor {
+		return api.AuditActor{Type: "service", Service: &api.Service{ServiceID: serviceID}}
+	}
+	read := func(actor api.AuditActor, secretID uuid.UUID, loggedAt time.Time) api.Audit {
+		return api.Audit{
+			Action:   api.AuditActionRead,
+			Actor:    actor,
+			LoggedAt: loggedAt,
+			Subject: api.AuditSubject{
+				Type:          api.AuditSubjectSecretVersion,
+				SecretVersion: &api.SecretVersion{Version: 1, Secret: &api.Secret{SecretID: secretID}},
+			},
+		}
+	}
+
+	// The events are returned most recent first.
+	events := []api.Audit{
+		read(service("s-ci"), passwordID, time.Date(2026, 5, 20, 12, 20, 0, 0, time.UTC)),
+		read(service("s-ci"), tokenID, time.Date(2026, 5, 20, 12, 10, 0, 0, time.UTC)),
+		read(service("s-ci"), tokenID, time.Date(2026, 5, 20, 12, 0, 0, 0, time.UTC)),
+		read(user("developer"), tokenID, time.Date(2026, 5, 10, 11, 0, 0, 0, time.UTC)),
+		read(user("developer"), passwordID, time.Date(2026, 5, 10, 10, 0, 0, 0, time.UTC)),
+		read(user("developer"), passwordID, time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)),
+		read(service("s-old"), passwordID, time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)),
+		{
+			Action:   api.AuditActionCreate,
+			Actor:    user("owner"),
+			LoggedAt: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC),
+			Subject: api.AuditSubject{
+				Type:   api.AuditSubjectSecret,
+				Secret: &api.Secret{SecretID: passwordID},
+			},
+		},
+	}
+
+	services := []*api.Service{
+		{ServiceID: "s-old", CreatedAt: longAgo},
+		{ServiceID: "s-ci", CreatedAt: longAgo},
+		{ServiceID: "s-never", CreatedAt: longAgo},
+		{ServiceID: "s-new", CreatedAt: time.Date(2026, 5, 20, 0, 0, 0, 0, time.UTC)},
+	}
+
+	rule := func(name string, dirID uuid.UUID, permission api.Permission, createdAt time.Time) *api.AccessRule {
+		return &api.AccessRule{
+			Account:    &api.Account{Name: api.AccountName(name)},
+			DirID:      dirID,
+			Permission: permission,
+			CreatedAt:  createdAt,
+		}
+	}
+	rules := []*api.AccessRule{
+		rule("developer", rootID, api.PermissionWrite, longAgo),
+		rule("owner", rootID, api.PermissionAdmin, longAgo),
+		rule("reader", rootID, api.PermissionRead, longAgo),
+		rule("s-ci", prodID, api.PermissionWrite, longAgo),
+		rule("newbie", prodID, api.PermissionWrite, time.Date(2026, 5, 25, 0, 0, 0, 0, time.UTC)),
+		rule("intern", prodID, api.PermissionAdmin, longAgo),
+	}
+
+	cases := map[string]struct {
+		cmd      AuditReportCommand
+		events   []api.Audit
+		services []*api.Service
+		rules    []*api.AccessRule
+		out      string
+		err      error
+	}{
+		"table": {
+			cmd: AuditReportCommand{
+				burstReads: 3,
+				format:     formatTable,
+			},
+			events:   events,
+			services: services,
+			rules:    rules,
+			out: "TYPE                   ACCOUNT      SUBJECT                         DATE    DETAILS\n" +
+				"first read             developer    namespace/repo/token            date    first read of the secret by the account\n" +
+				"first read             s-ci         namespace/repo/token            date    first read of the secret by the account\n" +
+				"first read             s-ci         namespace/repo/prod/password    date    first read of the secret by the account\n" +
+				"read burst             s-ci         2 secrets                       date    3 reads within 20m0s\n" +
+				"dormant service        s-never                                              never used\n" +
+				"dormant service        s-old                                        date    not used since 2026-01-05\n" +
+				"unused write access    developer    namespace/repo                  date    write permission, but never wrote\n" +
+				"unused write access    intern       namespace/repo/prod             date    admin permission, but never wrote\n",
+		},
+		"limited history": {
+			cmd: AuditReportCommand{
+				history:    "30d",
+				burstReads: 3,
+				format:     formatTable,
+			},
+			events:   events,
+			services: services,
+			rules:    rules,
+			out: "TYPE                   ACCOUNT      SUBJECT                         DATE    DETAILS\n" +
+				"first read             developer    namespace/repo/prod/password    date    first read of the secret by the account\n" +
+				"first read             developer    namespace/repo/token            date    first read of the secret by the account\n" +
+				"first read             s-ci         namespace/repo/token            date    first read of the secret by the account\n" +
+				"first read             s-ci         namespace/repo/prod/password    date    first read of the secret by the account\n" +
+				"read burst             s-ci         2 secrets                       date    3 reads within 20m0s\n" +
+				"dormant service        s-never                                              not used since 2026-03-03\n" +
+				"dormant service        s-old                                                not used since 2026-03-03\n" +
+				"unused write access    developer    namespace/repo                  date    write permission, but did not write since 2026-03-03\n" +
+				"unused write access    owner        namespace/repo                  date    admin permission, but did not write since 2026-03-03\n" +
+				"unused write access    intern       namespace/repo/prod             date    admin permission, but did not write since 2026-03-03\n",
+		},
+		"json": {
+			cmd: AuditReportCommand{
+				burstReads: 3,
+				format:     formatJSON,
+			},
+			events: events[:3],
+			out: `{"Account":"s-ci","Date":"date","Details":"first read of the secret by the account","Subject":"namespace/repo/token","Type":"first read"}` + "\n" +
+				`{"Account":"s-ci","Date":"date","Details":"first read of the secret by the account","Subject":"namespace/repo/prod/password","Type":"first read"}` + "\n" +
+				`{"Account":"s-ci","Date":"date","Details":"3 reads within 20m0s","Subject":"2 secrets","Type":"read burst"}` + "\n",
+		},
+		"burst outside window": {
+			cmd: AuditReportCommand{
+				burstReads:  3,
+				burstWindow: "15m",
+				format:      formatJSON,
+			},
+			events: events[:3],
+			out: `{"Account":"s-ci","Date":"date","Details":"first read of the secret by the account","Subject":"namespace/repo/token","Type":"first read"}` + "\n" +
+				`{"Account":"s-ci","Date":"date","Details":"first read of the secret by the account","Subject":"namespace/repo/prod/password","Type":"first read"}` + "\n",
+		},
+		"no anomalies": {
+			cmd: AuditReportCommand{
+				burstReads: 20,
+				format:     formatTable,
+			},
+			events: events[5:],
+			out:    "No anomalies found in the audit log of namespace/repo.\n",
+		},
+		"invalid burst reads": {
+			cmd: AuditReportCommand{
+				burstReads: 1,
+				format:     formatTable,
+			},
+			err: ErrInvalidBurstReads(1),
+		},
+		"invalid inactive for": {
+			cmd: AuditReportCommand{
+				burstReads:  20,
+				inactiveFor: "a quarter",
+				format:      formatTable,
+			},
+			err: ErrInvalidWithin("a quarter"),
+		},
+		"invalid history": {
+			cmd: AuditReportCommand{
+				history:    "forever",
+				burstReads: 20,
+				format:     formatTable,
+			},
+			err: ErrInvalidWithin("forever"),
+		},
+		"invalid format": {
+			cmd: AuditReportCommand{
+				format: "yaml",
+			},
+			err: errNoSuchFormat("yaml"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			io := fakeui.NewIO(t)
+			tc.cmd.io = io
+			tc.cmd.path = "namespace/repo"
+			tc.cmd.since = "30d"
+			if tc.cmd.burstWindow == "" {
+				tc.cmd.burstWindow = "1h"
+			}
+			if tc.cmd.inactiveFor == "" {
+				tc.cmd.inactiveFor = "90d"
+			}
+			tc.cmd.timeFormatter = &fakes.TimeFormatter{Response: "date"}
+			tc.cmd.newClient = func() (secrethub.ClientInterface, error) {
+				return fakeclient.Client{
+					DirService: &fakeclient.DirService{
+						GetTreeFunc: func(path string, depth int, ancestors bool) (*api.Tree, error) {
+							return tree, nil
+						},
+					},
+					ServiceService: &fakeclient.ServiceService{
+						ListFunc: func(path string) ([]*api.Service, error) {
+							return tc.services, nil
+						},
+					},
+					AccessRuleService: &fakeclient.AccessRuleService{
+						ListFunc: func(path string, depth int, ancestors bool) ([]*api.AccessRule, error) {
+							return tc.rules, nil
+						},
+					},
+					RepoService: &fakeclient.RepoService{
+						AuditEventIterator: &fakeclient.AuditEventIterator{
+							Events: tc.events,
+						},
+					},
+				}, nil
+			}
+
+			// Act
+			err := tc.cmd.run(now)
+
+			// Assert
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, io.Out.String(), tc.out)
+		})
+	}
+}
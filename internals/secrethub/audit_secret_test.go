@@ -117,7 +117,7 @@ func TestAuditSecretCommand_run(t *testing.T) {
 			},
 			err: ErrCannotFindHomeDir(),
 		},
-		"error can not audit dir": {
+		"dir audits its repository": {
 			cmd: AuditCommand{
 				path: "namespace/repo/dir",
 				newClient: func() (secrethub.ClientInterface, error) {
@@ -126,18 +126,29 @@ func TestAuditSecretCommand_run(t *testing.T) {
 							ExistsFunc: func(_ string) (bool, error) {
 								return true, nil
 							},
+							GetTreeFunc: func(path string, depth int, ancestors bool) (*api.Tree, error) {
+								return nil, nil
+							},
 						},
 						SecretService: &fakeclient.SecretService{
 							AuditEventIterator: &fakeclient.AuditEventIterator{
 								Err: api.ErrSecretNotFound,
 							},
 						},
+						RepoService: &fakeclient.RepoService{
+							AuditEventIterator: &fakeclient.AuditEventIterator{
+								Events: []api.Audit{},
+							},
+						},
 					}, nil
 				},
-				format:  formatTable,
-				perPage: 20,
+				format:     formatTable,
+				perPage:    20,
+				maxResults: -1,
+				terminalWidth: func(int) (int, error) {
+					return 83, nil
+				},
 			},
-			err: ErrCannotAuditDir,
 		},
 		"other list audit events error": {
 			cmd: AuditCommand{
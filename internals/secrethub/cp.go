@@ -0,0 +1,492 @@
+package secrethub
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secretpath"
+)
+
+// Errors
+var (
+	ErrCannotCopyDir        = errMain.Code("cannot_copy_dir").Error("cannot copy directory. Use the -r flag to copy directories.")
+	ErrCannotMoveRootDir    = errMain.Code("cannot_move_root_dir").Error("cannot move the root directory of a repository. Use cp -r to copy its contents")
+	ErrCannotCopyIntoItself = errMain.Code("cannot_copy_into_itself").ErrorPref("cannot copy or move %s into itself")
+	ErrDestinationExists    = errMain.Code("destination_exists").ErrorPref("the secret %s already exists. Use --force to write the copied versions on top of it")
+)
+
+// CpCommand copies secrets and directories.
+type CpCommand struct {
+	transfer secretTransfer
+}
+
+// NewCpCommand creates a new CpCommand.
+func NewCpCommand(io ui.IO, newClient newClientFunc) *CpCommand {
+	return &CpCommand{
+		transfer: secretTransfer{
+			io:        io,
+			newClient: newClient,
+		},
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *CpCommand) Register(r cli.Registerer) {
+	clause := r.Command("cp", "Copy a secret or directory.")
+	clause.Alias("copy")
+	clause.HelpLong("Copy a secret or, with -r, a directory and its contents to another path, which can be in another repository. " +
+		"When the destination is an existing directory, the source is copied into it. " +
+		"By default, only the latest version of every secret is copied. With --all-versions, the history is replayed in order, " +
+		"so the copied secret gets a new version for every version of the source.\n\n" +
+		"Before copying, the accounts whose access at the destination differs from their access to the source are listed.")
+	cmd.transfer.register(clause)
+}
+
+// Run copies the secret or directory.
+func (cmd *CpCommand) Run() error {
+	return cmd.transfer.run()
+}
+
+// secretTransfer holds the logic shared by the cp and mv commands.
+type secretTransfer struct {
+	io          ui.IO
+	src         api.Path
+	dst         api.Path
+	recursive   bool
+	allVersions bool
+	dryRun      bool
+	force       bool
+	move        bool
+	newClient   newClientFunc
+}
+
+// register registers the arguments and flags shared by the cp and mv commands.
+func (t *secretTransfer) register(clause *cli.CommandClause) {
+	verb := "copy"
+	if t.move {
+		verb = "move"
+	}
+
+	clause.Flags().BoolVarP(&t.recursive, "recursive", "r", false, "Recursively "+verb+" directories and their contents.")
+	// A move always replays all versions, as the history of the source is removed afterwards.
+	if !t.move {
+		clause.Flags().BoolVar(&t.allVersions, "all-versions", false, "Replay all versions of the secrets instead of only the latest version.")
+	}
+	clause.Flags().BoolVar(&t.dryRun, "dry-run", false, "List what would be "+verb+"d without changing anything.")
+	if t.move {
+		clause.Flags().BoolVarP(&t.force, "force", "f", false, "Write the versions on top of secrets that already exist at the destination and remove the source without asking for confirmation.")
+	} else {
+		clause.Flags().BoolVarP(&t.force, "force", "f", false, "Write the versions on top of secrets that already exist at the destination.")
+	}
+
+	clause.BindAction(t.run)
+	clause.BindArguments([]cli.Argument{
+		{Value: &t.src, Name: "source", Required: true, Placeholder: optionalSecretPathPlaceHolder, Description: "The path to the secret or directory to " + verb + "."},
+		{Value: &t.dst, Name: "destination", Required: true, Placeholder: optionalSecretPathPlaceHolder, Description: "The path to " + verb + " to."},
+	})
+}
+
+// transferPlan lists the changes needed to copy a secret or directory.
+type transferPlan struct {
+	// src is the cleaned path of the source.
+	src string
+	// isDir is true when the source is a directory.
+	isDir bool
+	// srcDir and dstDir are the directories whose access levels are compared.
+	srcDir string
+	dstDir string
+	// dirs are the directories to create at the destination, parents first.
+	dirs []string
+	// secrets are the secrets to copy, sorted by source path.
+	secrets []secretCopy
+}
+
+// secretCopy is a secret to copy.
+type secretCopy struct {
+	src      string
+	dst      string
+	versions int
+}
+
+// run copies or moves the source to the destination.
+func (t *secretTransfer) run() error {
+	client, err := t.newClient()
+	if err != nil {
+		return err
+	}
+
+	plan, err := t.plan(client)
+	if err != nil {
+		return err
+	}
+
+	err = t.printACLDiff(client, plan)
+	if err != nil {
+		return err
+	}
+
+	if t.dryRun {
+		t.printPlan(plan)
+		return nil
+	}
+
+	if t.move && !t.force {
+		confirmed, err := ui.AskYesNo(
+			t.io,
+			fmt.Sprintf("[WARNING] This permanently removes %s once it has been copied to %s. Do you want to continue?", plan.src, t.dst),
+			ui.DefaultNo,
+		)
+		if err == ui.ErrCannotAsk {
+			return ErrCannotDoWithoutForce
+		} else if err != nil {
+			return err
+		}
+
+		if !confirmed {
+			fmt.Fprintln(t.io.Output(), "Aborting.")
+			return nil
+		}
+	}
+
+	err = t.execute(client, plan)
+	if err != nil {
+		return err
+	}
+
+	verb := "Copy"
+	if t.move {
+		verb = "Move"
+	}
+	fmt.Fprintf(t.io.Output(), "%s complete! %s transferred to %s.\n", verb, pluralize("secret", "secrets", len(plan.secrets)), t.dst)
+	return nil
+}
+
+// plan determines which directories and secrets to create, without changing anything.
+func (t *secretTransfer) plan(client secrethub.ClientInterface) (*transferPlan, error) {
+	if t.src.HasVersion() {
+		return nil, errCannotWriteToVersion
+	}
+	src := secretpath.Clean(t.src.String())
+	dst := secretpath.Clean(t.dst.String())
+
+	tree, err := client.Dirs().GetTree(src, -1, false)
+	isDir := err == nil
+	if err != nil && !api.IsErrNotFound(err) {
+		return nil, err
+	}
+
+	if isDir {
+		if !t.recursive {
+			return nil, ErrCannotCopyDir
+		}
+		if t.move && secretpath.Count(src) <= 2 {
+			return nil, ErrCannotMoveRootDir
+		}
+	}
+
+	var secret *api.Secret
+	if !isDir {
+		secret, err = client.Secrets().Get(src)
+		if api.IsErrNotFound(err) {
+			return nil, ErrResourceNotFound(src)
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	dstIsDir, err := client.Dirs().Exists(dst)
+	if err != nil {
+		return nil, err
+	}
+	target := dst
+	if dstIsDir {
+		target = secretpath.Join(dst, secretpath.Base(src))
+	}
+
+	if target == src || strings.HasPrefix(target, src+"/") {
+		return nil, ErrCannotCopyIntoItself(src)
+	}
+
+	if !isDir {
+		_, err = api.NewSecretPath(target)
+		if err != nil {
+			return nil, err
+		}
+		err = t.checkDestination(client, target, dstIsDir)
+		if err != nil {
+			return nil, err
+		}
+
+		return &transferPlan{
+			src:    src,
+			srcDir: secretpath.Parent(src),
+			dstDir: secretpath.Parent(target),
+			secrets: []secretCopy{{
+				src:      src,
+				dst:      target,
+				versions: t.versionCount(secret),
+			}},
+		}, nil
+	}
+
+	targetExists := false
+	if dstIsDir {
+		targetExists, err = client.Dirs().Exists(target)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	plan := &transferPlan{
+		src:    src,
+		isDir:  true,
+		srcDir: src,
+		dstDir: secretpath.Parent(target),
+	}
+	if targetExists {
+		plan.dstDir = target
+	}
+
+	for id := range tree.Dirs {
+		dirPath, err := tree.AbsDirPath(id)
+		if err != nil {
+			return nil, err
+		}
+		dir := target + strings.TrimPrefix(dirPath.Value(), src)
+
+		exists := false
+		if targetExists {
+			exists, err = client.Dirs().Exists(dir)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if !exists {
+			plan.dirs = append(plan.dirs, dir)
+		}
+	}
+	sort.Strings(plan.dirs)
+
+	for id, secret := range tree.Secrets {
+		// The annotations are merged into those of the destination instead of overwriting them.
+		if strings.EqualFold(secret.Name, annotationsFileName) {
+			continue
+		}
+
+		secretPath, err := tree.AbsSecretPath(id)
+		if err != nil {
+			return nil, err
+		}
+		secretDst := target + strings.TrimPrefix(secretPath.Value(), src)
+
+		if targetExists {
+			err = t.checkDestination(client, secretDst, true)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		plan.secrets = append(plan.secrets, secretCopy{
+			src:      secretPath.Value(),
+			dst:      secretDst,
+			versions: t.versionCount(secret),
+		})
+	}
+	sort.Slice(plan.secrets, func(i, j int) bool {
+		return plan.secrets[i].src < plan.secrets[j].src
+	})
+
+	return plan, nil
+}
+
+// checkDestination returns an error when a secret exists at the destination, unless --force is set.
+// The check is skipped when the directory of the destination does not exist yet.
+func (t *secretTransfer) checkDestination(client secrethub.ClientInterface, dst string, dirExists bool) error {
+	if t.force {
+		return nil
+	}
+
+	if !dirExists {
+		exists, err := client.Dirs().Exists(secretpath.Parent(dst))
+		if err != nil || !exists {
+			return err
+		}
+	}
+
+	exists, err := client.Secrets().Exists(dst)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrDestinationExists(dst)
+	}
+	return nil
+}
+
+// replayAll returns whether all versions of the secrets are copied.
+func (t *secretTransfer) replayAll() bool {
+	return t.allVersions || t.move
+}
+
+// versionCount returns the number of versions of the secret to copy.
+func (t *secretTransfer) versionCount(secret *api.Secret) int {
+	if t.replayAll() {
+		return secret.VersionCount
+	}
+	return 1
+}
+
+// execute creates the directories and copies the secrets of the plan, together with their annotations.
+// When moving, the source is removed after everything has been copied.
+func (t *secretTransfer) execute(client secrethub.ClientInterface, plan *transferPlan) error {
+	for _, dir := range plan.dirs {
+		_, err := client.Dirs().Create(dir)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, secret := range plan.secrets {
+		versions, err := t.versions(client, secret.src)
+		if err != nil {
+			return err
+		}
+
+		for _, version := range versions {
+			_, err = client.Secrets().Write(secret.dst, version.Data)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err := copyAnnotations(client, plan.secrets)
+	if err != nil {
+		return err
+	}
+
+	if !t.move {
+		return nil
+	}
+
+	// The annotations of a directory are removed together with the directory.
+	if plan.isDir {
+		return client.Dirs().Delete(plan.src)
+	}
+	err = client.Secrets().Delete(plan.src)
+	if err != nil {
+		return err
+	}
+	return removeAnnotations(client, plan.src)
+}
+
+// versions returns the versions of the secret to copy, oldest first.
+func (t *secretTransfer) versions(client secrethub.ClientInterface, path string) ([]*api.SecretVersion, error) {
+	if !t.replayAll() {
+		version, err := client.Secrets().Versions().GetWithData(path)
+		if err != nil {
+			return nil, err
+		}
+		return []*api.SecretVersion{version}, nil
+	}
+
+	versions, err := client.Secrets().Versions().ListWithData(path)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Version < versions[j].Version
+	})
+	return versions, nil
+}
+
+// printPlan prints the changes of the plan.
+func (t *secretTransfer) printPlan(plan *transferPlan) {
+	verb := "copy"
+	if t.move {
+		verb = "move"
+	}
+
+	for _, dir := range plan.dirs {
+		fmt.Fprintf(t.io.Output(), "Would create directory %s\n", dir)
+	}
+	for _, secret := range plan.secrets {
+		fmt.Fprintf(t.io.Output(), "Would %s %s to %s (%s)\n", verb, secret.src, secret.dst, pluralize("version", "versions", secret.versions))
+	}
+	if t.move {
+		fmt.Fprintf(t.io.Output(), "Would remove %s\n", plan.src)
+	}
+}
+
+// printACLDiff prints the accounts whose access to the destination
+// differs from their access to the source.
+func (t *secretTransfer) printACLDiff(client secrethub.ClientInterface, plan *transferPlan) error {
+	srcLevels, err := accessLevelsByAccount(client, plan.srcDir)
+	if err != nil {
+		return err
+	}
+
+	dstLevels, err := accessLevelsByAccount(client, plan.dstDir)
+	if err != nil {
+		return err
+	}
+
+	accounts := make([]string, 0, len(srcLevels)+len(dstLevels))
+	for account, permission := range srcLevels {
+		if dstLevels[account] != permission {
+			accounts = append(accounts, account)
+		}
+	}
+	for account := range dstLevels {
+		_, ok := srcLevels[account]
+		if !ok {
+			accounts = append(accounts, account)
+		}
+	}
+	sort.Strings(accounts)
+
+	if len(accounts) == 0 {
+		fmt.Fprintln(t.io.Output(), "All accounts have the same access to the destination as to the source.")
+		return nil
+	}
+
+	fmt.Fprintln(t.io.Output(), "The following accounts have different access to the destination than to the source:")
+	tw := tabwriter.NewWriter(t.io.Output(), 0, 4, 4, ' ', 0)
+	fmt.Fprintf(tw, "%s\t%s\t%s\n", "ACCOUNT", "SOURCE", "DESTINATION")
+	for _, account := range accounts {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", account, srcLevels[account], dstLevels[account])
+	}
+	err = tw.Flush()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(t.io.Output())
+	return nil
+}
+
+// accessLevelsByAccount returns the permission of every account on the directory.
+// When the directory does not exist yet, the levels of the closest existing parent
+// are returned, as a new directory inherits those.
+func accessLevelsByAccount(client secrethub.ClientInterface, dir string) (map[string]api.Permission, error) {
+	levels, err := client.AccessRules().ListLevels(dir)
+	for api.IsErrNotFound(err) && secretpath.Count(dir) > 2 {
+		dir = secretpath.Parent(dir)
+		levels, err = client.AccessRules().ListLevels(dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := make(map[string]api.Permission, len(levels))
+	for _, level := range levels {
+		permissions[string(level.Account.Name)] = level.Permission
+	}
+	return permissions, nil
+}
@@ -0,0 +1,414 @@
+package secrethub
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/api/uuid"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestSecretTransfer_Run(t *testing.T) {
+	rootID := uuid.New()
+	subID := uuid.New()
+	tree := &api.Tree{
+		ParentPath: "namespace/repo",
+		RootDir:    &api.Dir{DirID: rootID, Name: "dir"},
+		Dirs: map[uuid.UUID]*api.Dir{
+			rootID: {DirID: rootID, Name: "dir"},
+			subID:  {DirID: subID, ParentID: &rootID, Name: "sub"},
+		},
+		Secrets: map[uuid.UUID]*api.Secret{
+			uuid.New(): {DirID: rootID, Name: "a", VersionCount: 2},
+			uuid.New(): {DirID: subID, Name: "b", VersionCount: 1},
+			uuid.New(): {DirID: rootID, Name: ".annotations", VersionCount: 1},
+		},
+	}
+
+	sameLevels := "All accounts have the same access to the destination as to the source.\n"
+	otherLevels := "The following accounts have different access to the destination than to the source:\n" +
+		"ACCOUNT    SOURCE    DESTINATION\n" +
+		"dev2       write     none\n" +
+		"ops        none      admin\n" +
+		"\n"
+
+	movePrompt := "[WARNING] This permanently removes %s once it has been copied to %s. Do you want to continue? [y/N]: "
+
+	cases := map[string]struct {
+		transfer        secretTransfer
+		in              string
+		askErr          error
+		annotations     map[string]string
+		expectedCreated []string
+		expectedWritten map[string][]string
+		expectedDeleted []string
+		expectedOut     string
+		expectedPrompt  string
+		expectedErr     error
+	}{
+		"copy secret": {
+			transfer: secretTransfer{
+				src: "namespace/repo/secret",
+				dst: "namespace/other/secret",
+			},
+			expectedWritten: map[string][]string{
+				"namespace/other/secret": {"s1"},
+			},
+			expectedOut: otherLevels + "Copy complete! 1 secret transferred to namespace/other/secret.\n",
+		},
+		"copy secret into directory": {
+			transfer: secretTransfer{
+				src: "namespace/repo/secret",
+				dst: "namespace/repo/dir",
+			},
+			expectedWritten: map[string][]string{
+				"namespace/repo/dir/secret": {"s1"},
+			},
+			expectedOut: sameLevels + "Copy complete! 1 secret transferred to namespace/repo/dir.\n",
+		},
+		"copy directory with all versions": {
+			transfer: secretTransfer{
+				src:         "namespace/repo/dir",
+				dst:         "namespace/other/copy",
+				recursive:   true,
+				allVersions: true,
+			},
+			expectedCreated: []string{"namespace/other/copy", "namespace/other/copy/sub"},
+			expectedWritten: map[string][]string{
+				"namespace/other/copy/a":     {"a1", "a2"},
+				"namespace/other/copy/sub/b": {"b1"},
+			},
+			expectedOut: otherLevels + "Copy complete! 2 secrets transferred to namespace/other/copy.\n",
+		},
+		"copy directory into existing directory": {
+			transfer: secretTransfer{
+				src:       "namespace/repo/dir",
+				dst:       "namespace/other/existing",
+				recursive: true,
+				force:     true,
+			},
+			expectedCreated: []string{"namespace/other/existing/dir/sub"},
+			expectedWritten: map[string][]string{
+				"namespace/other/existing/dir/a":     {"a2"},
+				"namespace/other/existing/dir/sub/b": {"b1"},
+			},
+			expectedOut: otherLevels + "Copy complete! 2 secrets transferred to namespace/other/existing.\n",
+		},
+		"copy directory without recursive": {
+			transfer: secretTransfer{
+				src: "namespace/repo/dir",
+				dst: "namespace/other/copy",
+			},
+			expectedErr: ErrCannotCopyDir,
+		},
+		"copy directory into itself": {
+			transfer: secretTransfer{
+				src:       "namespace/repo/dir",
+				dst:       "namespace/repo/dir/sub",
+				recursive: true,
+			},
+			expectedErr: ErrCannotCopyIntoItself("namespace/repo/dir"),
+		},
+		"destination exists": {
+			transfer: secretTransfer{
+				src: "namespace/repo/dir/a",
+				dst: "namespace/other/existing/dir/a",
+			},
+			expectedErr: ErrDestinationExists("namespace/other/existing/dir/a"),
+		},
+		"destination exists force": {
+			transfer: secretTransfer{
+				src:   "namespace/repo/dir/a",
+				dst:   "namespace/other/existing/dir/a",
+				force: true,
+			},
+			expectedWritten: map[string][]string{
+				"namespace/other/existing/dir/a": {"a2"},
+			},
+			expectedOut: otherLevels + "Copy complete! 1 secret transferred to namespace/other/existing/dir/a.\n",
+		},
+		"source not found": {
+			transfer: secretTransfer{
+				src: "namespace/repo/unknown",
+				dst: "namespace/other/unknown",
+			},
+			expectedErr: ErrResourceNotFound("namespace/repo/unknown"),
+		},
+		"move secret": {
+			transfer: secretTransfer{
+				src:  "namespace/repo/secret",
+				dst:  "namespace/repo/renamed",
+				move: true,
+			},
+			in: "y",
+			expectedWritten: map[string][]string{
+				"namespace/repo/renamed": {"s1"},
+			},
+			expectedDeleted: []string{"namespace/repo/secret"},
+			expectedOut:     sameLevels + "Move complete! 1 secret transferred to namespace/repo/renamed.\n",
+			expectedPrompt:  fmt.Sprintf(movePrompt, "namespace/repo/secret", "namespace/repo/renamed"),
+		},
+		"copy secret with annotations": {
+			transfer: secretTransfer{
+				src: "namespace/repo/secret",
+				dst: "namespace/other/secret",
+			},
+			annotations: map[string]string{
+				"namespace/repo/.annotations":  "secret:\n  expires: \"2027-01-01\"\n",
+				"namespace/other/.annotations": "other:\n  owner: ops\n",
+			},
+			expectedWritten: map[string][]string{
+				"namespace/other/secret":       {"s1"},
+				"namespace/other/.annotations": {"other:\n  owner: ops\nsecret:\n  expires: \"2027-01-01\"\n"},
+			},
+			expectedOut: otherLevels + "Copy complete! 1 secret transferred to namespace/other/secret.\n",
+		},
+		"copy directory with annotations": {
+			transfer: secretTransfer{
+				src:       "namespace/repo/dir",
+				dst:       "namespace/other/copy",
+				recursive: true,
+			},
+			annotations: map[string]string{
+				"namespace/repo/dir/.annotations": "a:\n  owner: dev1\n",
+			},
+			expectedCreated: []string{"namespace/other/copy", "namespace/other/copy/sub"},
+			expectedWritten: map[string][]string{
+				"namespace/other/copy/a":            {"a2"},
+				"namespace/other/copy/sub/b":        {"b1"},
+				"namespace/other/copy/.annotations": {"a:\n  owner: dev1\n"},
+			},
+			expectedOut: otherLevels + "Copy complete! 2 secrets transferred to namespace/other/copy.\n",
+		},
+		"move secret with annotations": {
+			transfer: secretTransfer{
+				src:   "namespace/repo/secret",
+				dst:   "namespace/repo/renamed",
+				move:  true,
+				force: true,
+			},
+			annotations: map[string]string{
+				"namespace/repo/.annotations": "other:\n  owner: ops\nsecret:\n  expires: \"2027-01-01\"\n",
+			},
+			expectedWritten: map[string][]string{
+				"namespace/repo/renamed": {"s1"},
+				"namespace/repo/.annotations": {
+					"other:\n  owner: ops\nrenamed:\n  expires: \"2027-01-01\"\nsecret:\n  expires: \"2027-01-01\"\n",
+					"other:\n  owner: ops\nrenamed:\n  expires: \"2027-01-01\"\n",
+				},
+			},
+			expectedDeleted: []string{"namespace/repo/secret"},
+			expectedOut:     sameLevels + "Move complete! 1 secret transferred to namespace/repo/renamed.\n",
+		},
+		"move directory": {
+			transfer: secretTransfer{
+				src:       "namespace/repo/dir",
+				dst:       "namespace/other",
+				recursive: true,
+				move:      true,
+			},
+			in:              "y",
+			expectedCreated: []string{"namespace/other/dir", "namespace/other/dir/sub"},
+			expectedWritten: map[string][]string{
+				"namespace/other/dir/a":     {"a1", "a2"},
+				"namespace/other/dir/sub/b": {"b1"},
+			},
+			expectedDeleted: []string{"namespace/repo/dir"},
+			expectedOut:     otherLevels + "Move complete! 2 secrets transferred to namespace/other.\n",
+			expectedPrompt:  fmt.Sprintf(movePrompt, "namespace/repo/dir", "namespace/other"),
+		},
+		"move with force": {
+			transfer: secretTransfer{
+				src:   "namespace/repo/dir/a",
+				dst:   "namespace/repo/renamed",
+				move:  true,
+				force: true,
+			},
+			expectedWritten: map[string][]string{
+				"namespace/repo/renamed": {"a1", "a2"},
+			},
+			expectedDeleted: []string{"namespace/repo/dir/a"},
+			expectedOut:     sameLevels + "Move complete! 1 secret transferred to namespace/repo/renamed.\n",
+		},
+		"move aborted": {
+			transfer: secretTransfer{
+				src:  "namespace/repo/secret",
+				dst:  "namespace/repo/renamed",
+				move: true,
+			},
+			in:             "n",
+			expectedOut:    sameLevels + "Aborting.\n",
+			expectedPrompt: fmt.Sprintf(movePrompt, "namespace/repo/secret", "namespace/repo/renamed"),
+		},
+		"move cannot ask": {
+			transfer: secretTransfer{
+				src:  "namespace/repo/secret",
+				dst:  "namespace/repo/renamed",
+				move: true,
+			},
+			askErr:      ui.ErrCannotAsk,
+			expectedOut: sameLevels,
+			expectedErr: ErrCannotDoWithoutForce,
+		},
+		"move root directory": {
+			transfer: secretTransfer{
+				src:       "namespace/repo",
+				dst:       "namespace/other",
+				recursive: true,
+				move:      true,
+			},
+			expectedErr: ErrCannotMoveRootDir,
+		},
+		"dry run": {
+			transfer: secretTransfer{
+				src:       "namespace/repo/dir",
+				dst:       "namespace/other",
+				recursive: true,
+				dryRun:    true,
+				move:      true,
+			},
+			expectedOut: otherLevels +
+				"Would create directory namespace/other/dir\n" +
+				"Would create directory namespace/other/dir/sub\n" +
+				"Would move namespace/repo/dir/a to namespace/other/dir/a (2 versions)\n" +
+				"Would move namespace/repo/dir/sub/b to namespace/other/dir/sub/b (1 version)\n" +
+				"Would remove namespace/repo/dir\n",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dirs := map[string]bool{
+				"namespace/repo":                   true,
+				"namespace/repo/dir":               true,
+				"namespace/repo/dir/sub":           true,
+				"namespace/other":                  true,
+				"namespace/other/existing":         true,
+				"namespace/other/existing/dir":     true,
+				"namespace/other/existing/dir/sub": false,
+			}
+			secrets := map[string][]string{
+				"namespace/repo/secret":          {"s1"},
+				"namespace/repo/dir/a":           {"a1", "a2"},
+				"namespace/repo/dir/sub/b":       {"b1"},
+				"namespace/other/existing/dir/a": {"x1"},
+			}
+			for path, data := range tc.annotations {
+				secrets[path] = []string{data}
+			}
+
+			var created []string
+			var deleted []string
+			written := map[string][]string{}
+
+			io := fakeui.NewIO(t)
+			io.PromptIn.Buffer = bytes.NewBufferString(tc.in)
+			io.PromptErr = tc.askErr
+			tc.transfer.io = io
+			tc.transfer.newClient = func() (secrethub.ClientInterface, error) {
+				return fakeclient.Client{
+					DirService: &fakeclient.DirService{
+						GetTreeFunc: func(path string, depth int, ancestors bool) (*api.Tree, error) {
+							switch path {
+							case "namespace/repo/dir":
+								return tree, nil
+							case "namespace/repo":
+								return &api.Tree{}, nil
+							}
+							return nil, api.ErrDirNotFound
+						},
+						ExistsFunc: func(path string) (bool, error) {
+							return dirs[path], nil
+						},
+						CreateFunc: func(path string) (*api.Dir, error) {
+							created = append(created, path)
+							return &api.Dir{}, nil
+						},
+						DeleteFunc: func(path string) error {
+							deleted = append(deleted, path)
+							return nil
+						},
+					},
+					SecretService: &fakeclient.SecretService{
+						GetFunc: func(path string) (*api.Secret, error) {
+							versions, ok := secrets[path]
+							if !ok {
+								return nil, api.ErrSecretNotFound
+							}
+							return &api.Secret{VersionCount: len(versions)}, nil
+						},
+						ExistsFunc: func(path string) (bool, error) {
+							_, ok := secrets[path]
+							return ok, nil
+						},
+						WriteFunc: func(path string, data []byte) (*api.SecretVersion, error) {
+							written[path] = append(written[path], string(data))
+							secrets[path] = append(secrets[path], string(data))
+							return &api.SecretVersion{}, nil
+						},
+						DeleteFunc: func(path string) error {
+							deleted = append(deleted, path)
+							return nil
+						},
+						VersionService: &fakeclient.SecretVersionService{
+							GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+								versions, ok := secrets[path]
+								if !ok {
+									return nil, api.ErrSecretNotFound
+								}
+								return &api.SecretVersion{Version: len(versions), Data: []byte(versions[len(versions)-1])}, nil
+							},
+							ListWithDataFunc: func(path string) ([]*api.SecretVersion, error) {
+								var res []*api.SecretVersion
+								for i, data := range secrets[path] {
+									// Prepend, so that the command has to sort the versions.
+									res = append([]*api.SecretVersion{{Version: i + 1, Data: []byte(data)}}, res...)
+								}
+								return res, nil
+							},
+						},
+					},
+					AccessRuleService: &fakeclient.AccessRuleService{
+						ListLevelsFunc: func(path string) ([]*api.AccessLevel, error) {
+							if !strings.HasPrefix(path, "namespace/other") {
+								return []*api.AccessLevel{
+									{Account: &api.Account{Name: "dev1"}, Permission: api.PermissionRead},
+									{Account: &api.Account{Name: "dev2"}, Permission: api.PermissionWrite},
+								}, nil
+							}
+							if path != "namespace/other" {
+								return nil, api.ErrDirNotFound
+							}
+							return []*api.AccessLevel{
+								{Account: &api.Account{Name: "dev1"}, Permission: api.PermissionRead},
+								{Account: &api.Account{Name: "ops"}, Permission: api.PermissionAdmin},
+							}, nil
+						},
+					},
+				}, nil
+			}
+
+			err := tc.transfer.run()
+
+			assert.Equal(t, err, tc.expectedErr)
+			sort.Strings(created)
+			assert.Equal(t, created, tc.expectedCreated)
+			if tc.expectedWritten == nil {
+				tc.expectedWritten = map[string][]string{}
+			}
+			assert.Equal(t, written, tc.expectedWritten)
+			assert.Equal(t, deleted, tc.expectedDeleted)
+			assert.Equal(t, io.Out.String(), tc.expectedOut)
+			assert.Equal(t, io.PromptOut.String(), tc.expectedPrompt)
+		})
+	}
+}
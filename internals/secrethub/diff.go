@@ -0,0 +1,186 @@
+package secrethub
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+)
+
+// maskedLine replaces the contents of every line when values are not revealed.
+const maskedLine = "********"
+
+// DiffCommand compares two versions of a secret.
+type DiffCommand struct {
+	io        ui.IO
+	first     api.SecretPath
+	second    api.SecretPath
+	reveal    bool
+	brief     bool
+	newClient newClientFunc
+}
+
+// NewDiffCommand creates a new DiffCommand.
+func NewDiffCommand(io ui.IO, newClient newClientFunc) *DiffCommand {
+	return &DiffCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *DiffCommand) Register(r cli.Registerer) {
+	clause := r.Command("diff", "Show the differences between two secret versions.")
+	clause.HelpLong("The values are compared line by line. By default, the contents of the lines are masked, " +
+		"so only the lines that were added or removed are shown. When no version is given, the latest version is used.")
+	clause.Flags().BoolVar(&cmd.reveal, "reveal", false, "Show the values of the lines instead of masking them.")
+	clause.Flags().BoolVarP(&cmd.brief, "brief", "q", false, "Only report whether the versions differ.")
+
+	clause.BindAction(cmd.Run)
+	clause.BindArguments([]cli.Argument{
+		{Value: &cmd.first, Name: "secret-path", Required: true, Placeholder: secretPathOptionalVersionPlaceHolder, Description: "The path to the first secret version."},
+		{Value: &cmd.second, Name: "other-secret-path", Required: true, Placeholder: secretPathOptionalVersionPlaceHolder, Description: "The path to the second secret version."},
+	})
+}
+
+// Run prints the differences between the two secret versions.
+func (cmd *DiffCommand) Run() error {
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	first, err := client.Secrets().Versions().GetWithData(cmd.first.Value())
+	if err != nil {
+		return err
+	}
+
+	second, err := client.Secrets().Versions().GetWithData(cmd.second.Value())
+	if err != nil {
+		return err
+	}
+
+	firstName := versionedPath(cmd.first, first.Version)
+	secondName := versionedPath(cmd.second, second.Version)
+
+	if string(first.Data) == string(second.Data) {
+		fmt.Fprintf(cmd.io.Output(), "%s and %s are identical.\n", firstName, secondName)
+		return nil
+	}
+
+	if cmd.brief {
+		fmt.Fprintf(cmd.io.Output(), "%s and %s differ.\n", firstName, secondName)
+		return nil
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "--- %s\n+++ %s\n", firstName, secondName)
+
+	removed := 0
+	added := 0
+	for _, line := range lineDiff(splitLines(first.Data), splitLines(second.Data)) {
+		text := line.text
+		if !cmd.reveal {
+			text = maskedLine
+		}
+
+		switch line.op {
+		case diffDelete:
+			removed++
+			fmt.Fprintf(cmd.io.Output(), "- %s\n", text)
+		case diffInsert:
+			added++
+			fmt.Fprintf(cmd.io.Output(), "+ %s\n", text)
+		default:
+			fmt.Fprintf(cmd.io.Output(), "  %s\n", text)
+		}
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "\n%s removed, %s added.\n", pluralize("line", "lines", removed), pluralize("line", "lines", added))
+	if !cmd.reveal {
+		fmt.Fprintln(cmd.io.Output(), "The values are masked. Use --reveal to show them.")
+	}
+
+	return nil
+}
+
+// versionedPath returns the path of the secret with the given version.
+func versionedPath(path api.SecretPath, version int) string {
+	return fmt.Sprintf("%s:%d", withoutVersion(path), version)
+}
+
+// withoutVersion returns the path of the secret without its version.
+func withoutVersion(path api.SecretPath) string {
+	return strings.SplitN(path.Value(), ":", 2)[0]
+}
+
+// splitLines splits a value into lines, ignoring a trailing newline.
+func splitLines(value []byte) []string {
+	if len(value) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(value), "\n"), "\n")
+}
+
+type diffOp int
+
+const (
+	diffEqual diffOp = iota
+	diffDelete
+	diffInsert
+)
+
+// diffLine is a line in the difference between two values.
+type diffLine struct {
+	op   diffOp
+	text string
+}
+
+// lineDiff returns the lines to delete from a and to insert into it to get b,
+// based on the longest common subsequence of both. Secrets are small, so the
+// quadratic time and memory this takes are not a concern.
+func lineDiff(a, b []string) []diffLine {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var diff []diffLine
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			diff = append(diff, diffLine{op: diffEqual, text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, diffLine{op: diffDelete, text: a[i]})
+			i++
+		default:
+			diff = append(diff, diffLine{op: diffInsert, text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		diff = append(diff, diffLine{op: diffDelete, text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		diff = append(diff, diffLine{op: diffInsert, text: b[j]})
+	}
+
+	return diff
+}
@@ -0,0 +1,142 @@
+package secrethub
+
+import (
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestDiffCommand_Run(t *testing.T) {
+	versions := map[string]*api.SecretVersion{
+		"namespace/repo/config:1": {Version: 1, Data: []byte("host=db\nuser=app\npassword=old\n")},
+		"namespace/repo/config:2": {Version: 2, Data: []byte("host=db\nuser=app\npassword=new\nport=5432\n")},
+		"namespace/repo/config":   {Version: 2, Data: []byte("host=db\nuser=app\npassword=new\nport=5432\n")},
+	}
+
+	cases := map[string]struct {
+		cmd DiffCommand
+		out string
+		err error
+	}{
+		"masked": {
+			cmd: DiffCommand{
+				first:  "namespace/repo/config:1",
+				second: "namespace/repo/config:2",
+			},
+			out: "--- namespace/repo/config:1\n" +
+				"+++ namespace/repo/config:2\n" +
+				"  ********\n" +
+				"  ********\n" +
+				"- ********\n" +
+				"+ ********\n" +
+				"+ ********\n" +
+				"\n1 line removed, 2 lines added.\n" +
+				"The values are masked. Use --reveal to show them.\n",
+		},
+		"reveal": {
+			cmd: DiffCommand{
+				first:  "namespace/repo/config:1",
+				second: "namespace/repo/config",
+				reveal: true,
+			},
+			out: "--- namespace/repo/config:1\n" +
+				"+++ namespace/repo/config:2\n" +
+				"  host=db\n" +
+				"  user=app\n" +
+				"- password=old\n" +
+				"+ password=new\n" +
+				"+ port=5432\n" +
+				"\n1 line removed, 2 lines added.\n",
+		},
+		"brief": {
+			cmd: DiffCommand{
+				first:  "namespace/repo/config:1",
+				second: "namespace/repo/config:2",
+				brief:  true,
+			},
+			out: "namespace/repo/config:1 and namespace/repo/config:2 differ.\n",
+		},
+		"identical": {
+			cmd: DiffCommand{
+				first:  "namespace/repo/config:2",
+				second: "namespace/repo/config",
+			},
+			out: "namespace/repo/config:2 and namespace/repo/config:2 are identical.\n",
+		},
+		"not found": {
+			cmd: DiffCommand{
+				first:  "namespace/repo/config:1",
+				second: "namespace/repo/config:3",
+			},
+			err: api.ErrSecretVersionNotFound,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			io := fakeui.NewIO(t)
+			tc.cmd.io = io
+			tc.cmd.newClient = func() (secrethub.ClientInterface, error) {
+				return fakeclient.Client{
+					SecretService: &fakeclient.SecretService{
+						VersionService: &fakeclient.SecretVersionService{
+							GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+								version, ok := versions[path]
+								if !ok {
+									return nil, api.ErrSecretVersionNotFound
+								}
+								return version, nil
+							},
+						},
+					},
+				}, nil
+			}
+
+			err := tc.cmd.Run()
+
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, io.Out.String(), tc.out)
+		})
+	}
+}
+
+func TestLineDiff(t *testing.T) {
+	cases := map[string]struct {
+		a        []string
+		b        []string
+		expected []diffLine
+	}{
+		"both empty": {},
+		"all added": {
+			b:        []string{"a", "b"},
+			expected: []diffLine{{diffInsert, "a"}, {diffInsert, "b"}},
+		},
+		"all removed": {
+			a:        []string{"a", "b"},
+			expected: []diffLine{{diffDelete, "a"}, {diffDelete, "b"}},
+		},
+		"changed in the middle": {
+			a:        []string{"a", "b", "c"},
+			b:        []string{"a", "x", "c"},
+			expected: []diffLine{{diffEqual, "a"}, {diffDelete, "b"}, {diffInsert, "x"}, {diffEqual, "c"}},
+		},
+		"moved line": {
+			a:        []string{"a", "b", "c"},
+			b:        []string{"b", "c", "a"},
+			expected: []diffLine{{diffDelete, "a"}, {diffEqual, "b"}, {diffEqual, "c"}, {diffInsert, "a"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual := lineDiff(tc.a, tc.b)
+
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
@@ -21,8 +21,8 @@ func NewEnvCommand(io ui.IO, newClient newClientFunc) *EnvCommand {
 
 // Register registers the command and its sub-commands on the provided Registerer.
 func (cmd *EnvCommand) Register(r cli.Registerer) {
-	clause := r.Command("env", "[BETA] Manage environment variables.").Hidden()
-	clause.HelpLong("This command is hidden because it is still in beta. Future versions may break.")
+	clause := r.Command("env", "Manage environment variables.")
 	NewEnvReadCommand(cmd.io, cmd.newClient).Register(clause)
 	NewEnvListCommand(cmd.io, cmd.newClient).Register(clause)
+	NewEnvExportCommand(cmd.io, cmd.newClient).Register(clause)
 }
@@ -0,0 +1,296 @@
+package secrethub
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/filemode"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+// Errors
+var (
+	ErrUnknownExportFormat  = errMain.Code("unknown_export_format").ErrorPref("unknown export format: '%s' supported formats are dotenv, json, yaml, shell, systemd and docker")
+	ErrUnsupportedExportVar = errMain.Code("unsupported_export_value").ErrorPref("cannot export environment variable %s in the %s format: values containing newlines are not supported by this format")
+	ErrCannotSetFileMode    = errMain.Code("cannot_set_file_mode").ErrorPref("cannot set the file mode of %s: %s")
+)
+
+const (
+	exportFormatDotEnv  = "dotenv"
+	exportFormatJSON    = "json"
+	exportFormatYAML    = "yaml"
+	exportFormatShell   = "shell"
+	exportFormatSystemd = "systemd"
+	exportFormatDocker  = "docker"
+)
+
+// exportFormats are the supported formats of the env export command.
+var exportFormats = []string{
+	exportFormatDotEnv,
+	exportFormatJSON,
+	exportFormatYAML,
+	exportFormatShell,
+	exportFormatSystemd,
+	exportFormatDocker,
+}
+
+// EnvExportCommand is a command to export the environment sourced for `secrethub run` to a file.
+type EnvExportCommand struct {
+	io          ui.IO
+	newClient   newClientFunc
+	environment *environment
+	osEnv       []string
+	format      string
+	outFile     string
+	fileMode    filemode.FileMode
+	force       bool
+	all         bool
+}
+
+// NewEnvExportCommand creates a new EnvExportCommand.
+func NewEnvExportCommand(io ui.IO, newClient newClientFunc) *EnvExportCommand {
+	return &EnvExportCommand{
+		io:          io,
+		newClient:   newClient,
+		environment: newEnvironment(io, newClient),
+		osEnv:       os.Environ(),
+		fileMode:    filemode.New(0600),
+	}
+}
+
+// Register adds a CommandClause and it's args and flags to a Registerer.
+func (cmd *EnvExportCommand) Register(r cli.Registerer) {
+	clause := r.Command("export", "Export the environment that `secrethub run` would source, with all secrets resolved.")
+	clause.HelpLong("Export the environment that `secrethub run` would source, with all secrets resolved, " +
+		"so it can be consumed by tools that cannot be wrapped by `secrethub run`. " +
+		"By default, only the variables that are sourced by SecretHub are exported. Variables inherited from the current process are left out, unless --all is set.\n" +
+		"\n" +
+		"Supported formats are:\n" +
+		"  dotenv   KEY=\"value\" lines with $ escaped, readable by most .env loaders\n" +
+		"  json     a single JSON object\n" +
+		"  yaml     a single YAML mapping\n" +
+		"  shell    export KEY='value' lines, to be sourced by a POSIX shell\n" +
+		"  systemd  a file for the EnvironmentFile= option of systemd units\n" +
+		"  docker   a file for the --env-file option of docker run")
+	clause.Flags().StringVar(&cmd.format, "format", exportFormatDotEnv, "The format to export the environment in. The options are dotenv, json, yaml, shell, systemd and docker.")
+	_ = clause.Cmd.RegisterFlagCompletionFunc("format", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return exportFormats, cobra.ShellCompDirectiveDefault
+	})
+	clause.Flags().StringVarP(&cmd.outFile, "out-file", "o", "", "Write the exported environment to a file instead of stdout.")
+	clause.Flags().Var(&cmd.fileMode, "file-mode", "Set filemode for the output file. It is ignored without the --out-file flag.")
+	clause.Flags().BoolVar(&cmd.all, "all", false, "Also export the variables inherited from the current process environment.")
+	clause.Flags().BoolVarP(&cmd.force, "force", "f", false, "Overwrite the output file if it already exists, without prompting for confirmation. This flag is ignored if no --out-file is supplied.")
+
+	cmd.environment.register(clause)
+
+	clause.BindAction(cmd.Run)
+	clause.BindArguments(nil)
+}
+
+// Run executes the command.
+func (cmd *EnvExportCommand) Run() error {
+	if !isExportFormat(cmd.format) {
+		return ErrUnknownExportFormat(cmd.format)
+	}
+
+	vars, err := cmd.resolve()
+	if err != nil {
+		return err
+	}
+
+	out, err := formatEnv(vars, cmd.format)
+	if err != nil {
+		return err
+	}
+
+	if cmd.outFile == "" {
+		_, err = cmd.io.Output().Write(out)
+		return err
+	}
+
+	_, err = os.Stat(cmd.outFile)
+	if err == nil && !cmd.force {
+		if cmd.io.IsOutputPiped() {
+			return ErrFileAlreadyExists
+		}
+
+		confirmed, err := ui.AskYesNo(
+			cmd.io,
+			fmt.Sprintf(
+				"File %s already exists, overwrite it?",
+				cmd.outFile,
+			),
+			ui.DefaultNo,
+		)
+		if err != nil {
+			return err
+		}
+
+		if !confirmed {
+			fmt.Fprintln(cmd.io.Output(), "Aborting.")
+			return nil
+		}
+	}
+
+	err = writeEnvFile(cmd.outFile, out, cmd.fileMode.FileMode())
+	if err != nil {
+		return err
+	}
+
+	absPath, err := filepath.Abs(cmd.outFile)
+	if err != nil {
+		return ErrCannotWrite(cmd.outFile, err)
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "%s\n", absPath)
+
+	return nil
+}
+
+// writeEnvFile writes the exported environment to a file with the given permissions.
+// The permissions of an existing file are set before anything is written to it,
+// so the secrets are never readable by others.
+func writeEnvFile(path string, data []byte, perm os.FileMode) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return ErrCannotWrite(path, err)
+	}
+	defer file.Close()
+
+	// OpenFile does not change the permissions of existing files.
+	err = file.Chmod(perm)
+	if err != nil {
+		return ErrCannotSetFileMode(path, err)
+	}
+
+	_, err = file.Write(data)
+	if err != nil {
+		return ErrCannotWrite(path, err)
+	}
+
+	err = file.Close()
+	if err != nil {
+		return ErrCannotWrite(path, err)
+	}
+	return nil
+}
+
+// resolve returns the environment with all secrets resolved.
+// Unless all is set, variables that are inherited unchanged from the process environment are left out.
+func (cmd *EnvExportCommand) resolve() (map[string]string, error) {
+	env, err := cmd.environment.env()
+	if err != nil {
+		return nil, err
+	}
+
+	osEnv, _ := parseKeyValueStringsToMap(cmd.osEnv)
+	secretReader := newBufferedSecretReader(newSecretReader(cmd.newClient))
+
+	vars := make(map[string]string, len(env))
+	for key, value := range env {
+		resolved, err := value.resolve(secretReader)
+		if err != nil {
+			return nil, err
+		}
+
+		inherited, isOSVar := osEnv[key]
+		if !cmd.all && !value.containsSecret() && isOSVar && inherited == resolved {
+			continue
+		}
+		vars[key] = resolved
+	}
+
+	return vars, nil
+}
+
+// formatEnv encodes the environment variables in the given format.
+// The variables are sorted by key, so the output is stable.
+func formatEnv(vars map[string]string, format string) ([]byte, error) {
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	switch format {
+	case exportFormatJSON:
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "    ")
+		err := encoder.Encode(vars)
+		if err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case exportFormatYAML:
+		out := make(yaml.MapSlice, len(keys))
+		for i, key := range keys {
+			out[i] = yaml.MapItem{Key: key, Value: vars[key]}
+		}
+		return yaml.Marshal(out)
+	case exportFormatDotEnv, exportFormatShell, exportFormatSystemd, exportFormatDocker:
+		var buf bytes.Buffer
+		for _, key := range keys {
+			line, err := formatEnvLine(key, vars[key], format)
+			if err != nil {
+				return nil, err
+			}
+			buf.WriteString(line)
+			buf.WriteByte('\n')
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, ErrUnknownExportFormat(format)
+	}
+}
+
+// formatEnvLine encodes a single environment variable in one of the line-based formats.
+func formatEnvLine(key, value, format string) (string, error) {
+	switch format {
+	case exportFormatShell:
+		return "export " + key + "=" + shellQuote(value), nil
+	case exportFormatDotEnv:
+		// Dollar signs are escaped, as many loaders expand variables in double-quoted values.
+		replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "\n", `\n`, "\r", `\r`)
+		return key + "=\"" + replacer.Replace(value) + "\"", nil
+	case exportFormatSystemd:
+		if strings.ContainsAny(value, "\r\n") {
+			return "", ErrUnsupportedExportVar(key, format)
+		}
+		replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+		return key + "=\"" + replacer.Replace(value) + "\"", nil
+	case exportFormatDocker:
+		// Docker reads values literally, quotes included.
+		if strings.ContainsAny(value, "\r\n") {
+			return "", ErrUnsupportedExportVar(key, format)
+		}
+		return key + "=" + value, nil
+	default:
+		return "", ErrUnknownExportFormat(format)
+	}
+}
+
+// isExportFormat returns whether the given format is supported by the env export command.
+func isExportFormat(format string) bool {
+	for _, f := range exportFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// shellQuote wraps the value in single quotes, so no characters are interpreted by the shell.
+func shellQuote(value string) string {
+	return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+}
@@ -0,0 +1,225 @@
+package secrethub
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/filemode"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestEnvExportCommand_Run(t *testing.T) {
+	newClient := func() (secrethub.ClientInterface, error) {
+		return fakeclient.Client{
+			SecretService: &fakeclient.SecretService{
+				VersionService: &fakeclient.SecretVersionService{
+					GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+						return &api.SecretVersion{Data: []byte("it's a secret")}, nil
+					},
+				},
+			},
+		}, nil
+	}
+
+	osStatNotExist := func(_ string) (info os.FileInfo, err error) {
+		return nil, os.ErrNotExist
+	}
+
+	cases := map[string]struct {
+		format string
+		all    bool
+		out    string
+		err    error
+	}{
+		"dotenv": {
+			format: exportFormatDotEnv,
+			out:    "DB_PASSWORD=\"it's a secret\"\n",
+		},
+		"shell": {
+			format: exportFormatShell,
+			out:    "export DB_PASSWORD='it'\\''s a secret'\n",
+		},
+		"json": {
+			format: exportFormatJSON,
+			out:    "{\n    \"DB_PASSWORD\": \"it's a secret\"\n}\n",
+		},
+		"all": {
+			format: exportFormatDocker,
+			all:    true,
+			out:    "DB_PASSWORD=it's a secret\nHOME=/home/user\n",
+		},
+		"unknown format": {
+			format: "xml",
+			err:    ErrUnknownExportFormat("xml"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			io := fakeui.NewIO(t)
+			osEnv := []string{"HOME=/home/user"}
+
+			cmd := EnvExportCommand{
+				io:        io,
+				newClient: newClient,
+				osEnv:     osEnv,
+				format:    tc.format,
+				all:       tc.all,
+				environment: &environment{
+					io:     io,
+					osEnv:  osEnv,
+					osStat: osStatNotExist,
+					envar: map[string]string{
+						"DB_PASSWORD": "company/app/db/password",
+					},
+				},
+			}
+
+			err := cmd.Run()
+
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, io.Out.String(), tc.out)
+		})
+	}
+}
+
+func TestEnvExportCommand_Run_outFile(t *testing.T) {
+	cases := map[string]struct {
+		existing bool
+		missing  bool
+	}{
+		"new file": {},
+		"existing file": {
+			existing: true,
+		},
+		"missing directory": {
+			missing: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			dir, cleanup := testdata.tempDir(t)
+			defer cleanup()
+			outFile := filepath.Join(dir, ".env")
+			if tc.missing {
+				outFile = filepath.Join(dir, "missing", ".env")
+			}
+			if tc.existing {
+				err := ioutil.WriteFile(outFile, []byte("OLD=\"value\"\nLONGER=\"than the new file\"\n"), 0644)
+				assert.OK(t, err)
+				err = os.Chmod(outFile, 0644)
+				assert.OK(t, err)
+			}
+
+			io := fakeui.NewIO(t)
+			cmd := EnvExportCommand{
+				io: io,
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						SecretService: &fakeclient.SecretService{
+							VersionService: &fakeclient.SecretVersionService{
+								GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+									return &api.SecretVersion{Data: []byte("secret")}, nil
+								},
+							},
+						},
+					}, nil
+				},
+				format:   exportFormatDotEnv,
+				outFile:  outFile,
+				fileMode: filemode.New(0600),
+				force:    true,
+				environment: &environment{
+					io: io,
+					osStat: func(_ string) (os.FileInfo, error) {
+						return nil, os.ErrNotExist
+					},
+					envar: map[string]string{
+						"DB_PASSWORD": "company/app/db/password",
+					},
+				},
+			}
+
+			// Act
+			err := cmd.Run()
+
+			// Assert
+			if tc.missing {
+				_, openErr := os.OpenFile(outFile, os.O_WRONLY|os.O_CREATE, 0600)
+				assert.Equal(t, err, ErrCannotWrite(outFile, openErr))
+				return
+			}
+			assert.OK(t, err)
+			info, err := os.Stat(outFile)
+			assert.OK(t, err)
+			assert.Equal(t, info.Mode().Perm(), os.FileMode(0600))
+			content, err := ioutil.ReadFile(outFile)
+			assert.OK(t, err)
+			assert.Equal(t, string(content), "DB_PASSWORD=\"secret\"\n")
+		})
+	}
+}
+
+func TestFormatEnv(t *testing.T) {
+	vars := map[string]string{
+		"B": "multi\nline \"value\"",
+		"A": "plain",
+	}
+
+	cases := map[string]struct {
+		format   string
+		vars     map[string]string
+		expected string
+		err      error
+	}{
+		"dotenv": {
+			format:   exportFormatDotEnv,
+			expected: "A=\"plain\"\nB=\"multi\\nline \\\"value\\\"\"\n",
+		},
+		"dotenv dollar": {
+			format:   exportFormatDotEnv,
+			vars:     map[string]string{"A": "pa$$word ${HOME}"},
+			expected: "A=\"pa\\$\\$word \\${HOME}\"\n",
+		},
+		"yaml": {
+			format:   exportFormatYAML,
+			expected: "A: plain\nB: |-\n  multi\n  line \"value\"\n",
+		},
+		"shell": {
+			format:   exportFormatShell,
+			expected: "export A='plain'\nexport B='multi\nline \"value\"'\n",
+		},
+		"systemd": {
+			format: exportFormatSystemd,
+			err:    ErrUnsupportedExportVar("B", exportFormatSystemd),
+		},
+		"docker": {
+			format: exportFormatDocker,
+			err:    ErrUnsupportedExportVar("B", exportFormatDocker),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if tc.vars == nil {
+				tc.vars = vars
+			}
+
+			actual, err := formatEnv(tc.vars, tc.format)
+
+			assert.Equal(t, err, tc.err)
+			if tc.err == nil {
+				assert.Equal(t, string(actual), tc.expected)
+			}
+		})
+	}
+}
@@ -23,8 +23,7 @@ func NewEnvListCommand(io ui.IO, newClient newClientFunc) *EnvListCommand {
 
 // Register adds a CommandClause and it's args and flags to a Registerer.
 func (cmd *EnvListCommand) Register(r cli.Registerer) {
-	clause := r.Command("ls", "[BETA] List environment variable names that will be populated with secrets.")
-	clause.HelpLong("This command is hidden because it is still in beta. Future versions may break.")
+	clause := r.Command("ls", "List environment variable names that will be populated with secrets.")
 	clause.Alias("list")
 
 	cmd.environment.register(clause)
@@ -46,6 +46,7 @@ type environment struct {
 	dontPromptMissingTemplateVar bool
 	secretsDir                   string
 	secretsEnvDir                string
+	stage                        string
 }
 
 func newEnvironment(io ui.IO, newClient newClientFunc) *environment {
@@ -72,6 +73,7 @@ func (env *environment) register(clause *cli.CommandClause) {
 	})
 	clause.Flags().BoolVar(&env.dontPromptMissingTemplateVar, "no-prompt", false, "Do not prompt when a template variable is missing and return an error instead.")
 	clause.Flags().StringVar(&env.secretsDir, "secrets-dir", "", "Recursively include all secrets from a directory. Environment variable names are derived from the path of the secret: `/` are replaced with `_` and the name is uppercased.")
+	clause.Flags().StringVar(&env.stage, "stage", "", "The name of the stage to use from an environment file that declares stages, e.g. dev, staging or prod.")
 	clause.Flags().StringVar(&env.secretsEnvDir, "env", "default", "The name of the environment prepared by the set command.")
 	clause.Cmd.Flag("env").Hidden = true
 }
@@ -111,6 +113,10 @@ func (env *environment) env() (map[string]value, error) {
 		}
 	}
 
+	if env.envFile == "" && env.stage != "" {
+		return nil, ErrStageWithoutEnvFile(env.stage)
+	}
+
 	if env.envFile != "" {
 		templateVariableReader, err := newVariableReader(osEnvMap, env.templateVars)
 		if err != nil {
@@ -126,6 +132,11 @@ func (env *environment) env() (map[string]value, error) {
 			return nil, ErrCannotReadFile(env.envFile, err)
 		}
 
+		raw, err = selectStage(env.envFile, raw, env.stage)
+		if err != nil {
+			return nil, err
+		}
+
 		parser, err := getTemplateParser(raw, env.templateVersion)
 		if err != nil {
 			return nil, err
@@ -220,7 +231,11 @@ func (s *secretsDirEnv) env() (map[string]value, error) {
 	}
 
 	paths := make(map[string]string, tree.SecretCount())
-	for id := range tree.Secrets {
+	for id, secret := range tree.Secrets {
+		// The annotations and policy of a directory are not environment variables.
+		if isReservedSecretName(secret.Name) {
+			continue
+		}
 		secretPath, err := tree.AbsSecretPath(id)
 		if err != nil {
 			return nil, err
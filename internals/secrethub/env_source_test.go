@@ -17,6 +17,7 @@ func TestSecretsDirEnv(t *testing.T) {
 	subDirUUID := uuid.New()
 	secretUUID1 := uuid.New()
 	secretUUID2 := uuid.New()
+	secretUUID3 := uuid.New()
 
 	cases := map[string]struct {
 		newClient          newClientFunc
@@ -80,6 +81,41 @@ func TestSecretsDirEnv(t *testing.T) {
 			},
 			expectedValues: []string{"FOO_BAR"},
 		},
+		"reserved secrets": {
+			newClient: func() (secrethub.ClientInterface, error) {
+				return fakeclient.Client{
+					DirService: &fakeclient.DirService{
+						GetTreeFunc: func(path string, depth int, ancestors bool) (*api.Tree, error) {
+							return &api.Tree{
+								ParentPath: "namespace",
+								RootDir: &api.Dir{
+									DirID: rootDirUUID,
+									Name:  "repo",
+								},
+								Secrets: map[uuid.UUID]*api.Secret{
+									secretUUID1: {
+										SecretID: secretUUID1,
+										DirID:    rootDirUUID,
+										Name:     "foo",
+									},
+									secretUUID2: {
+										SecretID: secretUUID2,
+										DirID:    rootDirUUID,
+										Name:     ".annotations",
+									},
+									secretUUID3: {
+										SecretID: secretUUID3,
+										DirID:    rootDirUUID,
+										Name:     ".policy",
+									},
+								},
+							}, nil
+						},
+					},
+				}, nil
+			},
+			expectedValues: []string{"FOO"},
+		},
 		"name collision": {
 			newClient: func() (secrethub.ClientInterface, error) {
 				return fakeclient.Client{
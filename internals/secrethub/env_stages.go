@@ -0,0 +1,167 @@
+package secrethub
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Errors
+var (
+	ErrStageRequired       = errMain.Code("stage_required").ErrorPref("environment file %s declares stages, select one with --stage: %s")
+	ErrStageNotFound       = errMain.Code("stage_not_found").ErrorPref("stage %s is not declared in environment file %s, available stages are: %s")
+	ErrStagesNotDeclared   = errMain.Code("stages_not_declared").ErrorPref("cannot select stage %s: environment file %s does not declare any stages")
+	ErrStageCycle          = errMain.Code("stage_cycle").ErrorPref("stage %s extends itself through: %s")
+	ErrStageUndefinedVar   = errMain.Code("stage_undefined_var").ErrorPref("stage %s overrides variable %s, which is not defined in its parent stage %s")
+	ErrStageParentMissing  = errMain.Code("stage_parent_not_found").ErrorPref("stage %s extends stage %s, which is not declared")
+	ErrStageWithoutEnvFile = errMain.Code("stage_without_env_file").ErrorPref("cannot select stage %s: no environment file found, create a secrethub.env file or set one with --env-file")
+)
+
+// stagedEnvFile is the YAML representation of an environment file that declares named stages, e.g.:
+//
+//	stages:
+//	  dev:
+//	    vars:
+//	      DB_USER: app
+//	      DB_PASSWORD: "{{ company/app/dev/db_password }}"
+//	  prod:
+//	    extends: dev
+//	    vars:
+//	      DB_PASSWORD: "{{ company/app/prod/db_password }}"
+//
+// A stage that extends another stage inherits all of its variables and may only override them.
+type stagedEnvFile struct {
+	Stages map[string]envStage `yaml:"stages"`
+}
+
+// envStage is a single stage in an environment file.
+type envStage struct {
+	Extends string            `yaml:"extends"`
+	Vars    map[string]string `yaml:"vars"`
+}
+
+// parseStagedEnvFile returns the stages declared in the raw environment file.
+// When the file does not declare any stages, e.g. because it is a flat
+// `key=value` or `key: value` file, false is returned.
+func parseStagedEnvFile(raw []byte) (stagedEnvFile, bool) {
+	var file stagedEnvFile
+	err := yaml.Unmarshal(raw, &file)
+	if err != nil || len(file.Stages) == 0 {
+		return stagedEnvFile{}, false
+	}
+	return file, true
+}
+
+// selectStage returns the environment file with only the variables of the selected stage,
+// encoded as a flat YAML file, so it can be parsed like any other environment file.
+// Files that do not declare stages are returned as is when no stage is selected.
+func selectStage(path string, raw []byte, stage string) ([]byte, error) {
+	vars, isStaged, err := readStageVars(path, raw, stage)
+	if err != nil {
+		return nil, err
+	}
+	if !isStaged {
+		return raw, nil
+	}
+	return yaml.Marshal(vars)
+}
+
+// readEnvFileStage reads the environment file at the given path and returns the variables of the selected stage.
+func readEnvFileStage(readFile func(filename string) ([]byte, error), path string, stage string) (map[string]string, error) {
+	raw, err := readFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrStageWithoutEnvFile(stage)
+	} else if err != nil {
+		return nil, ErrCannotReadFile(path, err)
+	}
+
+	vars, _, err := readStageVars(path, raw, stage)
+	return vars, err
+}
+
+// readStageVars returns the variables of the selected stage, including the variables it inherits.
+// The values are returned as written in the file, so they can still contain templates.
+// The returned boolean is false when the file does not declare stages and no stage is selected.
+func readStageVars(path string, raw []byte, stage string) (map[string]string, bool, error) {
+	file, isStaged := parseStagedEnvFile(raw)
+	if !isStaged {
+		if stage != "" {
+			return nil, false, ErrStagesNotDeclared(stage, path)
+		}
+		return nil, false, nil
+	}
+
+	if stage == "" {
+		return nil, true, ErrStageRequired(path, strings.Join(file.stageNames(), ", "))
+	}
+
+	_, found := file.Stages[stage]
+	if !found {
+		return nil, true, ErrStageNotFound(stage, path, strings.Join(file.stageNames(), ", "))
+	}
+
+	vars, err := file.resolve(stage, nil)
+	if err != nil {
+		return nil, true, err
+	}
+	return vars, true, nil
+}
+
+// resolve returns the variables of the given stage, merged on top of those of its parents.
+// The chain of stages that is being resolved is passed to detect cycles.
+func (f stagedEnvFile) resolve(name string, chain []string) (map[string]string, error) {
+	for _, visited := range chain {
+		if visited == name {
+			return nil, ErrStageCycle(chain[0], strings.Join(append(chain, name), " -> "))
+		}
+	}
+	chain = append(chain, name)
+
+	stage := f.Stages[name]
+	if stage.Extends == "" {
+		vars := make(map[string]string, len(stage.Vars))
+		for key, value := range stage.Vars {
+			vars[key] = value
+		}
+		return vars, nil
+	}
+
+	_, found := f.Stages[stage.Extends]
+	if !found {
+		return nil, ErrStageParentMissing(name, stage.Extends)
+	}
+
+	vars, err := f.resolve(stage.Extends, chain)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sorted for a predictable error when multiple variables are undefined.
+	keys := make([]string, 0, len(stage.Vars))
+	for key := range stage.Vars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		_, defined := vars[key]
+		if !defined {
+			return nil, ErrStageUndefinedVar(name, key, stage.Extends)
+		}
+		vars[key] = stage.Vars[key]
+	}
+
+	return vars, nil
+}
+
+// stageNames returns the names of all declared stages in alphabetical order.
+func (f stagedEnvFile) stageNames() []string {
+	names := make([]string, 0, len(f.Stages))
+	for name := range f.Stages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
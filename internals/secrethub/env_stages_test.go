@@ -0,0 +1,111 @@
+package secrethub
+
+import (
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestReadStageVars(t *testing.T) {
+	const stagedFile = `
+stages:
+  dev:
+    vars:
+      DB_USER: app
+      DB_PASSWORD: "{{ company/app/dev/db_password }}"
+  staging:
+    extends: dev
+    vars:
+      DB_PASSWORD: "{{ company/app/staging/db_password }}"
+  prod:
+    extends: staging
+    vars:
+      DB_USER: prod-app
+  typo:
+    extends: dev
+    vars:
+      DB_PASWORD: foo
+  orphan:
+    extends: unknown
+  loop1:
+    extends: loop2
+  loop2:
+    extends: loop1
+`
+
+	cases := map[string]struct {
+		raw      string
+		stage    string
+		expected map[string]string
+		isStaged bool
+		err      error
+	}{
+		"base stage": {
+			raw:   stagedFile,
+			stage: "dev",
+			expected: map[string]string{
+				"DB_USER":     "app",
+				"DB_PASSWORD": "{{ company/app/dev/db_password }}",
+			},
+			isStaged: true,
+		},
+		"inherited overrides": {
+			raw:   stagedFile,
+			stage: "prod",
+			expected: map[string]string{
+				"DB_USER":     "prod-app",
+				"DB_PASSWORD": "{{ company/app/staging/db_password }}",
+			},
+			isStaged: true,
+		},
+		"override of undefined variable": {
+			raw:      stagedFile,
+			stage:    "typo",
+			isStaged: true,
+			err:      ErrStageUndefinedVar("typo", "DB_PASWORD", "dev"),
+		},
+		"unknown parent": {
+			raw:      stagedFile,
+			stage:    "orphan",
+			isStaged: true,
+			err:      ErrStageParentMissing("orphan", "unknown"),
+		},
+		"cycle": {
+			raw:      stagedFile,
+			stage:    "loop1",
+			isStaged: true,
+			err:      ErrStageCycle("loop1", "loop1 -> loop2 -> loop1"),
+		},
+		"stage not found": {
+			raw:      stagedFile,
+			stage:    "test",
+			isStaged: true,
+			err:      ErrStageNotFound("test", "secrethub.env", "dev, loop1, loop2, orphan, prod, staging, typo"),
+		},
+		"no stage selected": {
+			raw:      stagedFile,
+			isStaged: true,
+			err:      ErrStageRequired("secrethub.env", "dev, loop1, loop2, orphan, prod, staging, typo"),
+		},
+		"flat file": {
+			raw: "DB_USER=app\n",
+		},
+		"flat file with stage": {
+			raw:   "DB_USER: app\n",
+			stage: "dev",
+			err:   ErrStagesNotDeclared("dev", "secrethub.env"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, isStaged, err := readStageVars("secrethub.env", []byte(tc.raw), tc.stage)
+
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, isStaged, tc.isStaged)
+			if tc.err == nil {
+				assert.Equal(t, actual, tc.expected)
+			}
+		})
+	}
+}
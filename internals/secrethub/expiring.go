@@ -0,0 +1,204 @@
+package secrethub
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/policy"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secretpath"
+)
+
+// Errors
+var (
+	ErrInvalidWithin  = errMain.Code("invalid_within").ErrorPref("invalid duration %s: use a duration such as 30d or 12h")
+	ErrSecretsOverdue = errMain.Code("secrets_overdue").ErrorPref("%d secret(s) are overdue for rotation")
+)
+
+// ExpiringCommand reports the secrets of which the expires annotation is within a given period.
+type ExpiringCommand struct {
+	io        ui.IO
+	path      api.DirPath
+	within    string
+	newClient newClientFunc
+}
+
+// NewExpiringCommand creates a new ExpiringCommand.
+func NewExpiringCommand(io ui.IO, newClient newClientFunc) *ExpiringCommand {
+	return &ExpiringCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *ExpiringCommand) Register(r cli.Registerer) {
+	clause := r.Command("expiring", "Report the secrets that must be rotated soon.")
+	clause.HelpLong("The secrets of which the " + annotationExpires + " annotation is within the given period are listed, " +
+		"together with the secrets that are overdue. Set the annotation with e.g. " +
+		"secrethub annotate --set " + annotationExpires + "=2020-12-31 <secret-path>.\n\n" +
+		"Without a path, all repositories you have access to are checked. " +
+		"The command fails when any secret is overdue, so it can be used in scheduled jobs.")
+	clause.Flags().StringVar(&cmd.within, "within", "30d", "The period from now in which the secrets expire, e.g. 30d or 12h.")
+
+	clause.BindAction(cmd.Run)
+	clause.BindArguments([]cli.Argument{{Value: &cmd.path, Name: "dir-path", Required: false, Placeholder: optionalDirPathPlaceHolder, Description: "The path to the directory to check. Defaults to all your repositories."}})
+}
+
+// expiringSecret is a secret with an expires annotation.
+type expiringSecret struct {
+	path    string
+	expires time.Time
+}
+
+// Run prints the secrets that expire within the period.
+func (cmd *ExpiringCommand) Run() error {
+	within, err := policy.ParseDuration(cmd.within)
+	if err != nil || within < 0 {
+		return ErrInvalidWithin(cmd.within)
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	var dirPaths []string
+	if cmd.path != "" {
+		dirPaths = []string{cmd.path.Value()}
+	} else {
+		repos, err := client.Repos().ListMine()
+		if err != nil {
+			return err
+		}
+		for _, repo := range repos {
+			dirPaths = append(dirPaths, repo.Path().Value())
+		}
+	}
+
+	var secrets []expiringSecret
+	for _, dirPath := range dirPaths {
+		found, err := findExpiringSecrets(client, dirPath)
+		if cmd.path == "" && (api.IsErrNotFound(err) || err == api.ErrForbidden) {
+			// The repository can no longer be read, e.g. because it was removed.
+			continue
+		} else if err != nil {
+			return err
+		}
+		secrets = append(secrets, found...)
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	until := now.Add(within)
+
+	expiring := make([]expiringSecret, 0, len(secrets))
+	for _, secret := range secrets {
+		if !secret.expires.After(until) {
+			expiring = append(expiring, secret)
+		}
+	}
+	sort.Slice(expiring, func(i, j int) bool {
+		if !expiring[i].expires.Equal(expiring[j].expires) {
+			return expiring[i].expires.Before(expiring[j].expires)
+		}
+		return expiring[i].path < expiring[j].path
+	})
+
+	if len(expiring) == 0 {
+		fmt.Fprintf(cmd.io.Output(), "No secrets expire within %s.\n", cmd.within)
+		return nil
+	}
+
+	overdue := 0
+	tw := tabwriter.NewWriter(cmd.io.Output(), 0, 4, 4, ' ', 0)
+	fmt.Fprintf(tw, "%s\t%s\t%s\n", "PATH", "EXPIRES", "STATUS")
+	for _, secret := range expiring {
+		// Rounded, as a day is not always 24 hours when the clock is changed.
+		days := int(math.Round(secret.expires.Sub(today).Hours() / 24))
+
+		var status string
+		switch {
+		case days < 0:
+			status = fmt.Sprintf("overdue by %s", pluralize("day", "days", -days))
+			overdue++
+		case days == 0:
+			status = "expires today"
+		default:
+			status = fmt.Sprintf("expires in %s", pluralize("day", "days", days))
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", secret.path, secret.expires.Format(expiryDateLayout), status)
+	}
+	err = tw.Flush()
+	if err != nil {
+		return err
+	}
+
+	if overdue > 0 {
+		return ErrSecretsOverdue(overdue)
+	}
+	return nil
+}
+
+// findExpiringSecrets returns the secrets with an expires annotation in the directory and its subdirectories.
+// Annotations of secrets that no longer exist are ignored.
+func findExpiringSecrets(client secrethub.ClientInterface, dirPath string) ([]expiringSecret, error) {
+	tree, err := client.Dirs().GetTree(dirPath, -1, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// The names of the secrets by the lowercase name, per directory.
+	names := map[string]map[string]string{}
+	var annotated []string
+	for _, secret := range tree.Secrets {
+		dir, err := tree.AbsDirPath(secret.DirID)
+		if err != nil {
+			return nil, err
+		}
+		if secret.Name == annotationsFileName {
+			annotated = append(annotated, dir.Value())
+			continue
+		}
+		if names[dir.Value()] == nil {
+			names[dir.Value()] = map[string]string{}
+		}
+		names[dir.Value()][strings.ToLower(secret.Name)] = secret.Name
+	}
+
+	var secrets []expiringSecret
+	for _, dir := range annotated {
+		annotations, err := readAnnotations(client, dir)
+		if err != nil {
+			return nil, err
+		}
+
+		for key, secretAnnotations := range annotations {
+			name, ok := names[dir][key]
+			if !ok {
+				continue
+			}
+			value, ok := secretAnnotations[annotationExpires]
+			if !ok {
+				continue
+			}
+
+			path := secretpath.Join(dir, name)
+			expires, err := parseExpiryDate(path, value)
+			if err != nil {
+				return nil, err
+			}
+			secrets = append(secrets, expiringSecret{path: path, expires: expires})
+		}
+	}
+
+	return secrets, nil
+}
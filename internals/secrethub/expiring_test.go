@@ -0,0 +1,166 @@
+package secrethub
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/api/uuid"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestExpiringCommand_Run(t *testing.T) {
+	date := func(days int) string {
+		return time.Now().AddDate(0, 0, days).Format(expiryDateLayout)
+	}
+
+	repo1RootID := uuid.New()
+	dbID := uuid.New()
+	repo2RootID := uuid.New()
+	trees := map[string]*api.Tree{
+		"dev1/repo1": {
+			ParentPath: "dev1",
+			RootDir:    &api.Dir{DirID: repo1RootID, Name: "repo1"},
+			Dirs: map[uuid.UUID]*api.Dir{
+				repo1RootID: {DirID: repo1RootID, Name: "repo1"},
+				dbID:        {DirID: dbID, ParentID: &repo1RootID, Name: "db"},
+			},
+			Secrets: map[uuid.UUID]*api.Secret{
+				uuid.New(): {DirID: dbID, Name: "Password"},
+				uuid.New(): {DirID: dbID, Name: "user"},
+				uuid.New(): {DirID: dbID, Name: annotationsFileName},
+				uuid.New(): {DirID: repo1RootID, Name: "api_key"},
+				uuid.New(): {DirID: repo1RootID, Name: annotationsFileName},
+			},
+		},
+		"dev1/repo2": {
+			ParentPath: "dev1",
+			RootDir:    &api.Dir{DirID: repo2RootID, Name: "repo2"},
+			Dirs: map[uuid.UUID]*api.Dir{
+				repo2RootID: {DirID: repo2RootID, Name: "repo2"},
+			},
+			Secrets: map[uuid.UUID]*api.Secret{
+				uuid.New(): {DirID: repo2RootID, Name: "token"},
+				uuid.New(): {DirID: repo2RootID, Name: annotationsFileName},
+			},
+		},
+	}
+
+	cases := map[string]struct {
+		cmd         ExpiringCommand
+		annotations map[string]string
+		expectedOut string
+		expectedErr error
+	}{
+		"all repos": {
+			cmd: ExpiringCommand{
+				within: "30d",
+			},
+			annotations: map[string]string{
+				"dev1/repo1/db/.annotations": "password:\n  expires: \"" + date(10) + "\"\n" +
+					"user:\n  owner: team-db\n" +
+					"removed:\n  expires: \"" + date(-10) + "\"\n",
+				"dev1/repo1/.annotations": "api_key:\n  expires: \"" + date(0) + "\"\n",
+				"dev1/repo2/.annotations": "token:\n  expires: \"" + date(60) + "\"\n",
+			},
+			expectedOut: "PATH                      EXPIRES       STATUS\n" +
+				"dev1/repo1/api_key        " + date(0) + "    expires today\n" +
+				"dev1/repo1/db/Password    " + date(10) + "    expires in 10 days\n",
+		},
+		"overdue": {
+			cmd: ExpiringCommand{
+				path:   "dev1/repo2",
+				within: "1d",
+			},
+			annotations: map[string]string{
+				"dev1/repo2/.annotations": "token:\n  expires: \"" + date(-1) + "\"\n",
+			},
+			expectedOut: "PATH                EXPIRES       STATUS\n" +
+				"dev1/repo2/token    " + date(-1) + "    overdue by 1 day\n",
+			expectedErr: ErrSecretsOverdue(1),
+		},
+		"none": {
+			cmd: ExpiringCommand{
+				path:   "dev1/repo2",
+				within: "7d",
+			},
+			annotations: map[string]string{
+				"dev1/repo2/.annotations": "token:\n  expires: \"" + date(60) + "\"\n",
+			},
+			expectedOut: "No secrets expire within 7d.\n",
+		},
+		"invalid expiry date": {
+			cmd: ExpiringCommand{
+				path:   "dev1/repo2",
+				within: "30d",
+			},
+			annotations: map[string]string{
+				"dev1/repo2/.annotations": "token:\n  expires: soon\n",
+			},
+			expectedErr: ErrInvalidExpiryDate("soon", "dev1/repo2/token"),
+		},
+		"invalid within": {
+			cmd: ExpiringCommand{
+				within: "a month",
+			},
+			expectedErr: ErrInvalidWithin("a month"),
+		},
+		"dir not found": {
+			cmd: ExpiringCommand{
+				path:   "dev1/unknown",
+				within: "30d",
+			},
+			expectedErr: api.ErrDirNotFound,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			io := fakeui.NewIO(t)
+			tc.cmd.io = io
+			tc.cmd.newClient = func() (secrethub.ClientInterface, error) {
+				return fakeclient.Client{
+					RepoService: &fakeclient.RepoService{
+						ListMineFunc: func() ([]*api.Repo, error) {
+							return []*api.Repo{
+								{Owner: "dev1", Name: "repo1"},
+								{Owner: "dev1", Name: "repo2"},
+								{Owner: "dev1", Name: "removed"},
+							}, nil
+						},
+					},
+					DirService: &fakeclient.DirService{
+						GetTreeFunc: func(path string, depth int, ancestors bool) (*api.Tree, error) {
+							tree, ok := trees[path]
+							if !ok {
+								return nil, api.ErrDirNotFound
+							}
+							return tree, nil
+						},
+					},
+					SecretService: &fakeclient.SecretService{
+						VersionService: &fakeclient.SecretVersionService{
+							GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+								data, ok := tc.annotations[path]
+								if !ok {
+									return nil, errors.New("unexpected read of " + path)
+								}
+								return &api.SecretVersion{Data: []byte(data)}, nil
+							},
+						},
+					},
+				}, nil
+			}
+
+			err := tc.cmd.Run()
+
+			assert.Equal(t, err, tc.expectedErr)
+			assert.Equal(t, io.Out.String(), tc.expectedOut)
+		})
+	}
+}
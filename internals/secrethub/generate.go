@@ -28,10 +28,17 @@ var (
 	ErrCouldNotFindCharSet       = errGenerate.Code("charset_not_found").ErrorPref("could not find charset: %s")
 	ErrMinFlagInvalidInteger     = errGenerate.Code("min_flag_invalid_int").ErrorPref("second part of --min flag is not an integer: %s")
 	ErrInvalidMinFlag            = errGenerate.Code("min_flag_invalid").ErrorPref("min flag must be of the form <charset name>:<minimum count>, invalid min flag: %s")
+	ErrGeneratedPartlyWritten    = errGenerate.Code("partly_written").ErrorPref("could not write the %s to %s after writing %s, so they no longer match: %v")
 )
 
 const defaultLength = 22
 
+// Suffixes of the sibling secrets in which typed generators store the public parts of the generated artifacts.
+const (
+	publicKeySuffix   = ".pub"
+	certificateSuffix = ".crt"
+)
+
 // GenerateSecretCommand generates a new secret and writes to the output path.
 type GenerateSecretCommand struct {
 	symbolsFlag     bool
@@ -46,6 +53,7 @@ type GenerateSecretCommand struct {
 	copyToClipboard bool
 	newClient       newClientFunc
 	clipWriter      ClipboardWriter
+	policy          *dirPolicy
 }
 
 // NewGenerateSecretCommand creates a new GenerateSecretCommand.
@@ -62,11 +70,13 @@ func NewGenerateSecretCommand(io ui.IO, newClient newClientFunc) *GenerateSecret
 // Register registers the command, arguments and flags on the provided Registerer.
 func (cmd *GenerateSecretCommand) Register(r cli.Registerer) {
 	clause := r.Command("generate", "Generate a random secret.")
+	clause.HelpLong("When a password policy applies to the secret, its length, charsets and minimum counts are used as defaults. " +
+		"The generated value is checked against the policy before it is written.")
 	clause.Flags().VarP(&cmd.lengthFlag, "length", "l", "The length of the generated secret.")
 	clause.Cmd.Flag("length").DefValue = strconv.Itoa(defaultLength)
 	clause.Flags().Var(&cmd.mins, "min", "<charset>:<n> Ensure that the resulting password contains at least n characters from the given character set. Note that adding constraints reduces the strength of the secret. When possible, avoid any constraints.")
 	clause.Flags().BoolVarP(&cmd.copyToClipboard, "clip", "c", false, "Copy the generated value to the clipboard. The clipboard is automatically cleared after "+units.HumanDuration(clearClipboardAfter)+".")
-	_ = cmd.charsetFlag.Set("alphanumeric")
+	cmd.charsetFlag.v = randchar.Alphanumeric
 	clause.Flags().Var(&cmd.charsetFlag, "charset", "Define the set of characters to randomly generate a password from. Options are all, alphanumeric, numeric, lowercase, uppercase, letters, symbols and human-readable. Multiple character sets can be combined by supplying them in a comma separated list.")
 	clause.Cmd.Flag("charset").DefValue = "alphanumeric"
 	_ = clause.Cmd.RegisterFlagCompletionFunc("charset", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -75,6 +85,11 @@ func (cmd *GenerateSecretCommand) Register(r cli.Registerer) {
 	clause.Flags().BoolVarP(&cmd.symbolsFlag, "symbols", "s", false, "Include symbols in secret.")
 	clause.Cmd.Flag("symbols").Hidden = true
 
+	NewGenerateSSHCommand(cmd.io, cmd.newClient).Register(clause)
+	NewGenerateTLSCommand(cmd.io, cmd.newClient).Register(clause)
+	NewGenerateJWTCommand(cmd.io, cmd.newClient).Register(clause)
+	NewGeneratePassphraseCommand(cmd.io, cmd.newClient).Register(clause)
+
 	clause.BindAction(cmd.Run)
 	clause.BindArguments([]cli.Argument{
 		{Value: &cmd.firstArg, Name: "secret-path", Required: true, Placeholder: secretPathPlaceHolder, Description: "The path to write the generated secret to."},
@@ -91,11 +106,21 @@ func (cmd *GenerateSecretCommand) before() error {
 	}
 
 	charset := cmd.charsetFlag.v
+	mins := cmd.mins.v
+	if cmd.policy != nil {
+		if !cmd.charsetFlag.set {
+			charset = cmd.policy.policy.Charset(charset)
+		}
+		if len(mins) == 0 {
+			mins = cmd.policy.policy.Options()
+		}
+	}
+
 	if useSymbols {
 		charset = charset.Add(randchar.Symbols)
 	}
 
-	cmd.generator, err = randchar.NewRand(charset, cmd.mins.v...)
+	cmd.generator, err = randchar.NewRand(charset, mins...)
 	if err != nil {
 		return err
 	}
@@ -105,13 +130,34 @@ func (cmd *GenerateSecretCommand) before() error {
 
 // Run generates a new secret and writes to the output path.
 func (cmd *GenerateSecretCommand) Run() error {
-	err := cmd.before()
+	err := cmd.findPolicy()
+	if err != nil {
+		return err
+	}
+
+	err = cmd.before()
 	if err != nil {
 		return err
 	}
 	return cmd.run()
 }
 
+// findPolicy looks up the password policy that applies to the output path.
+func (cmd *GenerateSecretCommand) findPolicy() error {
+	path, err := cmd.path()
+	if err != nil {
+		return err
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	cmd.policy, err = newPolicyFinder(client).find(path)
+	return err
+}
+
 // run generates a new secret and writes to the output path.
 func (cmd *GenerateSecretCommand) run() error {
 	length, err := cmd.length()
@@ -133,6 +179,13 @@ func (cmd *GenerateSecretCommand) run() error {
 		return err
 	}
 
+	if cmd.policy != nil {
+		err = cmd.policy.check(data)
+		if err != nil {
+			return err
+		}
+	}
+
 	client, err := cmd.newClient()
 	if err != nil {
 		return err
@@ -161,6 +214,53 @@ func (cmd *GenerateSecretCommand) run() error {
 	return nil
 }
 
+// generatedSecret is a part of a generated artifact, such as the private or the public key of a key pair.
+type generatedSecret struct {
+	description string
+	path        string
+	data        []byte
+}
+
+// writeGeneratedSecrets writes the parts of a generated artifact as sibling secrets.
+// All paths and values are validated before anything is written.
+// When a part cannot be written, the error lists the parts that were written before it.
+func writeGeneratedSecrets(io ui.IO, newClient newClientFunc, secrets ...generatedSecret) error {
+	for _, secret := range secrets {
+		err := api.ValidateSecretPath(secret.path)
+		if err != nil {
+			return err
+		}
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	finder := newPolicyFinder(client)
+	for _, secret := range secrets {
+		err = finder.check(secret.path, secret.data)
+		if err != nil {
+			return err
+		}
+	}
+
+	written := make([]string, 0, len(secrets))
+	for _, secret := range secrets {
+		version, err := client.Secrets().Write(secret.path, secret.data)
+		if err != nil && len(written) > 0 {
+			return ErrGeneratedPartlyWritten(secret.description, secret.path, strings.Join(written, ", "), err)
+		} else if err != nil {
+			return err
+		}
+		written = append(written, secret.path)
+
+		fmt.Fprintf(io.Output(), "The %s has been written to %s:%d.\n", secret.description, secret.path, version.Version)
+	}
+
+	return nil
+}
+
 func (cmd *GenerateSecretCommand) length() (int, error) {
 	if cmd.lengthArg.IsSet() && cmd.lengthFlag.IsSet() {
 		return 0, ErrCannotUseLengthArgAndFlag
@@ -171,6 +271,9 @@ func (cmd *GenerateSecretCommand) length() (int, error) {
 	if cmd.lengthArg.IsSet() {
 		return cmd.lengthArg.Get(), nil
 	}
+	if cmd.policy != nil && cmd.policy.policy.Length > defaultLength {
+		return cmd.policy.policy.Length, nil
+	}
 	return defaultLength, nil
 }
 
@@ -241,7 +344,8 @@ func (ov *minRuleValue) IsCumulative() bool {
 }
 
 type charsetValue struct {
-	v randchar.Charset
+	v   randchar.Charset
+	set bool
 }
 
 func (cv *charsetValue) Type() string {
@@ -261,6 +365,7 @@ func (cv *charsetValue) Set(flagValue string) error {
 		}
 		cv.v = cv.v.Add(charset)
 	}
+	cv.set = true
 	return nil
 }
 
@@ -0,0 +1,63 @@
+package secrethub
+
+import (
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/keygen"
+
+	"github.com/spf13/cobra"
+)
+
+// GenerateJWTCommand generates a key to sign JSON Web Tokens with and writes it to SecretHub.
+type GenerateJWTCommand struct {
+	io        ui.IO
+	newClient newClientFunc
+	path      cli.StringValue
+	alg       string
+}
+
+// NewGenerateJWTCommand creates a new GenerateJWTCommand.
+func NewGenerateJWTCommand(io ui.IO, newClient newClientFunc) *GenerateJWTCommand {
+	return &GenerateJWTCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *GenerateJWTCommand) Register(r cli.Registerer) {
+	clause := r.Command("jwt", "Generate a key to sign JSON Web Tokens.")
+	clause.HelpLong("Generate a key to sign JSON Web Tokens. " +
+		"For the HMAC algorithms (HS256, HS384 and HS512), a random secret is written to the given path, encoded with unpadded base64url. " +
+		"For the other algorithms, the private key is written PEM encoded to the given path and the public key is written to a sibling secret with the .pub suffix.")
+	clause.Flags().StringVar(&cmd.alg, "alg", keygen.JWTAlgHS256, "The signing algorithm. The options are "+strings.Join(keygen.JWTAlgorithms, ", ")+".")
+	_ = clause.Cmd.RegisterFlagCompletionFunc("alg", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return keygen.JWTAlgorithms, cobra.ShellCompDirectiveDefault
+	})
+
+	clause.BindAction(cmd.Run)
+	clause.BindArguments([]cli.Argument{
+		{Value: &cmd.path, Name: "secret-path", Required: true, Placeholder: secretPathPlaceHolder, Description: "The path to write the signing key to."},
+	})
+}
+
+// Run generates the signing key and writes it to SecretHub.
+func (cmd *GenerateJWTCommand) Run() error {
+	private, public, err := keygen.JWTKey(cmd.alg)
+	if err != nil {
+		return err
+	}
+
+	if public == nil {
+		return writeGeneratedSecrets(cmd.io, cmd.newClient,
+			generatedSecret{description: cmd.alg + " secret", path: cmd.path.Value, data: private},
+		)
+	}
+
+	return writeGeneratedSecrets(cmd.io, cmd.newClient,
+		generatedSecret{description: cmd.alg + " private key", path: cmd.path.Value, data: private},
+		generatedSecret{description: cmd.alg + " public key", path: cmd.path.Value + publicKeySuffix, data: public},
+	)
+}
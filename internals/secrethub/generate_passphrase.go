@@ -0,0 +1,86 @@
+package secrethub
+
+import (
+	"fmt"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/clip"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/keygen"
+
+	"github.com/docker/go-units"
+)
+
+const (
+	defaultPassphraseWords     = 6
+	defaultPassphraseSeparator = "-"
+)
+
+// GeneratePassphraseCommand generates a diceware passphrase and writes it to SecretHub.
+type GeneratePassphraseCommand struct {
+	io              ui.IO
+	newClient       newClientFunc
+	clipWriter      ClipboardWriter
+	path            cli.StringValue
+	words           int
+	separator       string
+	copyToClipboard bool
+	generate        func(words int, separator string) (string, error)
+}
+
+// NewGeneratePassphraseCommand creates a new GeneratePassphraseCommand.
+func NewGeneratePassphraseCommand(io ui.IO, newClient newClientFunc) *GeneratePassphraseCommand {
+	return &GeneratePassphraseCommand{
+		io:        io,
+		newClient: newClient,
+		clipWriter: &ClipboardWriterAutoClear{
+			clipper: clip.NewClipboard(),
+		},
+		generate: keygen.Passphrase,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *GeneratePassphraseCommand) Register(r cli.Registerer) {
+	clause := r.Command("passphrase", "Generate a diceware passphrase.")
+	clause.HelpLong("Generate a diceware passphrase of words picked at random from the large wordlist of the EFF. " +
+		"Every word adds almost 12.9 bits of entropy, so the default of 6 words results in a passphrase of more than 77 bits of entropy.")
+	clause.Flags().IntVar(&cmd.words, "words", defaultPassphraseWords, "The number of words in the passphrase.")
+	clause.Flags().StringVar(&cmd.separator, "separator", defaultPassphraseSeparator, "The separator to put between the words.")
+	clause.Flags().BoolVarP(&cmd.copyToClipboard, "clip", "c", false, "Copy the generated passphrase to the clipboard. The clipboard is automatically cleared after "+units.HumanDuration(clearClipboardAfter)+".")
+
+	clause.BindAction(cmd.Run)
+	clause.BindArguments([]cli.Argument{
+		{Value: &cmd.path, Name: "secret-path", Required: true, Placeholder: secretPathPlaceHolder, Description: "The path to write the generated passphrase to."},
+	})
+}
+
+// Run generates the passphrase and writes it to SecretHub.
+func (cmd *GeneratePassphraseCommand) Run() error {
+	passphrase, err := cmd.generate(cmd.words, cmd.separator)
+	if err != nil {
+		return err
+	}
+
+	err = writeGeneratedSecrets(cmd.io, cmd.newClient,
+		generatedSecret{description: "passphrase", path: cmd.path.Value, data: []byte(passphrase)},
+	)
+	if err != nil {
+		return err
+	}
+
+	if cmd.copyToClipboard {
+		err = cmd.clipWriter.Write([]byte(passphrase))
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(
+			cmd.io.Output(),
+			"The generated passphrase has been copied to the clipboard. It will be cleared after %s.\n",
+			units.HumanDuration(clearClipboardAfter),
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package secrethub
+
+import (
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+	"github.com/secrethub/secrethub-cli/internals/keygen"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestGeneratePassphraseCommand_Run(t *testing.T) {
+	cases := map[string]struct {
+		words       int
+		path        string
+		expectedOut string
+		err         error
+	}{
+		"success": {
+			words:       3,
+			path:        "company/repo/passphrase",
+			expectedOut: "The passphrase has been written to company/repo/passphrase:2.\n",
+		},
+		"invalid word count": {
+			words: 0,
+			path:  "company/repo/passphrase",
+			err:   keygen.ErrInvalidWordCount,
+		},
+		"invalid path": {
+			words: 3,
+			path:  "company/passphrase",
+			err:   api.ErrInvalidSecretPath("company/passphrase"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var written []byte
+			io := fakeui.NewIO(t)
+			cmd := GeneratePassphraseCommand{
+				io:        io,
+				path:      cli.StringValue{Value: tc.path},
+				words:     tc.words,
+				separator: ".",
+				generate: func(words int, separator string) (string, error) {
+					if words < 1 {
+						return "", keygen.ErrInvalidWordCount
+					}
+					return "correct.horse.battery", nil
+				},
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						SecretService: &fakeclient.SecretService{
+							WriteFunc: func(path string, data []byte) (*api.SecretVersion, error) {
+								written = data
+								return &api.SecretVersion{Version: 2}, nil
+							},
+							VersionService: &fakeclient.SecretVersionService{
+								GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+									return nil, api.ErrSecretNotFound
+								},
+							},
+						},
+					}, nil
+				},
+			}
+
+			err := cmd.Run()
+
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, io.Out.String(), tc.expectedOut)
+			if tc.err == nil {
+				assert.Equal(t, string(written), "correct.horse.battery")
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package secrethub
+
+import (
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/keygen"
+
+	"github.com/spf13/cobra"
+)
+
+// GenerateSSHCommand generates an SSH key pair and writes it to sibling secrets.
+type GenerateSSHCommand struct {
+	io        ui.IO
+	newClient newClientFunc
+	path      cli.StringValue
+	keyType   string
+	bits      int
+	comment   string
+}
+
+// NewGenerateSSHCommand creates a new GenerateSSHCommand.
+func NewGenerateSSHCommand(io ui.IO, newClient newClientFunc) *GenerateSSHCommand {
+	return &GenerateSSHCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *GenerateSSHCommand) Register(r cli.Registerer) {
+	clause := r.Command("ssh", "Generate an SSH key pair.")
+	clause.HelpLong("Generate an SSH key pair. The private key is written to the given path in the OpenSSH format " +
+		"and the public key is written in the authorized_keys format to a sibling secret with the .pub suffix.")
+	clause.Flags().StringVar(&cmd.keyType, "type", keygen.KeyTypeEd25519, "The type of the key. The options are ed25519 and rsa.")
+	_ = clause.Cmd.RegisterFlagCompletionFunc("type", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{keygen.KeyTypeEd25519, keygen.KeyTypeRSA}, cobra.ShellCompDirectiveDefault
+	})
+	clause.Flags().IntVar(&cmd.bits, "bits", 0, "The size of the key in bits. Only applies to rsa keys and defaults to 4096.")
+	clause.Flags().StringVar(&cmd.comment, "comment", "", "A comment to add to the public key, e.g. user@host.")
+
+	clause.BindAction(cmd.Run)
+	clause.BindArguments([]cli.Argument{
+		{Value: &cmd.path, Name: "secret-path", Required: true, Placeholder: secretPathPlaceHolder, Description: "The path to write the private key to."},
+	})
+}
+
+// Run generates the key pair and writes it to SecretHub.
+func (cmd *GenerateSSHCommand) Run() error {
+	private, public, err := keygen.SSHKeyPair(cmd.keyType, cmd.bits, cmd.comment)
+	if err != nil {
+		return err
+	}
+
+	return writeGeneratedSecrets(cmd.io, cmd.newClient,
+		generatedSecret{description: "private key", path: cmd.path.Value, data: private},
+		generatedSecret{description: "public key", path: cmd.path.Value + publicKeySuffix, data: public},
+	)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/secrethub/secrethub-go/internals/api"
 	"github.com/secrethub/secrethub-go/internals/assert"
 	"github.com/secrethub/secrethub-go/internals/errio"
+	"github.com/secrethub/secrethub-go/pkg/randchar"
 	randchargeneratorfakes "github.com/secrethub/secrethub-go/pkg/randchar/fakes"
 	"github.com/secrethub/secrethub-go/pkg/secrethub"
 	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
@@ -224,3 +225,140 @@ func TestGenerateSecretCommand_run(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateSecretCommand_Run_policy(t *testing.T) {
+	cases := map[string]struct {
+		policy         string
+		length         intValue
+		expectedLength int
+		expectedErr    error
+	}{
+		"policy defaults": {
+			policy:         "length: 32\ncharsets: [numeric]",
+			expectedLength: 32,
+		},
+		"policy shorter than default": {
+			policy:         "length: 8\ncharsets: [numeric]",
+			expectedLength: defaultLength,
+		},
+		"length flag violates policy": {
+			policy:      "length: 32\ncharsets: [numeric]",
+			length:      newIntValue(10),
+			expectedErr: ErrPolicyViolation("namespace/repo/.policy", "is 10 characters long, the minimum is 32"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var written []byte
+			cmd := GenerateSecretCommand{
+				io:         fakeui.NewIO(t),
+				firstArg:   cli.StringValue{Value: "namespace/repo/dir/secret"},
+				lengthFlag: tc.length,
+				charsetFlag: charsetValue{
+					v: randchar.Alphanumeric,
+				},
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						SecretService: &fakeclient.SecretService{
+							WriteFunc: func(path string, data []byte) (*api.SecretVersion, error) {
+								written = data
+								return &api.SecretVersion{Version: 1}, nil
+							},
+							VersionService: &fakeclient.SecretVersionService{
+								GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+									if path == "namespace/repo/.policy" {
+										return &api.SecretVersion{Data: []byte(tc.policy)}, nil
+									}
+									return nil, api.ErrSecretNotFound
+								},
+							},
+						},
+					}, nil
+				},
+			}
+
+			err := cmd.Run()
+
+			assert.Equal(t, err, tc.expectedErr)
+			if tc.expectedErr == nil {
+				assert.Equal(t, len(written), tc.expectedLength)
+				assert.Equal(t, randchar.NewCharset(string(written)).IsSubset(randchar.Numeric), true)
+			}
+		})
+	}
+}
+
+func TestWriteGeneratedSecrets(t *testing.T) {
+	testErr := errors.New("test")
+	secrets := []generatedSecret{
+		{description: "private key", path: "namespace/repo/key", data: []byte("private key value")},
+		{description: "public key", path: "namespace/repo/key.pub", data: []byte("public key value")},
+	}
+
+	cases := map[string]struct {
+		policy          string
+		writeErr        map[string]error
+		expectedWritten []string
+		expectedOut     string
+		expectedErr     error
+	}{
+		"success": {
+			expectedWritten: []string{"namespace/repo/key", "namespace/repo/key.pub"},
+			expectedOut: "The private key has been written to namespace/repo/key:1.\n" +
+				"The public key has been written to namespace/repo/key.pub:1.\n",
+		},
+		"policy violated": {
+			policy:      "forbidden_words: [public]",
+			expectedErr: ErrPolicyViolation("namespace/repo/.policy", `contains the forbidden word "public"`),
+		},
+		"first write fails": {
+			writeErr: map[string]error{
+				"namespace/repo/key": testErr,
+			},
+			expectedErr: testErr,
+		},
+		"second write fails": {
+			writeErr: map[string]error{
+				"namespace/repo/key.pub": testErr,
+			},
+			expectedWritten: []string{"namespace/repo/key"},
+			expectedOut:     "The private key has been written to namespace/repo/key:1.\n",
+			expectedErr:     ErrGeneratedPartlyWritten("public key", "namespace/repo/key.pub", "namespace/repo/key", testErr),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var written []string
+			io := fakeui.NewIO(t)
+			newClient := func() (secrethub.ClientInterface, error) {
+				return fakeclient.Client{
+					SecretService: &fakeclient.SecretService{
+						WriteFunc: func(path string, data []byte) (*api.SecretVersion, error) {
+							if err := tc.writeErr[path]; err != nil {
+								return nil, err
+							}
+							written = append(written, path)
+							return &api.SecretVersion{Version: 1}, nil
+						},
+						VersionService: &fakeclient.SecretVersionService{
+							GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+								if path == "namespace/repo/.policy" && tc.policy != "" {
+									return &api.SecretVersion{Data: []byte(tc.policy)}, nil
+								}
+								return nil, api.ErrSecretNotFound
+							},
+						},
+					},
+				}, nil
+			}
+
+			err := writeGeneratedSecrets(io, newClient, secrets...)
+
+			assert.Equal(t, err, tc.expectedErr)
+			assert.Equal(t, written, tc.expectedWritten)
+			assert.Equal(t, io.Out.String(), tc.expectedOut)
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package secrethub
+
+import (
+	"net"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/keygen"
+
+	"github.com/spf13/cobra"
+)
+
+// Errors
+var (
+	ErrInvalidIPAddress = errGenerate.Code("invalid_ip_address").ErrorPref("invalid IP address: %s")
+	ErrNoCommonName     = errGenerate.Code("no_common_name").Error("set the common name of the certificate with --common-name or add a --dns-name")
+)
+
+// GenerateTLSCommand generates a private key and an X.509 certificate and writes them to sibling secrets.
+type GenerateTLSCommand struct {
+	io          ui.IO
+	newClient   newClientFunc
+	path        cli.StringValue
+	commonName  string
+	dnsNames    []string
+	ipAddresses []string
+	days        int
+	isCA        bool
+	caPath      string
+	keyType     string
+	bits        int
+}
+
+// NewGenerateTLSCommand creates a new GenerateTLSCommand.
+func NewGenerateTLSCommand(io ui.IO, newClient newClientFunc) *GenerateTLSCommand {
+	return &GenerateTLSCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *GenerateTLSCommand) Register(r cli.Registerer) {
+	clause := r.Command("tls", "Generate a private key and a self-signed or CA-signed X.509 certificate.")
+	clause.HelpLong("Generate a private key and an X.509 certificate. The private key is written PEM encoded to the given path " +
+		"and the certificate is written PEM encoded to a sibling secret with the .crt suffix.\n" +
+		"\n" +
+		"By default, the certificate is self-signed. Use --ca to sign it with a CA that was generated with --is-ca, " +
+		"or any CA of which the private key is stored at the given path and the certificate at the same path with the .crt suffix. " +
+		"The certificate of the CA is then appended to the certificate, so it can be served with its chain.")
+	clause.Flags().StringVar(&cmd.commonName, "common-name", "", "The common name of the subject of the certificate. Defaults to the first --dns-name.")
+	clause.Flags().StringSliceVar(&cmd.dnsNames, "dns-name", nil, "A DNS name to add as subject alternative name. Can be repeated or comma separated.")
+	clause.Flags().StringSliceVar(&cmd.ipAddresses, "ip-address", nil, "An IP address to add as subject alternative name. Can be repeated or comma separated.")
+	clause.Flags().IntVar(&cmd.days, "days", 365, "The number of days the certificate is valid.")
+	clause.Flags().BoolVar(&cmd.isCA, "is-ca", false, "Generate a certificate that can sign other certificates.")
+	clause.Flags().StringVar(&cmd.caPath, "ca", "", "The path to the private key of the CA to sign the certificate with. The certificate of the CA is read from the same path with the .crt suffix.")
+	clause.Flags().StringVar(&cmd.keyType, "key-type", keygen.KeyTypeECDSA, "The type of the private key. The options are ecdsa, rsa and ed25519.")
+	_ = clause.Cmd.RegisterFlagCompletionFunc("key-type", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{keygen.KeyTypeECDSA, keygen.KeyTypeRSA, keygen.KeyTypeEd25519}, cobra.ShellCompDirectiveDefault
+	})
+	clause.Flags().IntVar(&cmd.bits, "bits", 0, "The size of the private key in bits. Defaults to 256 for ecdsa and 4096 for rsa keys.")
+
+	clause.BindAction(cmd.Run)
+	clause.BindArguments([]cli.Argument{
+		{Value: &cmd.path, Name: "secret-path", Required: true, Placeholder: secretPathPlaceHolder, Description: "The path to write the private key to."},
+	})
+}
+
+// Run generates the private key and certificate and writes them to SecretHub.
+func (cmd *GenerateTLSCommand) Run() error {
+	opts := keygen.CertificateOptions{
+		CommonName: cmd.commonName,
+		DNSNames:   cmd.dnsNames,
+		ValidFor:   time.Duration(cmd.days) * 24 * time.Hour,
+		IsCA:       cmd.isCA,
+		KeyType:    cmd.keyType,
+		Bits:       cmd.bits,
+	}
+
+	if opts.CommonName == "" {
+		if len(cmd.dnsNames) == 0 {
+			return ErrNoCommonName
+		}
+		opts.CommonName = cmd.dnsNames[0]
+	}
+
+	for _, ipAddress := range cmd.ipAddresses {
+		ip := net.ParseIP(ipAddress)
+		if ip == nil {
+			return ErrInvalidIPAddress(ipAddress)
+		}
+		opts.IPAddresses = append(opts.IPAddresses, ip)
+	}
+
+	if cmd.caPath != "" {
+		ca, err := cmd.readCA()
+		if err != nil {
+			return err
+		}
+		opts.CA = ca
+	}
+
+	key, cert, err := keygen.Certificate(opts)
+	if err != nil {
+		return err
+	}
+
+	return writeGeneratedSecrets(cmd.io, cmd.newClient,
+		generatedSecret{description: "private key", path: cmd.path.Value, data: key},
+		generatedSecret{description: "certificate", path: cmd.path.Value + certificateSuffix, data: cert},
+	)
+}
+
+// readCA reads the private key and certificate of the CA from SecretHub.
+func (cmd *GenerateTLSCommand) readCA() (*keygen.CA, error) {
+	client, err := cmd.newClient()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := client.Secrets().Versions().GetWithData(cmd.caPath)
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := client.Secrets().Versions().GetWithData(cmd.caPath + certificateSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	return keygen.ParseCA(key.Data, cert.Data)
+}
@@ -0,0 +1,87 @@
+package secrethub
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+	"github.com/secrethub/secrethub-cli/internals/keygen"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestGenerateTLSCommand_Run(t *testing.T) {
+	secrets := map[string][]byte{}
+	newClient := func() (secrethub.ClientInterface, error) {
+		return fakeclient.Client{
+			SecretService: &fakeclient.SecretService{
+				WriteFunc: func(path string, data []byte) (*api.SecretVersion, error) {
+					secrets[path] = data
+					return &api.SecretVersion{Version: 1}, nil
+				},
+				VersionService: &fakeclient.SecretVersionService{
+					GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+						data, ok := secrets[path]
+						if !ok {
+							return nil, api.ErrSecretNotFound
+						}
+						return &api.SecretVersion{Data: data}, nil
+					},
+				},
+			},
+		}, nil
+	}
+
+	// Generate a CA.
+	io := fakeui.NewIO(t)
+	cmd := GenerateTLSCommand{
+		io:         io,
+		newClient:  newClient,
+		path:       cli.StringValue{Value: "company/pki/ca"},
+		commonName: "Company CA",
+		days:       30,
+		isCA:       true,
+		keyType:    keygen.KeyTypeECDSA,
+	}
+
+	err := cmd.Run()
+	assert.OK(t, err)
+	assert.Equal(t, io.Out.String(), "The private key has been written to company/pki/ca:1.\n"+
+		"The certificate has been written to company/pki/ca.crt:1.\n")
+
+	// Generate a certificate signed by the CA.
+	io = fakeui.NewIO(t)
+	cmd = GenerateTLSCommand{
+		io:          io,
+		newClient:   newClient,
+		path:        cli.StringValue{Value: "company/app/tls"},
+		dnsNames:    []string{"app.example.com"},
+		ipAddresses: []string{"10.0.0.1"},
+		days:        30,
+		caPath:      "company/pki/ca",
+		keyType:     keygen.KeyTypeECDSA,
+	}
+
+	err = cmd.Run()
+	assert.OK(t, err)
+
+	block, _ := pem.Decode(secrets["company/app/tls.crt"])
+	cert, err := x509.ParseCertificate(block.Bytes)
+	assert.OK(t, err)
+	assert.Equal(t, cert.Subject.CommonName, "app.example.com")
+	assert.Equal(t, cert.Issuer.CommonName, "Company CA")
+	assert.Equal(t, cert.IPAddresses[0].String(), "10.0.0.1")
+
+	// Invalid input.
+	cmd.dnsNames = nil
+	assert.Equal(t, cmd.Run(), ErrNoCommonName)
+
+	cmd.commonName = "app"
+	cmd.ipAddresses = []string{"10.0.0"}
+	assert.Equal(t, cmd.Run(), ErrInvalidIPAddress("10.0.0"))
+}
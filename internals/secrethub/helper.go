@@ -2,6 +2,9 @@ package secrethub
 
 import (
 	"fmt"
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/policy"
 
 	"github.com/fatih/color"
 	"github.com/secrethub/secrethub-go/internals/api"
@@ -28,3 +31,42 @@ func colorizeByStatus(status string, msg interface{}) interface{} {
 		return msg
 	}
 }
+
+// isReservedSecretName returns whether a secret with the given name holds the annotations
+// or the policy of its directory instead of a value of its own.
+// Such secrets are not listed or exported as ordinary secrets.
+func isReservedSecretName(name string) bool {
+	return strings.EqualFold(name, annotationsFileName) || strings.EqualFold(name, policy.FileName)
+}
+
+// hideReservedSecrets removes the secrets with a reserved name from the tree.
+func hideReservedSecrets(tree *api.Tree) {
+	for id, secret := range tree.Secrets {
+		if isReservedSecretName(secret.Name) {
+			delete(tree.Secrets, id)
+		}
+	}
+
+	hideReservedDirSecrets(tree.RootDir)
+	for _, dir := range tree.Dirs {
+		hideReservedDirSecrets(dir)
+	}
+}
+
+// hideReservedDirSecrets removes the secrets with a reserved name from the directory.
+func hideReservedDirSecrets(dir *api.Dir) {
+	if dir == nil {
+		return
+	}
+
+	secrets := make([]*api.Secret, 0, len(dir.Secrets))
+	for _, secret := range dir.Secrets {
+		if !isReservedSecretName(secret.Name) {
+			secrets = append(secrets, secret)
+		}
+	}
+	dir.Secrets = secrets
+	for _, sub := range dir.SubDirs {
+		hideReservedDirSecrets(sub)
+	}
+}
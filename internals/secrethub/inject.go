@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/secrethub/secrethub-cli/internals/cli"
 	"github.com/secrethub/secrethub-cli/internals/cli/clip"
@@ -12,14 +13,22 @@ import (
 	"github.com/secrethub/secrethub-cli/internals/cli/posix"
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
 	"github.com/secrethub/secrethub-cli/internals/secrethub/tpl"
+	"github.com/secrethub/secrethub-cli/internals/structured"
 
 	"github.com/docker/go-units"
+	"github.com/spf13/cobra"
 )
 
 // Errors
 var (
 	ErrUnknownTemplateVersion = errMain.Code("unknown_template_version").ErrorPref("unknown template version: '%s' supported versions are 1, 2 and latest")
 	ErrReadFile               = errMain.Code("in_file_read_error").ErrorPref("could not read the input file %s: %s")
+	ErrDetectFormatFromStdin  = errMain.Code("detect_format_from_stdin").Error("cannot detect the format of input read from stdin: set the format explicitly with --format or use the --in-file flag")
+)
+
+const (
+	injectFormatText = "text"
+	injectFormatAuto = "auto"
 )
 
 // InjectCommand is a command to read a secret.
@@ -36,6 +45,9 @@ type InjectCommand struct {
 	templateVars                  map[string]string
 	templateVersion               string
 	dontPromptMissingTemplateVars bool
+	format                        string
+	stage                         string
+	readFile                      func(filename string) ([]byte, error)
 }
 
 // NewInjectCommand creates a new InjectCommand.
@@ -49,6 +61,7 @@ func NewInjectCommand(io ui.IO, newClient newClientFunc) *InjectCommand {
 		newClient:    newClient,
 		templateVars: make(map[string]string),
 		fileMode:     filemode.New(0600),
+		readFile:     ioutil.ReadFile,
 	}
 }
 
@@ -71,6 +84,13 @@ func (cmd *InjectCommand) Register(r cli.Registerer) {
 	clause.Flags().StringVar(&cmd.templateVersion, "template-version", "auto", "Do not prompt when a template variable is missing and return an error instead.")
 	clause.Flags().BoolVar(&cmd.dontPromptMissingTemplateVars, "no-prompt", false, "Do not prompt when a template variable is missing and return an error instead.")
 	clause.Flags().BoolVarP(&cmd.force, "force", "f", false, "Overwrite the output file if it already exists, without prompting for confirmation. This flag is ignored if no --out-file is supplied.")
+	clause.Flags().StringVar(&cmd.stage, "stage", "", "Make the variables of a stage declared in the secrethub.env file available as template variables. Variables set with --var take precedence.")
+	clause.Flags().StringVar(&cmd.format, "format", injectFormatText, "The format of the input. With text, the input is treated as a template. "+
+		"With yaml, json, toml or ini, the input is parsed and every value of the form `secrethub://<path>` is replaced with the correctly escaped secret, preserving key order and comments where the format allows. "+
+		"Use auto to detect the format from the extension of the --in-file.")
+	_ = clause.Cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{injectFormatText, injectFormatAuto, structured.FormatYAML, structured.FormatJSON, structured.FormatTOML, structured.FormatINI}, cobra.ShellCompDirectiveDefault
+	})
 
 	clause.BindAction(cmd.Run)
 	clause.BindArguments(nil)
@@ -101,34 +121,20 @@ func (cmd *InjectCommand) Run() error {
 		}
 	}
 
-	osEnv, _ := parseKeyValueStringsToMap(cmd.osEnv)
-
-	var templateVariableReader tpl.VariableReader
-	templateVariableReader, err = newVariableReader(osEnv, cmd.templateVars)
-	if err != nil {
-		return err
-	}
-
-	if !cmd.dontPromptMissingTemplateVars {
-		templateVariableReader = newPromptMissingVariableReader(templateVariableReader, cmd.io)
-	}
-
-	parser, err := getTemplateParser(raw, cmd.templateVersion)
-	if err != nil {
-		return err
+	if cmd.stage != "" && cmd.format != injectFormatText {
+		return ErrFlagsConflict("--stage and --format " + cmd.format)
 	}
 
-	template, err := parser.Parse(string(raw), 1, 1)
-	if err != nil {
-		return err
+	var out []byte
+	if cmd.format == injectFormatText {
+		out, err = cmd.injectTemplate(raw)
+	} else {
+		out, err = cmd.injectStructured(raw)
 	}
-
-	injected, err := template.Evaluate(templateVariableReader, newSecretReader(cmd.newClient))
 	if err != nil {
 		return err
 	}
 
-	out := []byte(injected)
 	if cmd.useClipboard {
 		err = cmd.clipWriter.Write(out)
 		if err != nil {
@@ -181,3 +187,75 @@ func (cmd *InjectCommand) Run() error {
 
 	return nil
 }
+
+// injectTemplate evaluates the raw input as a template.
+func (cmd *InjectCommand) injectTemplate(raw []byte) ([]byte, error) {
+	osEnv, _ := parseKeyValueStringsToMap(cmd.osEnv)
+
+	templateVars := cmd.templateVars
+	if cmd.stage != "" {
+		stageVars, err := readEnvFileStage(cmd.readFile, defaultEnvFile, cmd.stage)
+		if err != nil {
+			return nil, err
+		}
+
+		templateVars = make(map[string]string, len(stageVars)+len(cmd.templateVars))
+		for k, v := range stageVars {
+			templateVars[strings.ToLower(k)] = v
+		}
+		for k, v := range cmd.templateVars {
+			templateVars[strings.ToLower(k)] = v
+		}
+	}
+
+	var templateVariableReader tpl.VariableReader
+	templateVariableReader, err := newVariableReader(osEnv, templateVars)
+	if err != nil {
+		return nil, err
+	}
+
+	if !cmd.dontPromptMissingTemplateVars {
+		templateVariableReader = newPromptMissingVariableReader(templateVariableReader, cmd.io)
+	}
+
+	parser, err := getTemplateParser(raw, cmd.templateVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := parser.Parse(string(raw), 1, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	injected, err := template.Evaluate(templateVariableReader, newSecretReader(cmd.newClient))
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(injected), nil
+}
+
+// injectStructured parses the raw input in the configured format and
+// replaces all secret references in it.
+func (cmd *InjectCommand) injectStructured(raw []byte) ([]byte, error) {
+	format := cmd.format
+	if format == injectFormatAuto {
+		if cmd.inFile == "" {
+			return nil, ErrDetectFormatFromStdin
+		}
+
+		var err error
+		format, err = structured.DetectFormat(cmd.inFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	injector, err := structured.NewInjector(format)
+	if err != nil {
+		return nil, err
+	}
+
+	return injector.Inject(raw, newSecretReader(cmd.newClient))
+}
@@ -1,12 +1,10 @@
 package secrethub
 
 import (
-	"fmt"
-
-	"github.com/secrethub/secrethub-cli/internals/cli"
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
 
 	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/pkg/secretpath"
 )
 
 // InspectSecretCommand prints out a secret's details.
@@ -44,36 +42,44 @@ func (cmd *InspectSecretCommand) Run() error {
 		return err
 	}
 
-	output, err := cli.PrettyJSON(newSecretOutput(secret.Secret, versions, cmd.timeFormatter))
+	annotations, err := readAnnotations(client, secretpath.Parent(cmd.path.Value()))
 	if err != nil {
 		return err
 	}
 
-	fmt.Fprintln(cmd.io.Output(), output)
+	output := newSecretOutput(cmd.path.Value(), secret.Secret, cmd.timeFormatter)
+	output.Annotations = annotations.of(secret.Secret.Name)
+	output.Versions = make([]secretVersionOutput, len(versions))
+	for i, version := range versions {
+		output.Versions[i] = newSecretVersionOutput(version, cmd.timeFormatter)
+	}
 
-	return nil
+	return newPrettyJSONFormatter(cmd.io.Output()).WriteValue(output)
 }
 
-// newSecretOutput returns the JSON output of a secret.
-func newSecretOutput(secret *api.Secret, versions []*api.SecretVersion, timeFormatter TimeFormatter) secretOutput {
-	out := secretOutput{
-		Name:         secret.Name,
-		CreatedAt:    timeFormatter.Format(secret.CreatedAt.Local()),
-		VersionCount: secret.VersionCount,
-		Versions:     make([]secretVersionOutput, len(versions)),
+// newSecretOutput returns the JSON output of a secret, without its versions.
+func newSecretOutput(path string, secret *api.Secret, timeFormatter TimeFormatter) secretOutput {
+	return secretOutput{
+		SecretID:      secret.SecretID.String(),
+		Name:          secret.Name,
+		Path:          path,
+		Status:        secret.Status,
+		CreatedAt:     timeFormatter.Format(secret.CreatedAt.Local()),
+		VersionCount:  secret.VersionCount,
+		LatestVersion: secret.LatestVersion,
 	}
-
-	for i, version := range versions {
-		out.Versions[i] = newSecretVersionOutput(version, timeFormatter)
-	}
-
-	return out
 }
 
 // secretOutput is the printable JSON format of a secret.
+// It is shared by the inspect and tree commands.
 type secretOutput struct {
-	Name         string
-	CreatedAt    string
-	VersionCount int
-	Versions     []secretVersionOutput
+	SecretID      string
+	Name          string
+	Path          string
+	Status        string
+	CreatedAt     string
+	VersionCount  int
+	LatestVersion int
+	Annotations   map[string]string     `json:",omitempty"`
+	Versions      []secretVersionOutput `json:",omitempty"`
 }
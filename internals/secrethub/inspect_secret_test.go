@@ -8,6 +8,7 @@ import (
 	"github.com/secrethub/secrethub-cli/internals/secrethub/fakes"
 
 	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/api/uuid"
 	"github.com/secrethub/secrethub-go/internals/assert"
 	"github.com/secrethub/secrethub-go/internals/errio"
 	"github.com/secrethub/secrethub-go/pkg/secrethub"
@@ -16,6 +17,7 @@ import (
 
 func TestInspectSecret_Run(t *testing.T) {
 	testErr := errio.Namespace("test").Code("test").Error("test error")
+	secretID := uuid.New()
 
 	cases := map[string]struct {
 		cmd                  InspectSecretCommand
@@ -35,15 +37,24 @@ func TestInspectSecret_Run(t *testing.T) {
 				GetWithoutDataFunc: func(path string) (*api.SecretVersion, error) {
 					return &api.SecretVersion{
 						Secret: &api.Secret{
-							Name:         "secret",
-							CreatedAt:    time.Date(2018, 1, 1, 1, 1, 1, 1, time.UTC),
-							VersionCount: 1,
+							SecretID:      secretID,
+							Name:          "secret",
+							Status:        api.StatusOK,
+							CreatedAt:     time.Date(2018, 1, 1, 1, 1, 1, 1, time.UTC),
+							VersionCount:  1,
+							LatestVersion: 1,
 						},
 						Version:   1,
 						CreatedAt: time.Date(2018, 1, 1, 1, 1, 1, 1, time.UTC),
 						Status:    api.StatusOK,
 					}, nil
 				},
+				GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+					assert.Equal(t, path, "foo/bar/.annotations")
+					return &api.SecretVersion{
+						Data: []byte("secret:\n  owner: team-db\n"),
+					}, nil
+				},
 				ListWithoutDataFunc: func(path string) ([]*api.SecretVersion, error) {
 					return []*api.SecretVersion{
 						{
@@ -61,9 +72,16 @@ func TestInspectSecret_Run(t *testing.T) {
 			},
 			out: "" +
 				"{\n" +
+				"    \"SecretID\": \"" + secretID.String() + "\",\n" +
 				"    \"Name\": \"secret\",\n" +
+				"    \"Path\": \"foo/bar/secret\",\n" +
+				"    \"Status\": \"ok\",\n" +
 				"    \"CreatedAt\": \"2018-01-01T01:01:01+01:00\",\n" +
 				"    \"VersionCount\": 1,\n" +
+				"    \"LatestVersion\": 1,\n" +
+				"    \"Annotations\": {\n" +
+				"        \"owner\": \"team-db\"\n" +
+				"    },\n" +
 				"    \"Versions\": [\n" +
 				"        {\n" +
 				"            \"Version\": 1,\n" +
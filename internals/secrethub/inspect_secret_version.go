@@ -1,9 +1,6 @@
 package secrethub
 
 import (
-	"fmt"
-
-	"github.com/secrethub/secrethub-cli/internals/cli"
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
 
 	"github.com/secrethub/secrethub-go/internals/api"
@@ -39,14 +36,7 @@ func (cmd *InspectSecretVersionCommand) Run() error {
 		return err
 	}
 
-	output, err := cli.PrettyJSON(newSecretVersionOutput(version, cmd.timeFormatter))
-	if err != nil {
-		return err
-	}
-
-	fmt.Fprintln(cmd.io.Output(), output)
-
-	return nil
+	return newPrettyJSONFormatter(cmd.io.Output()).WriteValue(newSecretVersionOutput(version, cmd.timeFormatter))
 }
 
 func newSecretVersionOutput(secret *api.SecretVersion, timeFormatter TimeFormatter) secretVersionOutput {
@@ -14,11 +14,17 @@ import (
 	"github.com/secrethub/secrethub-go/internals/errio"
 )
 
+// Errors
+var (
+	errAnnotationFilterNotDir = errMain.Code("annotation_filter_not_dir").Error("secrets can only be filtered on their annotations when listing a directory")
+)
+
 // LsCommand lists a repo, secret or namespace.
 type LsCommand struct {
 	path          api.Path
 	quiet         bool
 	useTimestamps bool
+	annotations   []string
 	io            ui.IO
 	newClient     newClientFunc
 }
@@ -36,6 +42,7 @@ func (cmd *LsCommand) Register(r cli.Registerer) {
 	clause := r.Command("ls", "List contents of a path.")
 	clause.Alias("list")
 	clause.Flags().BoolVarP(&cmd.quiet, "quiet", "q", false, "Only print paths.")
+	clause.Flags().StringArrayVar(&cmd.annotations, "annotation", nil, "Only list the secrets in a directory that have an annotation with the given `KEY=VALUE`, or with the given key when no value is given. Can be repeated.")
 	registerTimestampFlag(clause, &cmd.useTimestamps)
 
 	clause.BindAction(cmd.Run)
@@ -48,6 +55,14 @@ func (cmd *LsCommand) Register(r cli.Registerer) {
 func (cmd *LsCommand) Run() error {
 	timeFormatter := NewTimeFormatter(cmd.useTimestamps)
 
+	filters, err := parseAnnotationFilters(cmd.annotations)
+	if err != nil {
+		return err
+	}
+	if len(filters) > 0 && (cmd.path == "" || cmd.path.HasVersion()) {
+		return errAnnotationFilterNotDir
+	}
+
 	if cmd.path == "" {
 		repoLSCommand := NewRepoLSCommand(cmd.io, cmd.newClient)
 		repoLSCommand.quiet = cmd.quiet
@@ -90,6 +105,15 @@ func (cmd *LsCommand) Run() error {
 		} else if err != nil && !api.IsErrNotFound(err) {
 			return err
 		} else if err == nil {
+			hideReservedSecrets(dirFS)
+			if len(filters) > 0 {
+				annotations, err := readAnnotations(client, dirPath.Value())
+				if err != nil {
+					return err
+				}
+				filterDirByAnnotations(dirFS.RootDir, annotations, filters)
+			}
+
 			err = printDir(cmd.io.Output(), cmd.quiet, dirFS.RootDir, timeFormatter)
 			if err != nil {
 				return err
@@ -101,6 +125,10 @@ func (cmd *LsCommand) Run() error {
 	// Try SecretPath
 	secretPath, err := cmd.path.ToSecretPath()
 	if err == nil {
+		if len(filters) > 0 {
+			return errAnnotationFilterNotDir
+		}
+
 		versions, err := client.Secrets().Versions().ListWithoutData(secretPath.Value())
 		if api.IsErrNotFound(err) {
 			return ErrResourceNotFound(cmd.path)
@@ -118,6 +146,10 @@ func (cmd *LsCommand) Run() error {
 
 	workspace, err := cmd.path.ToNamespace()
 	if err == nil {
+		if len(filters) > 0 {
+			return errAnnotationFilterNotDir
+		}
+
 		cmd := RepoLSCommand{
 			workspace:     workspace,
 			useTimestamps: cmd.useTimestamps,
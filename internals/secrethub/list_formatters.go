@@ -41,11 +41,28 @@ func toPascalCase(s string) string {
 	return strings.ReplaceAll(strings.Title(s), " ", "")
 }
 
+// newPrettyJSONFormatter returns a formatter that formats values, such as the
+// details of a resource, as indented json.
+func newPrettyJSONFormatter(writer io.Writer) *jsonFormatter {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "    ")
+	return &jsonFormatter{
+		encoder: encoder,
+	}
+}
+
 type jsonFormatter struct {
 	encoder *json.Encoder
 	fields  []string
 }
 
+// WriteValue writes the json representation of the given value.
+// Commands that output a resource use this with the output types in this package,
+// so that the same resource is formatted the same everywhere.
+func (f *jsonFormatter) WriteValue(value interface{}) error {
+	return f.encoder.Encode(value)
+}
+
 // Write writes the json representation of the given row
 // with the configured field names as keys and the provided values
 func (f *jsonFormatter) Write(values []string) error {
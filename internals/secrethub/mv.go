@@ -0,0 +1,40 @@
+package secrethub
+
+import (
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+)
+
+// MvCommand moves secrets and directories.
+type MvCommand struct {
+	transfer secretTransfer
+}
+
+// NewMvCommand creates a new MvCommand.
+func NewMvCommand(io ui.IO, newClient newClientFunc) *MvCommand {
+	return &MvCommand{
+		transfer: secretTransfer{
+			io:        io,
+			newClient: newClient,
+			move:      true,
+		},
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *MvCommand) Register(r cli.Registerer) {
+	clause := r.Command("mv", "Move a secret or directory.")
+	clause.Alias("move")
+	clause.HelpLong("Move a secret or, with -r, a directory and its contents to another path, which can be in another repository. " +
+		"When the destination is an existing directory, the source is moved into it. " +
+		"The source is copied to the destination and removed once everything has been copied. " +
+		"All versions of every secret are replayed in order, so the history of the secrets is kept.\n\n" +
+		"Before moving, the accounts whose access at the destination differs from their access to the source are listed " +
+		"and you are asked to confirm the removal of the source, unless --force is set.")
+	cmd.transfer.register(clause)
+}
+
+// Run moves the secret or directory.
+func (cmd *MvCommand) Run() error {
+	return cmd.transfer.run()
+}
@@ -0,0 +1,407 @@
+package secrethub
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+
+	"github.com/spf13/cobra"
+)
+
+// Errors
+var (
+	ErrOffboardIncomplete = errMain.Code("offboard_incomplete").ErrorPref("offboarding %s is incomplete, as %s failed: see the report for details")
+)
+
+const formatMarkdown = "markdown"
+
+// OffboardCommand revokes a user from all organizations and repositories and reports the secrets to rotate.
+type OffboardCommand struct {
+	io           ui.IO
+	username     cli.StringValue
+	force        bool
+	reportFormat string
+	reportFile   string
+	newClient    newClientFunc
+}
+
+// NewOffboardCommand creates a new OffboardCommand.
+func NewOffboardCommand(io ui.IO, newClient newClientFunc) *OffboardCommand {
+	return &OffboardCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *OffboardCommand) Register(r cli.Registerer) {
+	clause := r.Command("offboard", "Revoke a user from all organizations and repositories and report the secrets that should be rotated.")
+	clause.HelpLong("The user is revoked from every organization you are a member of that the user is also a member of, " +
+		"which revokes the user from all repositories of these organizations, " +
+		"and from every other repository you have access to that the user is a member of. " +
+		"Repositories that you do not have access to are not found.\n\n" +
+		"After a single confirmation, the user is revoked and a report is generated of all secrets that were flagged for rotation, " +
+		"grouped by owner and repository, together with the repositories the user could not be revoked from. " +
+		"When revoking the user from an organization or repository fails, the other revocations still go ahead " +
+		"and the failures are listed in the report.")
+	registerForceFlag(clause, &cmd.force)
+	clause.Flags().StringVar(&cmd.reportFormat, "report-format", formatMarkdown, "Specify the format of the report. Options are: markdown and json.")
+	_ = clause.Cmd.RegisterFlagCompletionFunc("report-format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{formatMarkdown, formatJSON}, cobra.ShellCompDirectiveDefault
+	})
+	clause.Flags().StringVar(&cmd.reportFile, "report-file", "", "Write the report to this file instead of printing it.")
+
+	clause.BindAction(cmd.Run)
+	clause.BindArguments([]cli.Argument{
+		{Value: &cmd.username, Name: "username", Required: true, Description: "The username of the user to offboard."},
+	})
+}
+
+// offboardReport lists the secrets to rotate after offboarding a user.
+type offboardReport struct {
+	Username      string            `json:"username"`
+	Organizations []string          `json:"organizations"`
+	Repositories  []string          `json:"repositories"`
+	Owners        []offboardOwner   `json:"owners"`
+	Failures      []offboardFailure `json:"failures,omitempty"`
+}
+
+// offboardFailure is an organization or repository the user could not be revoked from.
+type offboardFailure struct {
+	Scope string `json:"scope"`
+	Error string `json:"error"`
+}
+
+// offboardOwner contains the repositories of an owner that need attention.
+type offboardOwner struct {
+	Owner        string         `json:"owner"`
+	Repositories []offboardRepo `json:"repositories"`
+}
+
+// offboardRepo contains the secrets of a repository that are flagged for rotation.
+type offboardRepo struct {
+	Path           string           `json:"path"`
+	Status         string           `json:"status"`
+	FlaggedSecrets []offboardSecret `json:"flagged_secrets"`
+	// Error is set when the flagged secrets of the repository could not be listed.
+	Error string `json:"error,omitempty"`
+}
+
+// offboardSecret is a secret that is flagged for rotation.
+type offboardSecret struct {
+	Path   string `json:"path"`
+	Status string `json:"status"`
+}
+
+// Run revokes the user and reports the secrets to rotate.
+func (cmd *OffboardCommand) Run() error {
+	if cmd.reportFormat != formatMarkdown && cmd.reportFormat != formatJSON {
+		return errNoSuchFormat(cmd.reportFormat)
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	username := cmd.username.Value
+	orgs, repos, err := offboardMemberships(client, username)
+	if err != nil {
+		return err
+	}
+
+	if len(orgs) == 0 && len(repos) == 0 {
+		fmt.Fprintf(cmd.io.Output(), "The user %s is not a member of any organization or repository you have access to.\n", username)
+		return nil
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "[WARNING] Offboarding %s will revoke the user from:\n\n", username)
+	for _, org := range orgs {
+		planned, err := client.Orgs().Members().Revoke(org, username, &api.RevokeOpts{DryRun: true})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.io.Output(), "  the %s organization and its %s\n", org, pluralize("repository", "repositories", len(planned.Repos)))
+	}
+	for _, repo := range repos {
+		fmt.Fprintf(cmd.io.Output(), "  the %s repository\n", repo)
+	}
+	fmt.Fprint(cmd.io.Output(), "\nSecrets the user had access to will be flagged for rotation.\n\n")
+
+	if !cmd.force {
+		confirmed, err := ui.ConfirmCaseInsensitive(
+			cmd.io,
+			"Please type in the username of the user to confirm and proceed with offboarding",
+			username,
+		)
+		if err == ui.ErrCannotAsk {
+			return ErrCannotDoWithoutForce
+		} else if err != nil {
+			return err
+		}
+
+		if !confirmed {
+			fmt.Fprintln(cmd.io.Output(), "Name does not match. Aborting.")
+			return nil
+		}
+	}
+
+	fmt.Fprint(cmd.io.Output(), "\nRevoking user...\n\n")
+
+	report := offboardReport{
+		Username:      username,
+		Organizations: []string{},
+		Repositories:  []string{},
+		Owners:        []offboardOwner{},
+	}
+
+	failed := 0
+	var revoked []*api.RevokeRepoResponse
+	for _, org := range orgs {
+		resp, err := client.Orgs().Members().Revoke(org, username, nil)
+		if err != nil {
+			failed++
+			report.Failures = append(report.Failures, offboardFailure{Scope: org, Error: err.Error()})
+			fmt.Fprintf(cmd.io.Output(), "Could not revoke the user from the %s organization: %v\n", org, err)
+			continue
+		}
+		report.Organizations = append(report.Organizations, org)
+		revoked = append(revoked, resp.Repos...)
+	}
+	for _, repo := range repos {
+		resp, err := client.Repos().Users().Revoke(repo.Value(), username)
+		if err != nil {
+			failed++
+			report.Failures = append(report.Failures, offboardFailure{Scope: repo.Value(), Error: err.Error()})
+			fmt.Fprintf(cmd.io.Output(), "Could not revoke the user from the %s repository: %v\n", repo, err)
+			continue
+		}
+		report.Repositories = append(report.Repositories, repo.Value())
+		namespace, name := repo.GetNamespaceAndRepoName()
+		resp.Namespace = namespace
+		resp.Name = name
+		revoked = append(revoked, resp)
+	}
+	if failed > 0 {
+		fmt.Fprintln(cmd.io.Output())
+	}
+
+	statusCounts := make(map[string]int)
+	secretCount := 0
+	unlisted := 0
+	for _, resp := range revoked {
+		statusCounts[resp.Status]++
+		if resp.Status == api.StatusOK {
+			continue
+		}
+
+		repoPath := api.RepoPath(resp.Namespace + "/" + resp.Name)
+		repo := offboardRepo{
+			Path:           repoPath.Value(),
+			Status:         resp.Status,
+			FlaggedSecrets: []offboardSecret{},
+		}
+		if resp.Status == api.StatusFlagged {
+			tree, err := client.Dirs().GetTree(repoPath.GetDirPath().Value(), -1, false)
+			if err != nil {
+				unlisted++
+				repo.Error = err.Error()
+			} else {
+				_, flagged := collectFlaggedSecrets(tree.RootDir, resp.Namespace)
+				for _, secret := range flagged {
+					repo.FlaggedSecrets = append(repo.FlaggedSecrets, offboardSecret{Path: secret.path, Status: secret.status})
+				}
+				secretCount += len(flagged)
+			}
+		}
+
+		if len(report.Owners) == 0 || !strings.EqualFold(report.Owners[len(report.Owners)-1].Owner, resp.Namespace) {
+			report.Owners = append(report.Owners, offboardOwner{Owner: resp.Namespace})
+		}
+		owner := &report.Owners[len(report.Owners)-1]
+		owner.Repositories = append(owner.Repositories, repo)
+	}
+
+	if len(revoked) > 0 {
+		err = writeOrgRevokeRepoList(cmd.io.Output(), revoked...)
+		if err != nil {
+			return err
+		}
+	}
+	result := "complete"
+	if failed > 0 || unlisted > 0 {
+		result = "incomplete"
+	}
+	fmt.Fprintf(
+		cmd.io.Output(),
+		"Offboarding %s! Repositories: %d flagged, %d failed, %d OK. Secrets to rotate: %d.\n",
+		result,
+		statusCounts[api.StatusFlagged],
+		statusCounts[api.StatusFailed],
+		statusCounts[api.StatusOK],
+		secretCount,
+	)
+
+	var incomplete error
+	if failed > 0 && unlisted > 0 {
+		incomplete = ErrOffboardIncomplete(username, fmt.Sprintf("%s and listing the flagged secrets of %s", pluralize("revocation", "revocations", failed), pluralize("repository", "repositories", unlisted)))
+	} else if failed > 0 {
+		incomplete = ErrOffboardIncomplete(username, pluralize("revocation", "revocations", failed))
+	} else if unlisted > 0 {
+		incomplete = ErrOffboardIncomplete(username, "listing the flagged secrets of "+pluralize("repository", "repositories", unlisted))
+	}
+
+	var out bytes.Buffer
+	if cmd.reportFormat == formatJSON {
+		err = newPrettyJSONFormatter(&out).WriteValue(report)
+	} else {
+		err = writeOffboardMarkdown(&out, report)
+	}
+	if err != nil {
+		return err
+	}
+
+	if cmd.reportFile != "" {
+		err = ioutil.WriteFile(cmd.reportFile, out.Bytes(), 0600)
+		if err != nil {
+			return ErrCannotWrite(cmd.reportFile, err)
+		}
+		fmt.Fprintf(cmd.io.Output(), "The report has been written to %s.\n", cmd.reportFile)
+		return incomplete
+	}
+
+	fmt.Fprintln(cmd.io.Output())
+	_, err = cmd.io.Output().Write(out.Bytes())
+	if err != nil {
+		return err
+	}
+	return incomplete
+}
+
+// offboardMemberships returns the organizations and the repositories outside these organizations
+// that the user is a member of, as far as they are visible to the client.
+// The repositories of an organization are left out, as revoking the user from the organization
+// also revokes the user from its repositories.
+func offboardMemberships(client secrethub.ClientInterface, username string) ([]string, []api.RepoPath, error) {
+	myOrgs, err := client.Orgs().ListMine()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	isOrg := make(map[string]bool, len(myOrgs))
+	var orgs []string
+	for _, org := range myOrgs {
+		isOrg[strings.ToLower(org.Name)] = true
+
+		members, err := client.Orgs().Members().List(org.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, member := range members {
+			if member.User != nil && strings.EqualFold(member.User.Username, username) {
+				orgs = append(orgs, org.Name)
+				break
+			}
+		}
+	}
+	sort.Strings(orgs)
+
+	myRepos, err := client.Repos().ListMine()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var repos []api.RepoPath
+	for _, repo := range myRepos {
+		// Users cannot be revoked from the repositories in their own namespace.
+		if isOrg[strings.ToLower(repo.Owner)] || strings.EqualFold(repo.Owner, username) {
+			continue
+		}
+
+		users, err := client.Repos().Users().List(repo.Path().Value())
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, user := range users {
+			if strings.EqualFold(user.Username, username) {
+				repos = append(repos, repo.Path())
+				break
+			}
+		}
+	}
+	sort.Slice(repos, func(i, j int) bool {
+		return strings.ToLower(repos[i].Value()) < strings.ToLower(repos[j].Value())
+	})
+
+	return orgs, repos, nil
+}
+
+// writeOffboardMarkdown writes the report as a Markdown document.
+func writeOffboardMarkdown(w io.Writer, report offboardReport) error {
+	fmt.Fprintf(w, "# Offboarding report for %s\n\n", report.Username)
+
+	var scopes []string
+	for _, org := range report.Organizations {
+		scopes = append(scopes, fmt.Sprintf("the %s organization", org))
+	}
+	for _, repo := range report.Repositories {
+		scopes = append(scopes, fmt.Sprintf("the %s repository", repo))
+	}
+	if len(scopes) > 0 {
+		fmt.Fprintf(w, "Revoked from %s.\n", strings.Join(scopes, ", "))
+	} else {
+		fmt.Fprintln(w, "Not revoked from any organization or repository.")
+	}
+
+	if len(report.Failures) > 0 {
+		fmt.Fprint(w, "\n## Failures\n\n")
+		fmt.Fprintf(w, "Revoking %s failed for the following organizations and repositories. Retry offboarding the user to revoke the user from them.\n\n", report.Username)
+		for _, failure := range report.Failures {
+			fmt.Fprintf(w, "- %s: %s\n", failure.Scope, failure.Error)
+		}
+	}
+
+	if len(report.Owners) == 0 {
+		fmt.Fprint(w, "\nNo secrets have to be rotated.\n")
+		return nil
+	}
+
+	for _, owner := range report.Owners {
+		fmt.Fprintf(w, "\n## %s\n", owner.Owner)
+		for _, repo := range owner.Repositories {
+			fmt.Fprintf(w, "\n### %s\n\n", repo.Path)
+			if repo.Status == api.StatusFailed {
+				fmt.Fprintf(w, "Revoking %s failed, as the user is the only admin of the repository. "+
+					"Make sure another account has admin rights on the repository or remove the repository.\n", report.Username)
+				continue
+			}
+			if repo.Error != "" {
+				fmt.Fprintf(w, "The flagged secrets could not be listed: %s. "+
+					"Check the repository for secrets that are flagged for rotation.\n", repo.Error)
+				continue
+			}
+
+			tw := tabwriter.NewWriter(w, 0, 4, 4, ' ', 0)
+			fmt.Fprintln(tw, "| Secret\t| Status\t|")
+			fmt.Fprintln(tw, "| ---\t| ---\t|")
+			for _, secret := range repo.FlaggedSecrets {
+				fmt.Fprintf(tw, "| %s\t| %s\t|\n", secret.Path, secret.Status)
+			}
+			err := tw.Flush()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,313 @@
+package secrethub
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestOffboardCommand_Run(t *testing.T) {
+	warning := "[WARNING] Offboarding dev1 will revoke the user from:\n" +
+		"\n" +
+		"  the company organization and its 2 repositories\n" +
+		"  the dev2/tools repository\n" +
+		"\n" +
+		"Secrets the user had access to will be flagged for rotation.\n" +
+		"\n"
+	revoking := "\n" +
+		"Revoking user...\n" +
+		"\n" +
+		"  company/app  => flagged\n" +
+		"  company/web  => ok\n" +
+		"  dev2/tools   => failed\n" +
+		"\n" +
+		"Offboarding complete! Repositories: 1 flagged, 1 failed, 1 OK. Secrets to rotate: 2.\n"
+	prompt := "Please type in the username of the user to confirm and proceed with offboarding: "
+
+	cases := map[string]struct {
+		cmd       OffboardCommand
+		in        string
+		askErr    error
+		revokeErr error
+		members   []string
+		reportOut string
+		out       string
+		promptOut string
+		revoked   []string
+		err       error
+	}{
+		"markdown": {
+			cmd: OffboardCommand{
+				reportFormat: formatMarkdown,
+			},
+			in:      "Dev1",
+			members: []string{"dev1", "dev2"},
+			out: warning + revoking +
+				"\n" +
+				"# Offboarding report for dev1\n" +
+				"\n" +
+				"Revoked from the company organization, the dev2/tools repository.\n" +
+				"\n" +
+				"## company\n" +
+				"\n" +
+				"### company/app\n" +
+				"\n" +
+				"| Secret                    | Status     |\n" +
+				"| ---                       | ---        |\n" +
+				"| company/app/prod/token    | flagged    |\n" +
+				"| company/app/db            | flagged    |\n" +
+				"\n" +
+				"## dev2\n" +
+				"\n" +
+				"### dev2/tools\n" +
+				"\n" +
+				"Revoking dev1 failed, as the user is the only admin of the repository. " +
+				"Make sure another account has admin rights on the repository or remove the repository.\n",
+			promptOut: prompt,
+			revoked:   []string{"company", "dev2/tools"},
+		},
+		"json report file": {
+			cmd: OffboardCommand{
+				reportFormat: formatJSON,
+				force:        true,
+			},
+			members: []string{"dev1"},
+			out:     warning + revoking + "The report has been written to <file>.\n",
+			reportOut: `{
+    "username": "dev1",
+    "organizations": [
+        "company"
+    ],
+    "repositories": [
+        "dev2/tools"
+    ],
+    "owners": [
+        {
+            "owner": "company",
+            "repositories": [
+                {
+                    "path": "company/app",
+                    "status": "flagged",
+                    "flagged_secrets": [
+                        {
+                            "path": "company/app/prod/token",
+                            "status": "flagged"
+                        },
+                        {
+                            "path": "company/app/db",
+                            "status": "flagged"
+                        }
+                    ]
+                }
+            ]
+        },
+        {
+            "owner": "dev2",
+            "repositories": [
+                {
+                    "path": "dev2/tools",
+                    "status": "failed",
+                    "flagged_secrets": []
+                }
+            ]
+        }
+    ]
+}
+`,
+			revoked: []string{"company", "dev2/tools"},
+		},
+		"revoke fails": {
+			cmd: OffboardCommand{
+				reportFormat: formatMarkdown,
+				force:        true,
+			},
+			revokeErr: errors.New("connection reset"),
+			members:   []string{"dev1"},
+			out: warning +
+				"\n" +
+				"Revoking user...\n" +
+				"\n" +
+				"Could not revoke the user from the company organization: connection reset\n" +
+				"\n" +
+				"  dev2/tools  => failed\n" +
+				"\n" +
+				"Offboarding incomplete! Repositories: 0 flagged, 1 failed, 0 OK. Secrets to rotate: 0.\n" +
+				"\n" +
+				"# Offboarding report for dev1\n" +
+				"\n" +
+				"Revoked from the dev2/tools repository.\n" +
+				"\n" +
+				"## Failures\n" +
+				"\n" +
+				"Revoking dev1 failed for the following organizations and repositories. " +
+				"Retry offboarding the user to revoke the user from them.\n" +
+				"\n" +
+				"- company: connection reset\n" +
+				"\n" +
+				"## dev2\n" +
+				"\n" +
+				"### dev2/tools\n" +
+				"\n" +
+				"Revoking dev1 failed, as the user is the only admin of the repository. " +
+				"Make sure another account has admin rights on the repository or remove the repository.\n",
+			revoked: []string{"dev2/tools"},
+			err:     ErrOffboardIncomplete("dev1", "1 revocation"),
+		},
+		"abort": {
+			cmd: OffboardCommand{
+				reportFormat: formatMarkdown,
+			},
+			in:        "typo",
+			members:   []string{"dev1"},
+			out:       warning + "Name does not match. Aborting.\n",
+			promptOut: prompt,
+		},
+		"cannot ask": {
+			cmd: OffboardCommand{
+				reportFormat: formatMarkdown,
+			},
+			askErr:  ui.ErrCannotAsk,
+			members: []string{"dev1"},
+			out:     warning,
+			err:     ErrCannotDoWithoutForce,
+		},
+		"not a member": {
+			cmd: OffboardCommand{
+				username:     cli.StringValue{Value: "intern"},
+				reportFormat: formatMarkdown,
+			},
+			members: []string{"dev1"},
+			out:     "The user intern is not a member of any organization or repository you have access to.\n",
+		},
+		"invalid format": {
+			cmd: OffboardCommand{
+				reportFormat: "yaml",
+			},
+			err: errNoSuchFormat("yaml"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			dir, cleanup := testdata.tempDir(t)
+			defer cleanup()
+			if tc.reportOut != "" {
+				tc.cmd.reportFile = filepath.Join(dir, "report.json")
+			}
+			if tc.cmd.username.Value == "" {
+				tc.cmd.username = cli.StringValue{Value: "dev1"}
+			}
+
+			var revoked []string
+			tc.cmd.newClient = func() (secrethub.ClientInterface, error) {
+				return fakeclient.Client{
+					OrgService: &fakeclient.OrgService{
+						ListMineFunc: func() ([]*api.Org, error) {
+							return []*api.Org{{Name: "company"}}, nil
+						},
+						MembersService: &fakeclient.OrgMemberService{
+							ListFunc: func(org string) ([]*api.OrgMember, error) {
+								var members []*api.OrgMember
+								for _, username := range tc.members {
+									members = append(members, &api.OrgMember{User: &api.User{Username: username}})
+								}
+								return members, nil
+							},
+							RevokeFunc: func(org string, username string, opts *api.RevokeOpts) (*api.RevokeOrgResponse, error) {
+								if opts == nil || !opts.DryRun {
+									if tc.revokeErr != nil {
+										return nil, tc.revokeErr
+									}
+									revoked = append(revoked, org)
+								}
+								return &api.RevokeOrgResponse{
+									Repos: []*api.RevokeRepoResponse{
+										{Namespace: "company", Name: "app", Status: api.StatusFlagged},
+										{Namespace: "company", Name: "web", Status: api.StatusOK},
+									},
+								}, nil
+							},
+						},
+					},
+					RepoService: &fakeclient.RepoService{
+						ListMineFunc: func() ([]*api.Repo, error) {
+							return []*api.Repo{
+								{Owner: "company", Name: "app"},
+								{Owner: "dev2", Name: "tools"},
+								{Owner: "dev1", Name: "notes"},
+							}, nil
+						},
+						UserService: &fakeclient.RepoUserService{
+							ListFunc: func(path string) ([]*api.User, error) {
+								if path != "dev2/tools" {
+									return []*api.User{{Username: "dev1"}}, nil
+								}
+								return []*api.User{{Username: "dev2"}, {Username: "DEV1"}}, nil
+							},
+							RevokeFunc: func(path string, username string) (*api.RevokeRepoResponse, error) {
+								revoked = append(revoked, path)
+								return &api.RevokeRepoResponse{Status: api.StatusFailed}, nil
+							},
+						},
+					},
+					DirService: &fakeclient.DirService{
+						GetTreeFunc: func(path string, depth int, ancestors bool) (*api.Tree, error) {
+							assert.Equal(t, path, "company/app")
+							return &api.Tree{
+								RootDir: &api.Dir{
+									Name: "app",
+									SubDirs: []*api.Dir{
+										{
+											Name:    "prod",
+											Secrets: []*api.Secret{{Name: "token", Status: api.StatusFlagged}},
+										},
+									},
+									Secrets: []*api.Secret{
+										{Name: "db", Status: api.StatusFlagged},
+										{Name: "readme", Status: api.StatusOK},
+									},
+								},
+							}, nil
+						},
+					},
+				}, nil
+			}
+
+			io := fakeui.NewIO(t)
+			io.PromptIn.Buffer = bytes.NewBufferString(tc.in)
+			io.PromptErr = tc.askErr
+			tc.cmd.io = io
+
+			// Act
+			err := tc.cmd.Run()
+
+			// Assert
+			assert.Equal(t, err, tc.err)
+			out := io.Out.String()
+			if tc.cmd.reportFile != "" {
+				out = strings.ReplaceAll(out, tc.cmd.reportFile, "<file>")
+			}
+			assert.Equal(t, out, tc.out)
+			assert.Equal(t, io.PromptOut.String(), tc.promptOut)
+			assert.Equal(t, revoked, tc.revoked)
+			if tc.reportOut != "" {
+				report, err := ioutil.ReadFile(tc.cmd.reportFile)
+				assert.OK(t, err)
+				assert.Equal(t, string(report), tc.reportOut)
+			}
+		})
+	}
+}
@@ -33,7 +33,9 @@ func (cmd *OrgCommand) Register(r cli.Registerer) {
 	NewOrgPurchaseCommand(cmd.io).Register(clause)
 	NewOrgListUsersCommand(cmd.io, cmd.newClient).Register(clause)
 	NewOrgLsCommand(cmd.io, cmd.newClient).Register(clause)
+	NewOrgReportCommand(cmd.io, cmd.newClient).Register(clause)
 	NewOrgRevokeCommand(cmd.io, cmd.newClient).Register(clause)
 	NewOrgRmCommand(cmd.io, cmd.newClient).Register(clause)
 	NewOrgSetRoleCommand(cmd.io, cmd.newClient).Register(clause)
+	NewOrgSyncCommand(cmd.io, cmd.newClient).Register(clause)
 }
@@ -0,0 +1,273 @@
+package secrethub
+
+import (
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/iterator"
+
+	"github.com/spf13/cobra"
+)
+
+// OrgReportCommand prints who can access the repositories of an organization.
+type OrgReportCommand struct {
+	io            ui.IO
+	orgName       api.OrgName
+	format        string
+	useTimestamps bool
+	timeFormatter TimeFormatter
+	newClient     newClientFunc
+}
+
+// NewOrgReportCommand creates a new OrgReportCommand.
+func NewOrgReportCommand(io ui.IO, newClient newClientFunc) *OrgReportCommand {
+	return &OrgReportCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *OrgReportCommand) Register(r cli.Registerer) {
+	clause := r.Command("report", "Show the members, admins, service accounts, last activity and number of secrets of every repository in an organization.")
+	clause.HelpLong("The admins of a repository are the accounts with admin permission on its root directory. " +
+		"Repositories with a single admin are pointed out, as that admin cannot be revoked from the repository " +
+		"until another account has admin permission on it.\n\n" +
+		"When the details of a repository cannot be collected, for example because you have no access to it, " +
+		"the error is shown for that repository and the report continues with the other repositories.")
+	clause.Flags().StringVar(&cmd.format, "output-format", formatTable, "Specify the format in which to output the report. Options are: table, json and csv.")
+	_ = clause.Cmd.RegisterFlagCompletionFunc("output-format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{formatTable, formatJSON, formatCSV}, cobra.ShellCompDirectiveDefault
+	})
+	registerTimestampFlag(clause, &cmd.useTimestamps)
+
+	clause.BindAction(cmd.Run)
+	clause.BindArguments([]cli.Argument{
+		{Value: &cmd.orgName, Name: "org-name", Required: true, Description: "The organization name."},
+	})
+}
+
+// Run prints the access report of the organization.
+func (cmd *OrgReportCommand) Run() error {
+	cmd.beforeRun()
+	return cmd.run()
+}
+
+// beforeRun configures the command using the flag values.
+func (cmd *OrgReportCommand) beforeRun() {
+	cmd.timeFormatter = NewTimeFormatter(cmd.useTimestamps)
+}
+
+// orgReportRow contains the access details of a repository.
+type orgReportRow struct {
+	path         string
+	members      int
+	admins       []string
+	services     []string
+	secrets      int
+	lastActivity time.Time
+	// error is set when the access details of the repository could not be collected.
+	error string
+}
+
+// orgReportOutput is the JSON representation of the access details of a repository.
+type orgReportOutput struct {
+	Repo         string   `json:"repo"`
+	MemberCount  int      `json:"member_count"`
+	Admins       []string `json:"admins"`
+	Services     []string `json:"services"`
+	SecretCount  int      `json:"secret_count"`
+	LastActivity string   `json:"last_activity,omitempty"`
+	SingleAdmin  bool     `json:"single_admin"`
+	Error        string   `json:"error,omitempty"`
+}
+
+// run prints the access report of the organization.
+func (cmd *OrgReportCommand) run() error {
+	if cmd.format != formatTable && cmd.format != formatJSON && cmd.format != formatCSV {
+		return errNoSuchFormat(cmd.format)
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	repos, err := client.Repos().List(cmd.orgName.Value())
+	if err != nil {
+		return err
+	}
+	sort.Sort(api.SortRepoByName(repos))
+
+	rows := make([]orgReportRow, len(repos))
+	hasErrors := false
+	for i, repo := range repos {
+		rows[i], err = orgReportRepo(client, repo)
+		if err != nil {
+			// An org admin does not necessarily have access to every repository,
+			// so the report continues with the other repositories.
+			rows[i].error = err.Error()
+			hasErrors = true
+		}
+	}
+
+	switch cmd.format {
+	case formatJSON:
+		output := make([]orgReportOutput, len(rows))
+		for i, row := range rows {
+			output[i] = orgReportOutput{
+				Repo:        row.path,
+				MemberCount: row.members,
+				Admins:      row.admins,
+				Services:    row.services,
+				SecretCount: row.secrets,
+				SingleAdmin: len(row.admins) == 1,
+				Error:       row.error,
+			}
+			if !row.lastActivity.IsZero() {
+				output[i].LastActivity = row.lastActivity.UTC().Format(time.RFC3339)
+			}
+		}
+		return newPrettyJSONFormatter(cmd.io.Output()).WriteValue(output)
+	case formatCSV:
+		w := csv.NewWriter(cmd.io.Output())
+		err = w.Write([]string{"repo", "members", "admins", "services", "secrets", "last_activity", "single_admin", "error"})
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			lastActivity := ""
+			if !row.lastActivity.IsZero() {
+				lastActivity = row.lastActivity.UTC().Format(time.RFC3339)
+			}
+			err = w.Write([]string{
+				row.path,
+				strconv.Itoa(row.members),
+				strings.Join(row.admins, " "),
+				strings.Join(row.services, " "),
+				strconv.Itoa(row.secrets),
+				lastActivity,
+				strconv.FormatBool(len(row.admins) == 1),
+				row.error,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		w.Flush()
+		return w.Error()
+	}
+
+	if len(rows) == 0 {
+		fmt.Fprintf(cmd.io.Output(), "The %s organization has no repositories.\n", cmd.orgName)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(cmd.io.Output(), 0, 2, 2, ' ', 0)
+	header := "REPO\tMEMBERS\tADMINS\tSERVICES\tSECRETS\tLAST ACTIVITY"
+	if hasErrors {
+		header += "\tERROR"
+	}
+	fmt.Fprintln(w, header)
+	var singleAdmin []string
+	for _, row := range rows {
+		if row.error != "" {
+			fmt.Fprintf(w, "%s\t-\t-\t-\t%d\t-\t%s\n", row.path, row.secrets, row.error)
+			continue
+		}
+
+		admins := strings.Join(row.admins, ", ")
+		if admins == "" {
+			admins = "-"
+		}
+		lastActivity := "-"
+		if !row.lastActivity.IsZero() {
+			lastActivity = cmd.timeFormatter.Format(row.lastActivity.Local())
+		}
+		line := fmt.Sprintf("%s\t%d\t%s\t%d\t%d\t%s", row.path, row.members, admins, len(row.services), row.secrets, lastActivity)
+		if hasErrors {
+			line += "\t-"
+		}
+		fmt.Fprintln(w, line)
+
+		if len(row.admins) == 1 {
+			singleAdmin = append(singleAdmin, row.path)
+		}
+	}
+	err = w.Flush()
+	if err != nil {
+		return err
+	}
+
+	if len(singleAdmin) > 0 {
+		pronoun := "them"
+		if len(singleAdmin) == 1 {
+			pronoun = "it"
+		}
+		fmt.Fprintf(
+			cmd.io.Output(),
+			"\n[WARNING] %s a single admin: %s. Give another account admin permission on %s, so that the admin can be revoked.\n",
+			pluralize("repository has", "repositories have", len(singleAdmin)),
+			strings.Join(singleAdmin, ", "),
+			pronoun,
+		)
+	}
+	return nil
+}
+
+// orgReportRepo collects the access details of a repository.
+func orgReportRepo(client secrethub.ClientInterface, repo *api.Repo) (orgReportRow, error) {
+	path := repo.Path().Value()
+	row := orgReportRow{
+		path:     path,
+		secrets:  repo.SecretCount,
+		admins:   []string{},
+		services: []string{},
+	}
+
+	users, err := client.Repos().Users().List(path)
+	if err != nil {
+		return row, err
+	}
+	row.members = len(users)
+
+	services, err := client.Repos().Services().List(path)
+	if err != nil {
+		return row, err
+	}
+	for _, service := range services {
+		row.services = append(row.services, service.ServiceID)
+	}
+	sort.Strings(row.services)
+
+	levels, err := client.AccessRules().ListLevels(path)
+	if err != nil {
+		return row, err
+	}
+	for _, level := range levels {
+		if level.Permission >= api.PermissionAdmin {
+			row.admins = append(row.admins, level.Account.Name.Value())
+		}
+	}
+	sort.Strings(row.admins)
+
+	// The events are returned most recent first.
+	event, err := client.Repos().EventIterator(path, &secrethub.AuditEventIteratorParams{}).Next()
+	if err == nil {
+		row.lastActivity = event.LoggedAt
+	} else if err != iterator.Done {
+		return row, err
+	}
+
+	return row, nil
+}
@@ -0,0 +1,181 @@
+package secrethub
+
+import (
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/fakes"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestOrgReportCommand_run(t *testing.T) {
+	repos := []*api.Repo{
+		{Owner: "company", Name: "web"},
+		{Owner: "company", Name: "api", SecretCount: 5},
+	}
+	users := map[string][]*api.User{
+		"company/api": {{Username: "owner"}, {Username: "dev1"}, {Username: "dev2"}},
+		"company/web": {{Username: "owner"}},
+	}
+	services := map[string][]*api.Service{
+		"company/api": {{ServiceID: "s-Zp3hGk9sVxQa"}},
+	}
+	levels := map[string][]*api.AccessLevel{
+		"company/api": {
+			{Account: &api.Account{Name: "owner"}, Permission: api.PermissionAdmin},
+			{Account: &api.Account{Name: "dev1"}, Permission: api.PermissionAdmin},
+			{Account: &api.Account{Name: "dev2"}, Permission: api.PermissionWrite},
+			{Account: &api.Account{Name: "s-Zp3hGk9sVxQa"}, Permission: api.PermissionRead},
+		},
+		"company/web": {
+			{Account: &api.Account{Name: "owner"}, Permission: api.PermissionAdmin},
+		},
+	}
+	events := map[string][]api.Audit{
+		"company/api": {
+			{Action: api.AuditActionRead, LoggedAt: time.Date(2026, 5, 20, 8, 0, 0, 0, time.UTC)},
+			{Action: api.AuditActionCreate, LoggedAt: time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC)},
+		},
+	}
+
+	cases := map[string]struct {
+		format string
+		repos  []*api.Repo
+		out    string
+		err    error
+	}{
+		"table": {
+			format: formatTable,
+			repos:  repos,
+			out: "REPO         MEMBERS  ADMINS       SERVICES  SECRETS  LAST ACTIVITY\n" +
+				"company/api  3        dev1, owner  1         5        2 weeks ago\n" +
+				"company/web  1        owner        0         0        -\n" +
+				"\n" +
+				"[WARNING] 1 repository has a single admin: company/web. Give another account admin permission on it, so that the admin can be revoked.\n",
+		},
+		"json": {
+			format: formatJSON,
+			repos:  repos,
+			out: `[
+    {
+        "repo": "company/api",
+        "member_count": 3,
+        "admins": [
+            "dev1",
+            "owner"
+        ],
+        "services": [
+            "s-Zp3hGk9sVxQa"
+        ],
+        "secret_count": 5,
+        "last_activity": "2026-05-20T08:00:00Z",
+        "single_admin": false
+    },
+    {
+        "repo": "company/web",
+        "member_count": 1,
+        "admins": [
+            "owner"
+        ],
+        "services": [],
+        "secret_count": 0,
+        "single_admin": true
+    }
+]
+`,
+		},
+		"csv": {
+			format: formatCSV,
+			repos:  repos,
+			out: "repo,members,admins,services,secrets,last_activity,single_admin,error\n" +
+				"company/api,3,dev1 owner,s-Zp3hGk9sVxQa,5,2026-05-20T08:00:00Z,false,\n" +
+				"company/web,1,owner,,0,,true,\n",
+		},
+		"repository without access": {
+			format: formatTable,
+			repos: []*api.Repo{
+				{Owner: "company", Name: "api", SecretCount: 5},
+				{Owner: "company", Name: "legacy", SecretCount: 2},
+			},
+			out: "REPO            MEMBERS  ADMINS       SERVICES  SECRETS  LAST ACTIVITY  ERROR\n" +
+				"company/api     3        dev1, owner  1         5        2 weeks ago    -\n" +
+				"company/legacy  -        -            -         2        -              " + api.ErrForbidden.Error() + "\n",
+		},
+		"repository without access csv": {
+			format: formatCSV,
+			repos: []*api.Repo{
+				{Owner: "company", Name: "legacy", SecretCount: 2},
+			},
+			out: "repo,members,admins,services,secrets,last_activity,single_admin,error\n" +
+				"company/legacy,0,,,2,,false," + api.ErrForbidden.Error() + "\n",
+		},
+		"no repositories": {
+			format: formatTable,
+			out:    "The company organization has no repositories.\n",
+		},
+		"invalid format": {
+			format: "yaml",
+			err:    errNoSuchFormat("yaml"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			io := fakeui.NewIO(t)
+			cmd := OrgReportCommand{
+				io:            io,
+				orgName:       "company",
+				format:        tc.format,
+				timeFormatter: &fakes.TimeFormatter{Response: "2 weeks ago"},
+				newClient: func() (secrethub.ClientInterface, error) {
+					return auditOrgClient{
+						Client: fakeclient.Client{
+							AccessRuleService: &fakeclient.AccessRuleService{
+								ListLevelsFunc: func(path string) ([]*api.AccessLevel, error) {
+									return levels[path], nil
+								},
+							},
+						},
+						repos: auditOrgRepoService{
+							RepoService: &fakeclient.RepoService{
+								ListFunc: func(namespace string) ([]*api.Repo, error) {
+									assert.Equal(t, namespace, "company")
+									return tc.repos, nil
+								},
+								UserService: &fakeclient.RepoUserService{
+									ListFunc: func(path string) ([]*api.User, error) {
+										if path == "company/legacy" {
+											return nil, api.ErrForbidden
+										}
+										return users[path], nil
+									},
+								},
+								RepoServiceService: &fakeclient.RepoServiceService{
+									ListFunc: func(path string) ([]*api.Service, error) {
+										return services[path], nil
+									},
+								},
+							},
+							events: func(path string) []api.Audit {
+								return events[path]
+							},
+						},
+					}, nil
+				},
+			}
+
+			// Act
+			err := cmd.run()
+
+			// Assert
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, io.Out.String(), tc.out)
+		})
+	}
+}
@@ -0,0 +1,381 @@
+package secrethub
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Errors
+var (
+	ErrInvalidMembersFile  = errMain.Code("invalid_members_file").ErrorPref("invalid members file %s: %v")
+	ErrOrgSyncWithoutAdmin = errMain.Code("org_sync_without_admin").ErrorPref("the members file leaves the %s organization without an admin: give at least one member the admin role")
+)
+
+const defaultMembersFile = "members.yml"
+
+// OrgSyncCommand makes the members of an organization match a members file.
+type OrgSyncCommand struct {
+	io        ui.IO
+	orgName   api.OrgName
+	file      string
+	apply     bool
+	force     bool
+	newClient newClientFunc
+}
+
+// NewOrgSyncCommand creates a new OrgSyncCommand.
+func NewOrgSyncCommand(io ui.IO, newClient newClientFunc) *OrgSyncCommand {
+	return &OrgSyncCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *OrgSyncCommand) Register(r cli.Registerer) {
+	clause := r.Command("sync", "Invite, update and revoke the members of an organization to match a members file.")
+	clause.HelpLong("The members file lists the users that should be a member of the organization, together with their role. " +
+		"Files ending in .csv are read as CSV with a header row containing the columns username and role:\n\n" +
+		"  username,role\n" +
+		"  developer,member\n" +
+		"  lead,admin\n\n" +
+		"All other files are read as YAML:\n\n" +
+		"  members:\n" +
+		"    - username: developer\n" +
+		"      role: member     # admin or member\n" +
+		"    - username: lead\n" +
+		"      role: admin\n\n" +
+		"When the role is left out, the user becomes a member. " +
+		"Users that are in the file but not in the organization are invited, members with a different role get the role in the file " +
+		"and members that are not in the file are revoked from the organization and all its repositories. " +
+		"Your own account is never revoked and the file must give at least one member the admin role.\n\n" +
+		"By default, only the changes are shown. Use --apply to make them.")
+	clause.Flags().StringVarP(&cmd.file, "file", "f", defaultMembersFile, "The path to the members file.")
+	clause.Flags().BoolVar(&cmd.apply, "apply", false, "Make the changes instead of only showing them.")
+	clause.Flags().BoolVar(&cmd.force, "force", false, "Apply the changes without asking for confirmation.")
+
+	clause.BindAction(cmd.Run)
+	clause.BindArguments([]cli.Argument{
+		{Value: &cmd.orgName, Name: "org-name", Required: true, Description: "The organization name."},
+	})
+}
+
+// orgMember is a user with a role in an organization.
+type orgMember struct {
+	username string
+	role     string
+}
+
+// orgMemberChange is a change to the membership of a user.
+type orgMemberChange struct {
+	kind   string
+	member orgMember
+	// old is the current role of a member of which the role is updated.
+	old string
+}
+
+// Run prints the changes to the members and makes them when --apply is set.
+func (cmd *OrgSyncCommand) Run() error {
+	declared, err := readMembersFile(cmd.file)
+	if err != nil {
+		return err
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	me, err := client.Users().Me()
+	if err != nil {
+		return err
+	}
+
+	changes, kept, err := planOrgSync(client, cmd.orgName.Value(), declared, me.Username)
+	if err != nil {
+		return err
+	}
+
+	for _, member := range kept {
+		fmt.Fprintf(cmd.io.Output(), "%s is not in the file, but is not revoked, as you cannot revoke yourself.\n\n", member.username)
+	}
+
+	if len(changes) == 0 {
+		fmt.Fprintf(cmd.io.Output(), "No changes. The members of %s match the file.\n", cmd.orgName)
+		return nil
+	}
+
+	err = printOrgSyncPlan(cmd.io.Output(), changes)
+	if err != nil {
+		return err
+	}
+
+	if !cmd.apply {
+		fmt.Fprintln(cmd.io.Output(), "\nThis was a dry run. Use --apply to make these changes.")
+		return nil
+	}
+
+	fmt.Fprintln(cmd.io.Output())
+	if !cmd.force {
+		confirmed, err := ui.AskYesNo(
+			cmd.io,
+			fmt.Sprintf("[WARNING] This changes who can access the secrets of the %s organization. Do you want to apply these changes?", cmd.orgName),
+			ui.DefaultNo,
+		)
+		if err != nil {
+			return err
+		}
+
+		if !confirmed {
+			fmt.Fprintln(cmd.io.Output(), "Aborting.")
+			return nil
+		}
+		fmt.Fprintln(cmd.io.Output())
+	}
+
+	for _, kind := range []string{aclChangeAdd, aclChangeUpdate, aclChangeRemove} {
+		for _, change := range changes {
+			if change.kind != kind {
+				continue
+			}
+
+			switch change.kind {
+			case aclChangeAdd:
+				fmt.Fprintf(cmd.io.Output(), "Inviting %s as %s\n", change.member.username, change.member.role)
+				_, err = client.Orgs().Members().Invite(cmd.orgName.Value(), change.member.username, change.member.role)
+			case aclChangeUpdate:
+				fmt.Fprintf(cmd.io.Output(), "Setting role of %s to %s\n", change.member.username, change.member.role)
+				_, err = client.Orgs().Members().Update(cmd.orgName.Value(), change.member.username, change.member.role)
+			case aclChangeRemove:
+				fmt.Fprintf(cmd.io.Output(), "Revoking %s\n", change.member.username)
+				var revoked *api.RevokeOrgResponse
+				revoked, err = client.Orgs().Members().Revoke(cmd.orgName.Value(), change.member.username, nil)
+				if err == nil && len(revoked.Repos) > 0 {
+					err = writeOrgRevokeRepoList(cmd.io.Output(), revoked.Repos...)
+				}
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Fprintf(
+		cmd.io.Output(),
+		"Sync complete! %d invited, %d updated, %d revoked.\n",
+		countOrgSyncChanges(changes, aclChangeAdd),
+		countOrgSyncChanges(changes, aclChangeUpdate),
+		countOrgSyncChanges(changes, aclChangeRemove),
+	)
+	return nil
+}
+
+// planOrgSync compares the declared members with the current members of the organization.
+// The authenticated user is never revoked, but returned as kept instead. When the changes
+// would leave the organization without an admin, an error is returned.
+func planOrgSync(client secrethub.ClientInterface, orgName string, declared []orgMember, me string) ([]orgMemberChange, []orgMember, error) {
+	members, err := client.Orgs().Members().List(orgName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	current := make(map[string]orgMember, len(members))
+	for _, member := range members {
+		current[strings.ToLower(member.User.Username)] = orgMember{
+			username: member.User.Username,
+			role:     member.Role,
+		}
+	}
+
+	var changes []orgMemberChange
+	var kept []orgMember
+	admins := 0
+	isDeclared := make(map[string]bool, len(declared))
+	for _, member := range declared {
+		key := strings.ToLower(member.username)
+		isDeclared[key] = true
+		if member.role == api.OrgRoleAdmin {
+			admins++
+		}
+
+		currentMember, ok := current[key]
+		if !ok {
+			changes = append(changes, orgMemberChange{kind: aclChangeAdd, member: member})
+		} else if currentMember.role != member.role {
+			changes = append(changes, orgMemberChange{kind: aclChangeUpdate, member: member, old: currentMember.role})
+		}
+	}
+	for key, member := range current {
+		if isDeclared[key] {
+			continue
+		}
+
+		if strings.EqualFold(member.username, me) {
+			kept = append(kept, member)
+			if member.role == api.OrgRoleAdmin {
+				admins++
+			}
+			continue
+		}
+		changes = append(changes, orgMemberChange{kind: aclChangeRemove, member: member})
+	}
+
+	if admins == 0 {
+		return nil, nil, ErrOrgSyncWithoutAdmin(orgName)
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return strings.ToLower(changes[i].member.username) < strings.ToLower(changes[j].member.username)
+	})
+	return changes, kept, nil
+}
+
+// printOrgSyncPlan writes the changes to the members to w.
+func printOrgSyncPlan(w io.Writer, changes []orgMemberChange) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 4, ' ', 0)
+	for _, change := range changes {
+		role := change.member.role
+		if change.kind == aclChangeUpdate {
+			role = change.old + " -> " + role
+		}
+		fmt.Fprintf(tw, "  %s %s\t%s\n", change.kind, change.member.username, role)
+	}
+	err := tw.Flush()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(
+		w,
+		"\nPlan: %d to invite, %d to update, %d to revoke.\n",
+		countOrgSyncChanges(changes, aclChangeAdd),
+		countOrgSyncChanges(changes, aclChangeUpdate),
+		countOrgSyncChanges(changes, aclChangeRemove),
+	)
+	return nil
+}
+
+// countOrgSyncChanges returns the number of changes of the given kind.
+func countOrgSyncChanges(changes []orgMemberChange, kind string) int {
+	n := 0
+	for _, change := range changes {
+		if change.kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// membersFile is the YAML format of a members file.
+type membersFile struct {
+	Members []membersFileEntry `yaml:"members"`
+}
+
+// membersFileEntry is a user in a members file.
+type membersFileEntry struct {
+	Username string `yaml:"username"`
+	Role     string `yaml:"role"`
+}
+
+// readMembersFile reads and validates the members declared in a YAML or CSV members file.
+func readMembersFile(filePath string) ([]orgMember, error) {
+	raw, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, ErrReadFile(filePath, err)
+	}
+
+	var entries []membersFileEntry
+	if strings.EqualFold(filepath.Ext(filePath), ".csv") {
+		entries, err = parseMembersCSV(raw)
+	} else {
+		var file membersFile
+		err = yaml.UnmarshalStrict(raw, &file)
+		entries = file.Members
+	}
+	if err != nil {
+		return nil, ErrInvalidMembersFile(filePath, err)
+	}
+	if len(entries) == 0 {
+		return nil, ErrInvalidMembersFile(filePath, "the file declares no members")
+	}
+
+	members := make([]orgMember, len(entries))
+	declared := make(map[string]bool, len(entries))
+	for i, entry := range entries {
+		err = api.ValidateUsername(entry.Username)
+		if err != nil {
+			return nil, ErrInvalidMembersFile(filePath, fmt.Sprintf("member %d has an invalid username %q: %v", i+1, entry.Username, err))
+		}
+
+		role := strings.ToLower(entry.Role)
+		if role == "" {
+			role = api.OrgRoleMember
+		}
+		if api.ValidateOrgRole(role) != nil {
+			return nil, ErrInvalidMembersFile(filePath, fmt.Sprintf("member %d has an invalid role %q: use admin or member", i+1, entry.Role))
+		}
+
+		key := strings.ToLower(entry.Username)
+		if declared[key] {
+			return nil, ErrInvalidMembersFile(filePath, fmt.Sprintf("%s occurs more than once", entry.Username))
+		}
+		declared[key] = true
+
+		members[i] = orgMember{
+			username: entry.Username,
+			role:     role,
+		}
+	}
+
+	return members, nil
+}
+
+// parseMembersCSV parses a CSV members file with a header row containing the username and optionally the role column.
+func parseMembersCSV(raw []byte) ([]membersFileEntry, error) {
+	reader := csv.NewReader(bytes.NewReader(raw))
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("the file is empty: it should start with a header row containing the columns username and role")
+	}
+
+	usernameColumn, roleColumn := -1, -1
+	for i, column := range records[0] {
+		switch strings.ToLower(strings.TrimSpace(column)) {
+		case "username":
+			usernameColumn = i
+		case "role":
+			roleColumn = i
+		}
+	}
+	if usernameColumn == -1 {
+		return nil, fmt.Errorf("the header row has no username column")
+	}
+
+	entries := make([]membersFileEntry, len(records)-1)
+	for i, record := range records[1:] {
+		entries[i].Username = strings.TrimSpace(record[usernameColumn])
+		if roleColumn != -1 {
+			entries[i].Role = strings.TrimSpace(record[roleColumn])
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,221 @@
+package secrethub
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestOrgSyncCommand_Run(t *testing.T) {
+	membersYAML := "members:\n" +
+		"  - username: owner\n    role: admin\n" +
+		"  - username: dev1\n    role: Admin\n" +
+		"  - username: DEV2\n" +
+		"  - username: newbie\n    role: member\n"
+	plan := "  ~ dev1      member -> admin\n" +
+		"  - intern    member\n" +
+		"  + newbie    member\n" +
+		"\n" +
+		"Plan: 1 to invite, 1 to update, 1 to revoke.\n"
+	applied := "Inviting newbie as member\n" +
+		"Setting role of dev1 to admin\n" +
+		"Revoking intern\n" +
+		"  company/app  => flagged\n" +
+		"\n" +
+		"Sync complete! 1 invited, 1 updated, 1 revoked.\n"
+	prompt := "[WARNING] This changes who can access the secrets of the company organization. Do you want to apply these changes? [y/N]: "
+
+	cases := map[string]struct {
+		fileName  string
+		file      string
+		me        string
+		apply     bool
+		force     bool
+		in        string
+		out       string
+		promptOut string
+		changes   []string
+		err       error
+	}{
+		"dry run": {
+			file: membersYAML,
+			out:  plan + "\nThis was a dry run. Use --apply to make these changes.\n",
+		},
+		"csv": {
+			fileName: "members.CSV",
+			file:     "role,username\nadmin,owner\nadmin,dev1\n,dev2\nmember, newbie\n",
+			out:      plan + "\nThis was a dry run. Use --apply to make these changes.\n",
+		},
+		"apply": {
+			file:      membersYAML,
+			apply:     true,
+			in:        "y",
+			out:       plan + "\n\n" + applied,
+			promptOut: prompt,
+			changes:   []string{"invite newbie member", "update dev1 admin", "revoke intern"},
+		},
+		"apply with force": {
+			file:    membersYAML,
+			apply:   true,
+			force:   true,
+			out:     plan + "\n" + applied,
+			changes: []string{"invite newbie member", "update dev1 admin", "revoke intern"},
+		},
+		"abort": {
+			file:      membersYAML,
+			apply:     true,
+			in:        "n",
+			out:       plan + "\nAborting.\n",
+			promptOut: prompt,
+		},
+		"no changes": {
+			file:  "members:\n  - username: owner\n    role: admin\n  - username: dev1\n  - username: dev2\n  - username: intern\n",
+			apply: true,
+			out:   "No changes. The members of company match the file.\n",
+		},
+		"does not revoke yourself": {
+			file: membersYAML,
+			me:   "Intern",
+			out: "intern is not in the file, but is not revoked, as you cannot revoke yourself.\n" +
+				"\n" +
+				"  ~ dev1      member -> admin\n" +
+				"  + newbie    member\n" +
+				"\n" +
+				"Plan: 1 to invite, 1 to update, 0 to revoke.\n" +
+				"\nThis was a dry run. Use --apply to make these changes.\n",
+		},
+		"without admin": {
+			file:  "members:\n  - username: owner\n  - username: dev1\n  - username: dev2\n  - username: intern\n",
+			apply: true,
+			force: true,
+			err:   ErrOrgSyncWithoutAdmin("company"),
+		},
+		"keeping yourself as only admin": {
+			file: "members:\n  - username: dev1\n",
+			out: "owner is not in the file, but is not revoked, as you cannot revoke yourself.\n" +
+				"\n" +
+				"  - dev2      member\n" +
+				"  - intern    member\n" +
+				"\n" +
+				"Plan: 0 to invite, 0 to update, 2 to revoke.\n" +
+				"\nThis was a dry run. Use --apply to make these changes.\n",
+		},
+		"no members": {
+			file: "members:\n",
+			err:  ErrInvalidMembersFile("<file>", "the file declares no members"),
+		},
+		"empty file": {
+			file: "",
+			err:  ErrInvalidMembersFile("<file>", "the file declares no members"),
+		},
+		"csv with only a header": {
+			fileName: "members.csv",
+			file:     "username,role\n",
+			err:      ErrInvalidMembersFile("<file>", "the file declares no members"),
+		},
+		"invalid role": {
+			file: "members:\n  - username: owner\n    role: owner\n",
+			err:  ErrInvalidMembersFile("<file>", `member 1 has an invalid role "owner": use admin or member`),
+		},
+		"duplicate member": {
+			file: "members:\n  - username: dev1\n  - username: Dev1\n    role: admin\n",
+			err:  ErrInvalidMembersFile("<file>", "Dev1 occurs more than once"),
+		},
+		"csv without username column": {
+			fileName: "members.csv",
+			file:     "user,role\ndev1,admin\n",
+			err:      ErrInvalidMembersFile("<file>", "the header row has no username column"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Setup
+			dir, cleanup := testdata.tempDir(t)
+			defer cleanup()
+			if tc.fileName == "" {
+				tc.fileName = defaultMembersFile
+			}
+			if tc.me == "" {
+				tc.me = "owner"
+			}
+			file := filepath.Join(dir, tc.fileName)
+			err := ioutil.WriteFile(file, []byte(tc.file), 0600)
+			assert.OK(t, err)
+
+			var changes []string
+			io := fakeui.NewIO(t)
+			io.PromptIn.Buffer = bytes.NewBufferString(tc.in)
+			cmd := OrgSyncCommand{
+				io:      io,
+				orgName: "company",
+				file:    file,
+				apply:   tc.apply,
+				force:   tc.force,
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						UserService: &fakeclient.UserService{
+							MeFunc: func() (*api.User, error) {
+								return &api.User{Username: tc.me}, nil
+							},
+						},
+						OrgService: &fakeclient.OrgService{
+							MembersService: &fakeclient.OrgMemberService{
+								ListFunc: func(org string) ([]*api.OrgMember, error) {
+									assert.Equal(t, org, "company")
+									return []*api.OrgMember{
+										{User: &api.User{Username: "owner"}, Role: api.OrgRoleAdmin},
+										{User: &api.User{Username: "dev1"}, Role: api.OrgRoleMember},
+										{User: &api.User{Username: "dev2"}, Role: api.OrgRoleMember},
+										{User: &api.User{Username: "intern"}, Role: api.OrgRoleMember},
+									}, nil
+								},
+								InviteFunc: func(org string, username string, role string) (*api.OrgMember, error) {
+									changes = append(changes, "invite "+username+" "+role)
+									return &api.OrgMember{}, nil
+								},
+								UpdateFunc: func(org string, username string, role string) (*api.OrgMember, error) {
+									changes = append(changes, "update "+username+" "+role)
+									return &api.OrgMember{}, nil
+								},
+								RevokeFunc: func(org string, username string, opts *api.RevokeOpts) (*api.RevokeOrgResponse, error) {
+									changes = append(changes, "revoke "+username)
+									return &api.RevokeOrgResponse{
+										Repos: []*api.RevokeRepoResponse{
+											{Namespace: "company", Name: "app", Status: api.StatusFlagged},
+										},
+									}, nil
+								},
+							},
+						},
+					}, nil
+				},
+			}
+
+			// Act
+			err = cmd.Run()
+
+			// Assert
+			if err != nil {
+				err = errors.New(strings.ReplaceAll(err.Error(), file, "<file>"))
+			}
+			if tc.err != nil {
+				tc.err = errors.New(tc.err.Error())
+			}
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, io.Out.String(), tc.out)
+			assert.Equal(t, io.PromptOut.String(), tc.promptOut)
+			assert.Equal(t, changes, tc.changes)
+		})
+	}
+}
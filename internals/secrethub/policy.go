@@ -0,0 +1,147 @@
+package secrethub
+
+import (
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/policy"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+)
+
+// Errors
+var (
+	ErrInvalidPolicyFile = errMain.Code("invalid_policy_file").ErrorPref("cannot use the policy at %s: %v")
+	ErrPolicyViolation   = errMain.Code("policy_violation").ErrorPref("the value does not meet the policy at %s: it %s")
+)
+
+// PolicyCommand handles operations on password policies.
+type PolicyCommand struct {
+	io        ui.IO
+	newClient newClientFunc
+}
+
+// NewPolicyCommand creates a new PolicyCommand.
+func NewPolicyCommand(io ui.IO, newClient newClientFunc) *PolicyCommand {
+	return &PolicyCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command and its sub-commands on the provided Registerer.
+func (cmd *PolicyCommand) Register(r cli.Registerer) {
+	clause := r.Command("policy", "Manage password policies.")
+	clause.HelpLong("A password policy is a YAML secret named " + policy.FileName + " in a repository or directory. " +
+		"It applies to all secrets below it, unless a directory closer to the secret has a policy of its own. " +
+		"The generate command uses the policy as defaults and the write command refuses values that violate it.\n\n" +
+		"A policy can contain the following fields, all of which are optional:\n\n" +
+		"  length: 24                     # the minimum length\n" +
+		"  charsets: [alphanumeric]       # the characters a value may consist of\n" +
+		"  min: {numeric: 2}              # the minimum number of characters per charset\n" +
+		"  forbidden_words: [password]    # words that must not occur, regardless of case\n" +
+		"  min_entropy: 80                # the minimum estimated entropy in bits\n" +
+		"  max_age: 90d                   # the maximum age of the latest version")
+	NewPolicyCheckCommand(cmd.io, cmd.newClient).Register(clause)
+}
+
+// dirPolicy is a policy together with the path of the secret it is stored in.
+type dirPolicy struct {
+	path   string
+	policy *policy.Policy
+}
+
+// check returns ErrPolicyViolation when the value does not meet the policy.
+func (p *dirPolicy) check(value []byte) error {
+	violations := p.policy.Check(value, 0)
+	if len(violations) > 0 {
+		return ErrPolicyViolation(p.path, strings.Join(violations, "; it "))
+	}
+	return nil
+}
+
+// checkPolicy validates a value before it is written to the secret at the given path.
+// A policy itself is validated by parsing it, any other value against the policy that applies to it.
+func checkPolicy(client secrethub.ClientInterface, path string, value []byte) error {
+	return newPolicyFinder(client).check(path, value)
+}
+
+// policyFinder looks up the policies that apply to secrets.
+// The policy of every visited directory is cached, so secrets
+// in the same directory only cause a single lookup.
+type policyFinder struct {
+	client   secrethub.ClientInterface
+	policies map[string]*dirPolicy
+}
+
+// newPolicyFinder creates a new policyFinder.
+func newPolicyFinder(client secrethub.ClientInterface) *policyFinder {
+	return &policyFinder{
+		client:   client,
+		policies: make(map[string]*dirPolicy),
+	}
+}
+
+// find returns the policy that applies to the secret at the given path,
+// which is the policy of the closest directory above it that has one.
+// It returns nil when no policy applies.
+func (f *policyFinder) find(secretPath string) (*dirPolicy, error) {
+	parent, err := api.SecretPath(secretPath).GetParentPath()
+	if err != nil {
+		return nil, err
+	}
+	return f.findInDir(api.DirPath(parent))
+}
+
+// check validates a value before it is written to the secret at the given path, like checkPolicy.
+func (f *policyFinder) check(path string, value []byte) error {
+	if api.SecretPath(path).GetSecret() == policy.FileName {
+		_, err := policy.Parse(value)
+		if err != nil {
+			return ErrInvalidPolicyFile(path, err)
+		}
+		return nil
+	}
+
+	p, err := f.find(path)
+	if err != nil || p == nil {
+		return err
+	}
+	return p.check(value)
+}
+
+func (f *policyFinder) findInDir(dir api.DirPath) (*dirPolicy, error) {
+	found, ok := f.policies[dir.Value()]
+	if ok {
+		return found, nil
+	}
+
+	path := dir.JoinSecret(policy.FileName).Value()
+	version, err := f.client.Secrets().Versions().GetWithData(path)
+	if err == nil {
+		p, err := policy.Parse(version.Data)
+		if err != nil {
+			return nil, ErrInvalidPolicyFile(path, err)
+		}
+		found = &dirPolicy{path: path, policy: p}
+	} else if api.IsErrNotFound(err) || err == api.ErrForbidden {
+		// Without read access to a directory, its policy cannot be enforced client-side.
+		if !dir.IsRepoPath() {
+			parent, err := dir.GetParentPath()
+			if err != nil {
+				return nil, err
+			}
+			found, err = f.findInDir(api.DirPath(parent))
+			if err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		return nil, err
+	}
+
+	f.policies[dir.Value()] = found
+	return found, nil
+}
@@ -0,0 +1,131 @@
+package secrethub
+
+import (
+	"fmt"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+)
+
+// Errors
+var (
+	ErrPolicyViolationsFound = errMain.Code("policy_violations_found").ErrorPref("%d secret(s) violate their policy")
+)
+
+// PolicyCheckCommand reports the secrets in a directory that violate their policy.
+type PolicyCheckCommand struct {
+	io        ui.IO
+	path      api.DirPath
+	newClient newClientFunc
+}
+
+// NewPolicyCheckCommand creates a new PolicyCheckCommand.
+func NewPolicyCheckCommand(io ui.IO, newClient newClientFunc) *PolicyCheckCommand {
+	return &PolicyCheckCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *PolicyCheckCommand) Register(r cli.Registerer) {
+	clause := r.Command("check", "Report the secrets in a directory that violate their policy.")
+	clause.HelpLong("The latest version of every secret in the directory and its subdirectories is checked against " +
+		"the policy that applies to it. The values are decrypted and checked locally. " +
+		"The command fails when any secret violates its policy, so it can be used in CI pipelines.")
+
+	clause.BindAction(cmd.Run)
+	clause.BindArguments([]cli.Argument{{Value: &cmd.path, Name: "dir-path", Required: true, Placeholder: optionalDirPathPlaceHolder, Description: "The path to the directory to check."}})
+}
+
+// policyViolation is a requirement of a policy that a secret does not meet.
+type policyViolation struct {
+	secretPath string
+	policyPath string
+	violation  string
+}
+
+// Run checks the secrets in the directory against their policies.
+func (cmd *PolicyCheckCommand) Run() error {
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	tree, err := client.Dirs().GetTree(cmd.path.Value(), -1, false)
+	if err != nil {
+		return err
+	}
+
+	paths := make([]string, 0, len(tree.Secrets))
+	for id, secret := range tree.Secrets {
+		if isReservedSecretName(secret.Name) {
+			continue
+		}
+		path, err := tree.AbsSecretPath(id)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, path.Value())
+	}
+	sort.Strings(paths)
+
+	finder := newPolicyFinder(client)
+	var violations []policyViolation
+	checked := 0
+	violating := 0
+	for _, path := range paths {
+		p, err := finder.find(path)
+		if err != nil {
+			return err
+		}
+		if p == nil {
+			continue
+		}
+
+		version, err := client.Secrets().Versions().GetWithData(path)
+		if err != nil {
+			return err
+		}
+		checked++
+
+		found := p.policy.Check(version.Data, time.Since(version.CreatedAt))
+		if len(found) > 0 {
+			violating++
+		}
+		for _, violation := range found {
+			violations = append(violations, policyViolation{
+				secretPath: path,
+				policyPath: p.path,
+				violation:  violation,
+			})
+		}
+	}
+
+	if checked == 0 {
+		fmt.Fprintf(cmd.io.Output(), "No policy applies to the secrets in %s.\n", cmd.path)
+		return nil
+	}
+
+	if violating == 0 {
+		fmt.Fprintf(cmd.io.Output(), "All %d checked secret(s) meet their policy.\n", checked)
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(cmd.io.Output(), 0, 4, 4, ' ', 0)
+	fmt.Fprintf(tw, "%s\t%s\t%s\n", "SECRET", "POLICY", "VIOLATION")
+	for _, v := range violations {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", v.secretPath, v.policyPath, v.violation)
+	}
+	err = tw.Flush()
+	if err != nil {
+		return err
+	}
+
+	return ErrPolicyViolationsFound(violating)
+}
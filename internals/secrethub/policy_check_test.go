@@ -0,0 +1,109 @@
+package secrethub
+
+import (
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+	"github.com/secrethub/secrethub-cli/internals/policy"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/api/uuid"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestPolicyCheckCommand_Run(t *testing.T) {
+	rootID := uuid.New()
+	dirID := uuid.New()
+	tree := &api.Tree{
+		ParentPath: "namespace",
+		RootDir:    &api.Dir{DirID: rootID, Name: "repo"},
+		Dirs: map[uuid.UUID]*api.Dir{
+			rootID: {DirID: rootID, Name: "repo"},
+			dirID:  {DirID: dirID, ParentID: &rootID, Name: "dir"},
+		},
+		Secrets: map[uuid.UUID]*api.Secret{
+			uuid.New(): {DirID: rootID, Name: ".policy"},
+			uuid.New(): {DirID: rootID, Name: "strong"},
+			uuid.New(): {DirID: rootID, Name: "weak"},
+			uuid.New(): {DirID: dirID, Name: "old"},
+		},
+	}
+
+	cases := map[string]struct {
+		secrets     map[string]*api.SecretVersion
+		expectedOut string
+		expectedErr error
+	}{
+		"violations": {
+			secrets: map[string]*api.SecretVersion{
+				"namespace/repo/.policy":    {Data: []byte("length: 12\nmax_age: 30d")},
+				"namespace/repo/strong":     {Data: []byte("a-long-enough-value"), CreatedAt: time.Now()},
+				"namespace/repo/weak":       {Data: []byte("short"), CreatedAt: time.Now()},
+				"namespace/repo/dir/old":    {Data: []byte("a-long-enough-value"), CreatedAt: time.Now().Add(-40 * 24 * time.Hour)},
+				"namespace/repo/dir/unused": {Data: []byte("not in the tree")},
+			},
+			expectedOut: "SECRET                    POLICY                    VIOLATION\n" +
+				"namespace/repo/dir/old    namespace/repo/.policy    is 40d old, the maximum age is 30d\n" +
+				"namespace/repo/weak       namespace/repo/.policy    is 5 characters long, the minimum is 12\n",
+			expectedErr: ErrPolicyViolationsFound(2),
+		},
+		"all secrets meet policy": {
+			secrets: map[string]*api.SecretVersion{
+				"namespace/repo/.policy":     {Data: []byte("length: 12")},
+				"namespace/repo/dir/.policy": {Data: []byte("length: 5")},
+				"namespace/repo/strong":      {Data: []byte("a-long-enough-value")},
+				"namespace/repo/weak":        {Data: []byte("a-long-enough-value")},
+				"namespace/repo/dir/old":     {Data: []byte("short")},
+			},
+			expectedOut: "All 3 checked secret(s) meet their policy.\n",
+		},
+		"no policy": {
+			secrets:     map[string]*api.SecretVersion{},
+			expectedOut: "No policy applies to the secrets in namespace/repo.\n",
+		},
+		"invalid policy": {
+			secrets: map[string]*api.SecretVersion{
+				"namespace/repo/.policy": {Data: []byte("charsets: [emoji]")},
+			},
+			expectedErr: ErrInvalidPolicyFile("namespace/repo/.policy", policy.ErrUnknownCharset("emoji")),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			io := fakeui.NewIO(t)
+			cmd := PolicyCheckCommand{
+				io:   io,
+				path: "namespace/repo",
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						DirService: &fakeclient.DirService{
+							GetTreeFunc: func(path string, depth int, ancestors bool) (*api.Tree, error) {
+								return tree, nil
+							},
+						},
+						SecretService: &fakeclient.SecretService{
+							VersionService: &fakeclient.SecretVersionService{
+								GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+									version, ok := tc.secrets[path]
+									if !ok {
+										return nil, api.ErrSecretNotFound
+									}
+									return version, nil
+								},
+							},
+						},
+					}, nil
+				},
+			}
+
+			err := cmd.Run()
+
+			assert.Equal(t, err, tc.expectedErr)
+			assert.Equal(t, io.Out.String(), tc.expectedOut)
+		})
+	}
+}
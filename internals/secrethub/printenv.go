@@ -1,7 +1,11 @@
 package secrethub
 
 import (
+	"fmt"
+	"io/ioutil"
 	"os"
+	"sort"
+	"text/tabwriter"
 
 	"github.com/secrethub/secrethub-cli/internals/cli"
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
@@ -9,18 +13,22 @@ import (
 
 // PrintEnvCommand prints out debug statements about all environment variables.
 type PrintEnvCommand struct {
-	app     *cli.App
-	io      ui.IO
-	osEnv   func() []string
-	verbose bool
+	app      *cli.App
+	io       ui.IO
+	osEnv    func() []string
+	readFile func(filename string) ([]byte, error)
+	verbose  bool
+	stage    string
+	envFile  string
 }
 
 // NewPrintEnvCommand creates a new PrintEnvCommand.
 func NewPrintEnvCommand(app *cli.App, io ui.IO) *PrintEnvCommand {
 	return &PrintEnvCommand{
-		app:   app,
-		io:    io,
-		osEnv: os.Environ,
+		app:      app,
+		io:       io,
+		osEnv:    os.Environ,
+		readFile: ioutil.ReadFile,
 	}
 }
 
@@ -30,13 +38,42 @@ func (cmd *PrintEnvCommand) Run() error {
 	if err != nil {
 		return err
 	}
+
+	if cmd.stage != "" {
+		return cmd.printStage()
+	}
 	return nil
 }
 
+// printStage prints the variables of the selected stage as they are declared in the environment file.
+// Templates are not evaluated, so no secrets are printed.
+func (cmd *PrintEnvCommand) printStage() error {
+	vars, err := readEnvFileStage(cmd.readFile, cmd.envFile, cmd.stage)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(cmd.io.Output(), "\nVariables of stage %s in %s:\n", cmd.stage, cmd.envFile)
+	tabWriter := tabwriter.NewWriter(cmd.io.Output(), 0, 4, 4, ' ', 0)
+	fmt.Fprintf(tabWriter, "%s\t%s\n", "NAME", "VALUE")
+	for _, name := range names {
+		fmt.Fprintf(tabWriter, "%s\t%s\n", name, vars[name])
+	}
+	return tabWriter.Flush()
+}
+
 // Register registers the command, arguments and flags on the provided Registerer.
 func (cmd *PrintEnvCommand) Register(r cli.Registerer) {
 	clause := r.Command("printenv", "Print environment variables.")
 	clause.Flags().BoolVarP(&cmd.verbose, "verbose", "v", false, "Show all possible environment variables.")
+	clause.Flags().StringVar(&cmd.stage, "stage", "", "Also show the variables of a stage declared in the environment file, without evaluating templates.")
+	clause.Flags().StringVar(&cmd.envFile, "env-file", defaultEnvFile, "The path to the environment file that declares the stage. It is ignored without the --stage flag.")
 
 	clause.BindAction(cmd.Run)
 	clause.BindArguments(nil)
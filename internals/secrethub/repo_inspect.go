@@ -1,8 +1,6 @@
 package secrethub
 
 import (
-	"fmt"
-
 	"github.com/secrethub/secrethub-cli/internals/cli"
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
 
@@ -56,20 +54,16 @@ func (cmd *RepoInspectCommand) Run() error {
 		return err
 	}
 
-	output, err := cli.PrettyJSON(newInspectRepoOutput(repo, users, services, cmd.timeFormatter))
-	if err != nil {
-		return err
-	}
-
-	fmt.Fprintln(cmd.io.Output(), output)
-
-	return nil
+	return newPrettyJSONFormatter(cmd.io.Output()).WriteValue(newInspectRepoOutput(repo, users, services, cmd.timeFormatter))
 }
 
 func newInspectRepoOutput(repo *api.Repo, users []*api.User, services []*api.Service, timeFormatter TimeFormatter) inspectRepoOutput {
 	out := inspectRepoOutput{
+		RepoID:       repo.RepoID.String(),
 		Name:         repo.Name,
 		Owner:        repo.Owner,
+		Path:         repo.Path().Value(),
+		Status:       repo.Status,
 		CreatedAt:    timeFormatter.Format(repo.CreatedAt.Local()),
 		SecretCount:  repo.SecretCount,
 		MemberCount:  len(users),
@@ -91,8 +85,11 @@ func newInspectRepoOutput(repo *api.Repo, users []*api.User, services []*api.Ser
 
 // inspectRepoOutput is the json format to print out with all the details of a repo.
 type inspectRepoOutput struct {
+	RepoID       string
 	Name         string
 	Owner        string
+	Path         string
+	Status       string
 	CreatedAt    string
 	SecretCount  int
 	MemberCount  int
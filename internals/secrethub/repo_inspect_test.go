@@ -8,6 +8,7 @@ import (
 	"github.com/secrethub/secrethub-cli/internals/secrethub/fakes"
 
 	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/api/uuid"
 	"github.com/secrethub/secrethub-go/internals/assert"
 	"github.com/secrethub/secrethub-go/internals/errio"
 	"github.com/secrethub/secrethub-go/pkg/secrethub"
@@ -18,6 +19,7 @@ func TestInspectRepo_Run(t *testing.T) {
 	testErr := errio.Namespace("test").Code("test").Error("test error")
 
 	testTime := time.Date(2018, 1, 1, 1, 1, 1, 1, time.UTC)
+	repoID := uuid.New()
 
 	cases := map[string]struct {
 		cmd          RepoInspectCommand
@@ -36,8 +38,10 @@ func TestInspectRepo_Run(t *testing.T) {
 			repoService: fakeclient.RepoService{
 				GetFunc: func(path string) (repo *api.Repo, err error) {
 					return &api.Repo{
+						RepoID:      repoID,
 						Name:        "bar",
 						Owner:       "Repo Owner",
+						Status:      api.StatusOK,
 						CreatedAt:   testTime,
 						SecretCount: 1,
 					}, nil
@@ -73,8 +77,11 @@ func TestInspectRepo_Run(t *testing.T) {
 			},
 			out: "" +
 				"{\n" +
+				"    \"RepoID\": \"" + repoID.String() + "\",\n" +
 				"    \"Name\": \"bar\",\n" +
 				"    \"Owner\": \"Repo Owner\",\n" +
+				"    \"Path\": \"Repo Owner/bar\",\n" +
+				"    \"Status\": \"ok\",\n" +
 				"    \"CreatedAt\": \"2018-01-01T01:01:01+01:00\",\n" +
 				"    \"SecretCount\": 1,\n" +
 				"    \"MemberCount\": 2,\n" +
@@ -128,30 +128,50 @@ func (cmd *RepoRevokeCommand) Run() error {
 	return nil
 }
 
+// printFlaggedSecrets writes the secrets in the directory that are flagged for rotation to w
+// and returns the number of unaffected and flagged secrets.
 func printFlaggedSecrets(w io.Writer, dir *api.Dir, prePath string) (int, int) {
-	var countUnaffected, countFlagged int
+	countUnaffected, flagged := collectFlaggedSecrets(dir, prePath)
+	for _, secret := range flagged {
+		fmt.Fprintf(w, "%s\t=> %s\n", secret.path, secret.status)
+	}
+	return countUnaffected, len(flagged)
+}
+
+// flaggedSecret is a secret that is flagged for rotation.
+type flaggedSecret struct {
+	path   string
+	status string
+}
+
+// collectFlaggedSecrets returns the number of unaffected secrets in the directory and the secrets that are flagged.
+func collectFlaggedSecrets(dir *api.Dir, prePath string) (int, []flaggedSecret) {
+	var countUnaffected int
+	var flagged []flaggedSecret
 	if prePath != "" {
 		prePath = fmt.Sprintf("%s/%s", prePath, dir.Name)
 	} else {
 		prePath = dir.Name
 	}
 
-	// Print the directories below
+	// Collect the directories below
 	for _, subDir := range dir.SubDirs {
-		subUnaffected, subFlagged := printFlaggedSecrets(w, subDir, prePath)
+		subUnaffected, subFlagged := collectFlaggedSecrets(subDir, prePath)
 		countUnaffected += subUnaffected
-		countFlagged += subFlagged
+		flagged = append(flagged, subFlagged...)
 	}
 
-	// Print the secrets below
+	// Collect the secrets below
 	for _, secret := range dir.Secrets {
 		if secret.Status != api.StatusOK {
-			countFlagged++
-			fmt.Fprintf(w, "%s/%s\t=> %s\n", prePath, secret.Name, secret.Status)
+			flagged = append(flagged, flaggedSecret{
+				path:   fmt.Sprintf("%s/%s", prePath, secret.Name),
+				status: secret.Status,
+			})
 		} else {
 			countUnaffected++
 		}
 	}
 
-	return countUnaffected, countFlagged
+	return countUnaffected, flagged
 }
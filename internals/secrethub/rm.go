@@ -150,6 +150,11 @@ func rmSecret(client secrethub.ClientInterface, secretPath api.SecretPath, force
 		return err
 	}
 
+	err = removeAnnotations(client, secretPath.Value())
+	if err != nil {
+		return err
+	}
+
 	fmt.Fprintf(
 		io.Output(),
 		"Removal complete! The secret %s has been permanently removed.\n",
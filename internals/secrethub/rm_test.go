@@ -29,7 +29,9 @@ func TestRmCommand_Run(t *testing.T) {
 		getTreeErr        error
 		getSecretErr      error
 		promptErr         error
+		annotations       string
 		expectedPromptOut string
+		expectedWritten   map[string]string
 		expectedOut       string
 		expectedErr       error
 	}{
@@ -167,6 +169,19 @@ func TestRmCommand_Run(t *testing.T) {
 			expectedOut: "Removal complete! The secret namespace/repo/dir/secret has been permanently removed.\n",
 			getTreeErr:  api.ErrNotFound,
 		},
+		"success secret with annotations": {
+			cmd: RmCommand{
+				force: true,
+				path:  "namespace/repo/dir/secret",
+			},
+			argPath:     "namespace/repo/dir/secret",
+			annotations: "other:\n  owner: ops\nsecret:\n  expires: \"2027-01-01\"\n",
+			expectedWritten: map[string]string{
+				"namespace/repo/dir/.annotations": "other:\n  owner: ops\n",
+			},
+			expectedOut: "Removal complete! The secret namespace/repo/dir/secret has been permanently removed.\n",
+			getTreeErr:  api.ErrNotFound,
+		},
 		"success non force secret": {
 			cmd: RmCommand{
 				force: false,
@@ -219,6 +234,7 @@ func TestRmCommand_Run(t *testing.T) {
 			tc.cmd.io = io
 
 			var argPath string
+			written := map[string]string{}
 			tc.cmd.newClient = func() (secrethub.ClientInterface, error) {
 				return fakeclient.Client{
 					SecretService: &fakeclient.SecretService{
@@ -227,6 +243,16 @@ func TestRmCommand_Run(t *testing.T) {
 								argPath = path
 								return tc.deleteVersionErr
 							},
+							GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+								if tc.annotations == "" {
+									return nil, api.ErrSecretNotFound
+								}
+								return &api.SecretVersion{Data: []byte(tc.annotations)}, nil
+							},
+						},
+						WriteFunc: func(path string, data []byte) (*api.SecretVersion, error) {
+							written[path] = string(data)
+							return &api.SecretVersion{}, nil
 						},
 						GetFunc: func(path string) (*api.Secret, error) {
 							argPath = path
@@ -258,6 +284,10 @@ func TestRmCommand_Run(t *testing.T) {
 			assert.Equal(t, err, tc.expectedErr)
 			assert.Equal(t, io.Out.String(), tc.expectedOut)
 			assert.Equal(t, io.PromptOut.String(), tc.expectedPromptOut)
+			if tc.expectedWritten == nil {
+				tc.expectedWritten = map[string]string{}
+			}
+			assert.Equal(t, written, tc.expectedWritten)
 			if len(argPath) > 0 {
 				assert.Equal(t, argPath, tc.argPath.String())
 			}
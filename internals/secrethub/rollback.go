@@ -0,0 +1,105 @@
+package secrethub
+
+import (
+	"fmt"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+)
+
+// Errors
+var (
+	ErrRollbackVersionRequired = errMain.Code("rollback_version_required").Error("specify the version to roll back to, e.g. <path>:<version>")
+)
+
+// RollbackCommand restores an earlier version of a secret.
+type RollbackCommand struct {
+	io        ui.IO
+	path      api.SecretPath
+	force     bool
+	newClient newClientFunc
+}
+
+// NewRollbackCommand creates a new RollbackCommand.
+func NewRollbackCommand(io ui.IO, newClient newClientFunc) *RollbackCommand {
+	return &RollbackCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *RollbackCommand) Register(r cli.Registerer) {
+	clause := r.Command("rollback", "Restore an earlier version of a secret.")
+	clause.HelpLong("The value of the given version is written as a new version of the secret, " +
+		"so the history of the secret is preserved and the rollback itself can be undone.")
+	registerForceFlag(clause, &cmd.force)
+
+	clause.BindAction(cmd.Run)
+	clause.BindArguments([]cli.Argument{{Value: &cmd.path, Name: "secret-path", Required: true, Placeholder: secretPathPlaceHolder + ":<version>", Description: "The path to the version to restore."}})
+}
+
+// Run writes the value of the given version as the new latest version of the secret.
+func (cmd *RollbackCommand) Run() error {
+	if !cmd.path.HasVersion() {
+		return ErrRollbackVersionRequired
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	old, err := client.Secrets().Versions().GetWithData(cmd.path.Value())
+	if err != nil {
+		return err
+	}
+
+	path := withoutVersion(cmd.path)
+	secret, err := client.Secrets().Get(path)
+	if err != nil {
+		return err
+	}
+
+	if secret.LatestVersion == old.Version {
+		fmt.Fprintf(cmd.io.Output(), "Version %d is already the latest version of %s. Nothing to roll back.\n", old.Version, path)
+		return nil
+	}
+
+	// The old value is checked against the current policy, which may have changed since it was written.
+	err = checkPolicy(client, path, old.Data)
+	if err != nil {
+		return err
+	}
+
+	if !cmd.force {
+		confirmed, err := ui.AskYesNo(
+			cmd.io,
+			fmt.Sprintf(
+				"This writes the value of version %d of %s as a new version, replacing version %d as the latest version. Do you want to continue?",
+				old.Version,
+				path,
+				secret.LatestVersion,
+			),
+			ui.DefaultNo,
+		)
+		if err != nil {
+			return err
+		}
+
+		if !confirmed {
+			fmt.Fprintln(cmd.io.Output(), "Aborting.")
+			return nil
+		}
+	}
+
+	version, err := client.Secrets().Write(path, old.Data)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Rollback complete! The value of version %d has been written to %s:%d.\n", old.Version, path, version.Version)
+	return nil
+}
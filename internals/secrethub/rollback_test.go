@@ -0,0 +1,119 @@
+package secrethub
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestRollbackCommand_Run(t *testing.T) {
+	cases := map[string]struct {
+		cmd          RollbackCommand
+		in           string
+		policy       string
+		expectedData []byte
+		promptOut    string
+		out          string
+		err          error
+	}{
+		"success": {
+			cmd: RollbackCommand{
+				path: "namespace/repo/secret:2",
+			},
+			in:           "y",
+			expectedData: []byte("value 2"),
+			promptOut:    "This writes the value of version 2 of namespace/repo/secret as a new version, replacing version 3 as the latest version. Do you want to continue? [y/N]: ",
+			out:          "Rollback complete! The value of version 2 has been written to namespace/repo/secret:4.\n",
+		},
+		"force": {
+			cmd: RollbackCommand{
+				path:  "namespace/repo/secret:1",
+				force: true,
+			},
+			expectedData: []byte("value 1"),
+			out:          "Rollback complete! The value of version 1 has been written to namespace/repo/secret:4.\n",
+		},
+		"abort": {
+			cmd: RollbackCommand{
+				path: "namespace/repo/secret:2",
+			},
+			in:        "n",
+			promptOut: "This writes the value of version 2 of namespace/repo/secret as a new version, replacing version 3 as the latest version. Do you want to continue? [y/N]: ",
+			out:       "Aborting.\n",
+		},
+		"already latest": {
+			cmd: RollbackCommand{
+				path: "namespace/repo/secret:3",
+			},
+			out: "Version 3 is already the latest version of namespace/repo/secret. Nothing to roll back.\n",
+		},
+		"policy violated": {
+			cmd: RollbackCommand{
+				path:  "namespace/repo/secret:1",
+				force: true,
+			},
+			policy: "length: 16",
+			err:    ErrPolicyViolation("namespace/repo/.policy", "is 7 characters long, the minimum is 16"),
+		},
+		"no version": {
+			cmd: RollbackCommand{
+				path: "namespace/repo/secret",
+			},
+			err: ErrRollbackVersionRequired,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			io := fakeui.NewIO(t)
+			io.PromptIn.Buffer = bytes.NewBufferString(tc.in)
+			tc.cmd.io = io
+
+			var written []byte
+			tc.cmd.newClient = func() (secrethub.ClientInterface, error) {
+				return fakeclient.Client{
+					SecretService: &fakeclient.SecretService{
+						GetFunc: func(path string) (*api.Secret, error) {
+							assert.Equal(t, path, "namespace/repo/secret")
+							return &api.Secret{LatestVersion: 3}, nil
+						},
+						WriteFunc: func(path string, data []byte) (*api.SecretVersion, error) {
+							assert.Equal(t, path, "namespace/repo/secret")
+							written = data
+							return &api.SecretVersion{Version: 4}, nil
+						},
+						VersionService: &fakeclient.SecretVersionService{
+							GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+								if path == "namespace/repo/.policy" {
+									if tc.policy == "" {
+										return nil, api.ErrSecretNotFound
+									}
+									return &api.SecretVersion{Data: []byte(tc.policy)}, nil
+								}
+								v, err := api.SecretPath(path).GetVersion()
+								assert.OK(t, err)
+								version, err := strconv.Atoi(v)
+								assert.OK(t, err)
+								return &api.SecretVersion{Version: version, Data: []byte("value " + v)}, nil
+							},
+						},
+					},
+				}, nil
+			}
+
+			err := tc.cmd.Run()
+
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, written, tc.expectedData)
+			assert.Equal(t, io.PromptOut.String(), tc.promptOut)
+			assert.Equal(t, io.Out.String(), tc.out)
+		})
+	}
+}
@@ -0,0 +1,429 @@
+package secrethub
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/keygen"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/pkg/randchar"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Errors
+var (
+	ErrCannotReadRotationFile   = errMain.Code("cannot_read_rotation_file").ErrorPref("cannot read rotation file %s: %v")
+	ErrInvalidRotationFile      = errMain.Code("invalid_rotation_file").ErrorPref("invalid rotation file %s: %v")
+	ErrUnknownRotationGenerator = errMain.Code("unknown_rotation_generator").ErrorPref("unknown generator %s for %s: supported generators are rand and passphrase")
+	ErrNoRotationSpec           = errMain.Code("no_rotation_spec").ErrorPref("no rotation is declared for %s in %s")
+	ErrRotationHookFailed       = errMain.Code("rotation_hook_failed").ErrorPref("the hook for %s failed, so the secret has not been rotated: %v")
+	ErrRotationNotCommitted     = errMain.Code("rotation_not_committed").ErrorPref("the hook for %s succeeded, but the new version could not be written: %v. The new value has been saved to %s: write it with `secrethub write %s < %s` and remove the file afterwards")
+	ErrRotationValueNotSaved    = errMain.Code("rotation_value_not_saved").ErrorPref("the hook for %s succeeded, but the new version could not be written: %v. The new value could not be saved to a file either (%v), so write it by hand: %s")
+	ErrRotationsFailed          = errMain.Code("rotations_failed").ErrorPref("%d flagged secret(s) could not be rotated")
+)
+
+const (
+	defaultRotationFile = "secrethub.rotate.yml"
+
+	rotationGeneratorRand       = "rand"
+	rotationGeneratorPassphrase = "passphrase"
+
+	// rotationWriteAttempts is the number of times the new version is written
+	// after the hook succeeded, before giving up.
+	rotationWriteAttempts = 3
+)
+
+// RotateCommand replaces the value of secrets with newly generated values,
+// after a hook has applied the new value to the system that uses it.
+type RotateCommand struct {
+	io         ui.IO
+	path       cli.StringValue
+	file       string
+	flagged    bool
+	newClient  newClientFunc
+	runHook    func(hook []string, dir string, input []byte, output io.Writer) error
+	retryDelay time.Duration
+}
+
+// NewRotateCommand creates a new RotateCommand.
+func NewRotateCommand(io ui.IO, newClient newClientFunc) *RotateCommand {
+	return &RotateCommand{
+		io:         io,
+		newClient:  newClient,
+		runHook:    runRotationHook,
+		retryDelay: time.Second,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *RotateCommand) Register(r cli.Registerer) {
+	clause := r.Command("rotate", "Rotate a secret by generating a new value and running a hook.")
+	clause.HelpLong("The rotation file declares for each secret how its new value is generated and which hook " +
+		"applies the new value, e.g. a script that updates the password of a database user. " +
+		"The first rotation with a path pattern matching the secret is used, where * matches within a single directory:\n\n" +
+		"  rotations:\n" +
+		"    - path: company/app/db_password\n" +
+		"      generator: rand        # rand (default) or passphrase\n" +
+		"      length: 32             # for rand, defaults to the password policy or 22\n" +
+		"      charsets: [alphanumeric]\n" +
+		"      hook: [./update-db-password.sh, --user, app]\n" +
+		"    - path: company/app/*\n" +
+		"      generator: passphrase\n" +
+		"      words: 6\n\n" +
+		"The hook runs in the directory of the rotation file and receives a JSON object with the path, " +
+		"the old version, the old value and the new value on stdin. " +
+		"The new version is only written when the hook succeeds. " +
+		"When writing the new version fails after the hook succeeded, the new value is saved to a file " +
+		"next to the rotation file that is only readable by you, so that it can be written by hand. " +
+		"Secrets without a hook are rotated by writing the new value only.")
+	clause.Flags().StringVarP(&cmd.file, "file", "f", defaultRotationFile, "The path to the rotation file.")
+	clause.Flags().BoolVar(&cmd.flagged, "flagged", false, "Rotate all flagged secrets in the given repository or directory, e.g. after revoking an account.")
+
+	clause.BindAction(cmd.Run)
+	clause.BindArguments([]cli.Argument{{Value: &cmd.path, Name: "path", Required: true, Placeholder: secretPathPlaceHolder, Description: "The path to the secret to rotate, or the repository or directory when --flagged is set."}})
+}
+
+// Run rotates the secret, or all flagged secrets in the directory.
+func (cmd *RotateCommand) Run() error {
+	file, err := readRotationFile(cmd.file)
+	if err != nil {
+		return err
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	if !cmd.flagged {
+		err = api.ValidateSecretPath(cmd.path.Value)
+		if err != nil {
+			return err
+		}
+		return cmd.rotate(client, newPolicyFinder(client), file, cmd.path.Value)
+	}
+
+	err = api.ValidateDirPath(cmd.path.Value)
+	if err != nil {
+		return err
+	}
+
+	paths, err := flaggedSecrets(client, cmd.path.Value)
+	if err != nil {
+		return err
+	}
+
+	if len(paths) == 0 {
+		fmt.Fprintf(cmd.io.Output(), "No flagged secrets found in %s.\n", cmd.path.Value)
+		return nil
+	}
+
+	finder := newPolicyFinder(client)
+	failed := 0
+	for _, path := range paths {
+		err = cmd.rotate(client, finder, file, path)
+		if err != nil {
+			fmt.Fprintf(cmd.io.Output(), "Could not rotate %s: %s\n", path, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return ErrRotationsFailed(failed)
+	}
+	return nil
+}
+
+// rotate generates a new value for the secret and writes it after the hook succeeded.
+func (cmd *RotateCommand) rotate(client secrethub.ClientInterface, finder *policyFinder, file *rotationFile, secretPath string) error {
+	spec := file.find(secretPath)
+	if spec == nil {
+		return ErrNoRotationSpec(secretPath, cmd.file)
+	}
+
+	old, err := client.Secrets().Versions().GetWithData(secretPath)
+	if err != nil {
+		return err
+	}
+
+	p, err := finder.find(secretPath)
+	if err != nil {
+		return err
+	}
+
+	value, err := spec.generate(p)
+	if err != nil {
+		return err
+	}
+
+	if p != nil {
+		err = p.check(value)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(spec.Hook) > 0 {
+		input, err := json.Marshal(rotationHookInput{
+			Path:       secretPath,
+			OldVersion: old.Version,
+			OldValue:   string(old.Data),
+			NewValue:   string(value),
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.io.Output(), "Running the rotation hook for %s...\n", secretPath)
+		err = cmd.runHook(spec.Hook, filepath.Dir(cmd.file), input, cmd.io.Output())
+		if err != nil {
+			return ErrRotationHookFailed(secretPath, err)
+		}
+	}
+
+	// Once the hook has applied the new value, it must not get lost.
+	attempts := 1
+	if len(spec.Hook) > 0 {
+		attempts = rotationWriteAttempts
+	}
+	var version *api.SecretVersion
+	for attempt := 1; attempt <= attempts; attempt++ {
+		version, err = client.Secrets().Write(secretPath, value)
+		if err == nil {
+			break
+		}
+		if attempt < attempts {
+			time.Sleep(cmd.retryDelay)
+		}
+	}
+	if err != nil {
+		if len(spec.Hook) == 0 {
+			return err
+		}
+		recoveryFile, saveErr := saveRotationValue(filepath.Dir(cmd.file), value)
+		if saveErr != nil {
+			return ErrRotationValueNotSaved(secretPath, err, saveErr, string(value))
+		}
+		return ErrRotationNotCommitted(secretPath, err, recoveryFile, secretPath, recoveryFile)
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Rotated %s from version %d to version %d.\n", secretPath, old.Version, version.Version)
+	return nil
+}
+
+// flaggedSecrets returns the paths of all flagged secrets in the directory, sorted by path.
+func flaggedSecrets(client secrethub.ClientInterface, dirPath string) ([]string, error) {
+	tree, err := client.Dirs().GetTree(dirPath, -1, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for id, secret := range tree.Secrets {
+		// Policies and annotations are not values that can be rotated.
+		if secret.Status == api.StatusOK || isReservedSecretName(secret.Name) {
+			continue
+		}
+		path, err := tree.AbsSecretPath(id)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path.Value())
+	}
+	sort.Strings(paths)
+
+	return paths, nil
+}
+
+// rotationHookInput is passed to a rotation hook on stdin.
+type rotationHookInput struct {
+	Path       string `json:"path"`
+	OldVersion int    `json:"old_version"`
+	OldValue   string `json:"old_value"`
+	NewValue   string `json:"new_value"`
+}
+
+// saveRotationValue writes a value that could not be written to SecretHub to a new file
+// in the given directory, which is only readable by the current user, and returns its path.
+func saveRotationValue(dir string, value []byte) (string, error) {
+	file, err := ioutil.TempFile(dir, "secrethub-rotation-*.txt")
+	if err != nil {
+		return "", err
+	}
+
+	_, err = file.Write(value)
+	if err != nil {
+		_ = file.Close()
+		return "", err
+	}
+	return file.Name(), file.Close()
+}
+
+// runRotationHook runs the hook in the given directory with the input on stdin.
+// The output of the hook is written to output.
+func runRotationHook(hook []string, dir string, input []byte, output io.Writer) error {
+	command := exec.Command(hook[0], hook[1:]...)
+	command.Dir = dir
+	command.Stdin = bytes.NewReader(input)
+	command.Stdout = output
+	command.Stderr = output
+	return command.Run()
+}
+
+// rotationFile declares how secrets are rotated.
+type rotationFile struct {
+	Rotations []rotationSpec `yaml:"rotations"`
+}
+
+// rotationSpec declares how the secrets matching the path pattern are rotated.
+type rotationSpec struct {
+	Path      string       `yaml:"path"`
+	Generator string       `yaml:"generator"`
+	Length    int          `yaml:"length"`
+	Charsets  []string     `yaml:"charsets"`
+	Words     int          `yaml:"words"`
+	Separator *string      `yaml:"separator"`
+	Hook      rotationHook `yaml:"hook"`
+
+	charset randchar.Charset
+}
+
+// rotationHook is a command and its arguments. In the rotation file,
+// it can be a single string without arguments or a list of strings.
+type rotationHook []string
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (h *rotationHook) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var command string
+	err := unmarshal(&command)
+	if err == nil {
+		*h = rotationHook{command}
+		return nil
+	}
+
+	var args []string
+	err = unmarshal(&args)
+	if err != nil {
+		return err
+	}
+	*h = args
+	return nil
+}
+
+// readRotationFile reads and validates the rotation file at the given path.
+func readRotationFile(filePath string) (*rotationFile, error) {
+	raw, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, ErrCannotReadRotationFile(filePath, err)
+	}
+
+	var file rotationFile
+	err = yaml.UnmarshalStrict(raw, &file)
+	if err != nil {
+		return nil, ErrInvalidRotationFile(filePath, err)
+	}
+
+	for i, spec := range file.Rotations {
+		if spec.Path == "" {
+			return nil, ErrInvalidRotationFile(filePath, fmt.Sprintf("rotation %d has no path", i+1))
+		}
+		_, err = path.Match(spec.Path, "")
+		if err != nil {
+			return nil, ErrInvalidRotationFile(filePath, fmt.Sprintf("invalid path pattern %s: %v", spec.Path, err))
+		}
+
+		switch spec.Generator {
+		case "":
+			file.Rotations[i].Generator = rotationGeneratorRand
+		case rotationGeneratorRand, rotationGeneratorPassphrase:
+		default:
+			return nil, ErrUnknownRotationGenerator(spec.Generator, spec.Path)
+		}
+
+		for _, name := range spec.Charsets {
+			charset, ok := randchar.CharsetByName(name)
+			if !ok {
+				return nil, ErrCouldNotFindCharSet(name)
+			}
+			file.Rotations[i].charset = file.Rotations[i].charset.Add(charset)
+		}
+
+		for _, arg := range spec.Hook {
+			if arg == "" {
+				return nil, ErrInvalidRotationFile(filePath, fmt.Sprintf("the hook of %s contains an empty argument", spec.Path))
+			}
+		}
+	}
+
+	return &file, nil
+}
+
+// find returns the first rotation that matches the secret path, or nil if none matches.
+func (f *rotationFile) find(secretPath string) *rotationSpec {
+	for i, spec := range f.Rotations {
+		match, _ := path.Match(spec.Path, secretPath)
+		if match {
+			return &f.Rotations[i]
+		}
+	}
+	return nil
+}
+
+// generate generates a new value. The rand generator uses the password policy
+// for the settings that are not declared in the rotation.
+func (s *rotationSpec) generate(p *dirPolicy) ([]byte, error) {
+	if s.Generator == rotationGeneratorPassphrase {
+		words := s.Words
+		if words == 0 {
+			words = defaultPassphraseWords
+		}
+		separator := defaultPassphraseSeparator
+		if s.Separator != nil {
+			separator = *s.Separator
+		}
+
+		passphrase, err := keygen.Passphrase(words, separator)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(passphrase), nil
+	}
+
+	charset := randchar.Alphanumeric
+	if len(s.Charsets) > 0 {
+		charset = s.charset
+	}
+	length := s.Length
+	var options []randchar.Option
+	if p != nil {
+		if len(s.Charsets) == 0 {
+			charset = p.policy.Charset(charset)
+		}
+		options = p.policy.Options()
+		if length == 0 && p.policy.Length > defaultLength {
+			length = p.policy.Length
+		}
+	}
+	if length == 0 {
+		length = defaultLength
+	}
+
+	generator, err := randchar.NewRand(charset, options...)
+	if err != nil {
+		return nil, err
+	}
+	return generator.Generate(length)
+}
@@ -0,0 +1,260 @@
+package secrethub
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/api/uuid"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestRotateCommand_Run(t *testing.T) {
+	errWrite := errors.New("connection reset")
+	const rotations = `rotations:
+  - path: company/app/db_password
+    length: 30
+    charsets: [numeric]
+    hook: [./update.sh, --user, app]
+  - path: company/app/*
+    generator: passphrase
+    words: 3
+    separator: " "
+`
+	dir, cleanup := testdata.tempDir(t)
+	defer cleanup()
+
+	file := filepath.Join(dir, "secrethub.rotate.yml")
+	err := ioutil.WriteFile(file, []byte(rotations), 0600)
+	assert.OK(t, err)
+
+	rootID := uuid.New()
+	tree := &api.Tree{
+		ParentPath: "company",
+		RootDir:    &api.Dir{DirID: rootID, Name: "app"},
+		Dirs: map[uuid.UUID]*api.Dir{
+			rootID: {DirID: rootID, Name: "app"},
+		},
+		Secrets: map[uuid.UUID]*api.Secret{
+			uuid.New(): {DirID: rootID, Name: "db_password", Status: api.StatusFlagged},
+			uuid.New(): {DirID: rootID, Name: "api_key", Status: api.StatusFlagged},
+			uuid.New(): {DirID: rootID, Name: "untouched", Status: api.StatusOK},
+		},
+	}
+
+	cases := map[string]struct {
+		path           string
+		flagged        bool
+		hookErr        error
+		writeFailures  int
+		expectRecovery bool
+		expectedHooks  int
+		expectedWrite  []string
+		expectedOut    string
+		expectedErr    error
+	}{
+		"rotate with hook": {
+			path:          "company/app/db_password",
+			expectedHooks: 1,
+			expectedWrite: []string{"company/app/db_password"},
+			expectedOut: "Running the rotation hook for company/app/db_password...\n" +
+				"Updated the password of app.\n" +
+				"Rotated company/app/db_password from version 3 to version 4.\n",
+		},
+		"write fails once after hook": {
+			path:          "company/app/db_password",
+			writeFailures: 1,
+			expectedHooks: 1,
+			expectedWrite: []string{"company/app/db_password", "company/app/db_password"},
+			expectedOut: "Running the rotation hook for company/app/db_password...\n" +
+				"Updated the password of app.\n" +
+				"Rotated company/app/db_password from version 3 to version 4.\n",
+		},
+		"write fails after hook": {
+			path:           "company/app/db_password",
+			writeFailures:  rotationWriteAttempts,
+			expectRecovery: true,
+			expectedHooks:  1,
+			expectedWrite:  []string{"company/app/db_password", "company/app/db_password", "company/app/db_password"},
+			expectedOut: "Running the rotation hook for company/app/db_password...\n" +
+				"Updated the password of app.\n",
+			expectedErr: ErrRotationNotCommitted("company/app/db_password", errWrite, "<recovery>", "company/app/db_password", "<recovery>"),
+		},
+		"write fails without hook": {
+			path:          "company/app/api_key",
+			writeFailures: rotationWriteAttempts,
+			expectedWrite: []string{"company/app/api_key"},
+			expectedErr:   errWrite,
+		},
+		"rotate without hook": {
+			path:          "company/app/api_key",
+			expectedWrite: []string{"company/app/api_key"},
+			expectedOut:   "Rotated company/app/api_key from version 3 to version 4.\n",
+		},
+		"hook fails": {
+			path:          "company/app/db_password",
+			hookErr:       errors.New("exit status 1"),
+			expectedHooks: 1,
+			expectedOut:   "Running the rotation hook for company/app/db_password...\n",
+			expectedErr:   ErrRotationHookFailed("company/app/db_password", errors.New("exit status 1")),
+		},
+		"no rotation declared": {
+			path:        "company/other/secret",
+			expectedErr: ErrNoRotationSpec("company/other/secret", file),
+		},
+		"flagged": {
+			path:          "company/app",
+			flagged:       true,
+			expectedHooks: 1,
+			expectedWrite: []string{"company/app/api_key", "company/app/db_password"},
+			expectedOut: "Rotated company/app/api_key from version 3 to version 4.\n" +
+				"Running the rotation hook for company/app/db_password...\n" +
+				"Updated the password of app.\n" +
+				"Rotated company/app/db_password from version 3 to version 4.\n",
+		},
+		"flagged with failing hook": {
+			path:          "company/app",
+			flagged:       true,
+			hookErr:       errors.New("exit status 1"),
+			expectedHooks: 1,
+			expectedWrite: []string{"company/app/api_key"},
+			expectedOut: "Rotated company/app/api_key from version 3 to version 4.\n" +
+				"Running the rotation hook for company/app/db_password...\n" +
+				"Could not rotate company/app/db_password: " + ErrRotationHookFailed("company/app/db_password", errors.New("exit status 1")).Error() + "\n",
+			expectedErr: ErrRotationsFailed(1),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var written []string
+			var hooks []rotationHookInput
+			fakeIO := fakeui.NewIO(t)
+			cmd := RotateCommand{
+				io:      fakeIO,
+				path:    cli.StringValue{Value: tc.path},
+				file:    file,
+				flagged: tc.flagged,
+				runHook: func(hook []string, hookDir string, input []byte, output io.Writer) error {
+					assert.Equal(t, hook, []string{"./update.sh", "--user", "app"})
+					assert.Equal(t, hookDir, dir)
+					if tc.hookErr == nil {
+						fmt.Fprintln(output, "Updated the password of app.")
+					}
+
+					var in rotationHookInput
+					err := json.Unmarshal(input, &in)
+					assert.OK(t, err)
+					hooks = append(hooks, in)
+					return tc.hookErr
+				},
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						DirService: &fakeclient.DirService{
+							GetTreeFunc: func(path string, depth int, ancestors bool) (*api.Tree, error) {
+								return tree, nil
+							},
+						},
+						SecretService: &fakeclient.SecretService{
+							WriteFunc: func(path string, data []byte) (*api.SecretVersion, error) {
+								written = append(written, path)
+								if len(written) <= tc.writeFailures {
+									return nil, errWrite
+								}
+								return &api.SecretVersion{Version: 4}, nil
+							},
+							VersionService: &fakeclient.SecretVersionService{
+								GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+									if filepath.Base(path) == ".policy" {
+										return nil, api.ErrSecretNotFound
+									}
+									return &api.SecretVersion{Version: 3, Data: []byte("old value")}, nil
+								},
+							},
+						},
+					}, nil
+				},
+			}
+			err := cmd.Run()
+
+			recoveryFiles, globErr := filepath.Glob(filepath.Join(dir, "secrethub-rotation-*.txt"))
+			assert.OK(t, globErr)
+			assert.Equal(t, len(recoveryFiles) == 1, tc.expectRecovery)
+			if tc.expectRecovery && len(recoveryFiles) == 1 {
+				// The name of the recovery file is random.
+				err = errors.New(strings.ReplaceAll(err.Error(), recoveryFiles[0], "<recovery>"))
+				tc.expectedErr = errors.New(tc.expectedErr.Error())
+
+				info, statErr := os.Stat(recoveryFiles[0])
+				assert.OK(t, statErr)
+				assert.Equal(t, info.Mode().Perm(), os.FileMode(0600))
+				value, readErr := ioutil.ReadFile(recoveryFiles[0])
+				assert.OK(t, readErr)
+				assert.Equal(t, len(value), 30)
+				assert.OK(t, os.Remove(recoveryFiles[0]))
+			}
+
+			assert.Equal(t, err, tc.expectedErr)
+			assert.Equal(t, fakeIO.Out.String(), tc.expectedOut)
+			assert.Equal(t, written, tc.expectedWrite)
+			assert.Equal(t, len(hooks), tc.expectedHooks)
+			for _, hook := range hooks {
+				assert.Equal(t, hook.Path, "company/app/db_password")
+				assert.Equal(t, hook.OldVersion, 3)
+				assert.Equal(t, hook.OldValue, "old value")
+				assert.Equal(t, len(hook.NewValue), 30)
+			}
+		})
+	}
+}
+
+func TestReadRotationFile(t *testing.T) {
+	dir, cleanup := testdata.tempDir(t)
+	defer cleanup()
+
+	file := filepath.Join(dir, "rotate.yml")
+
+	cases := map[string]struct {
+		raw string
+		err error
+	}{
+		"hook as string": {
+			raw: "rotations:\n  - path: a/b/c\n    hook: ./update.sh",
+		},
+		"missing path": {
+			raw: "rotations:\n  - generator: rand",
+			err: ErrInvalidRotationFile(file, "rotation 1 has no path"),
+		},
+		"unknown generator": {
+			raw: "rotations:\n  - path: a/b/c\n    generator: uuid",
+			err: ErrUnknownRotationGenerator("uuid", "a/b/c"),
+		},
+		"unknown charset": {
+			raw: "rotations:\n  - path: a/b/c\n    charsets: [emoji]",
+			err: ErrCouldNotFindCharSet("emoji"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := ioutil.WriteFile(file, []byte(tc.raw), 0600)
+			assert.OK(t, err)
+
+			_, err = readRotationFile(file)
+
+			assert.Equal(t, err, tc.err)
+		})
+	}
+}
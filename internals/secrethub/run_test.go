@@ -721,6 +721,27 @@ func TestRunCommand_environment(t *testing.T) {
 			},
 			expectedEnv: []string{"TEST=test"},
 		},
+		"env file stage": {
+			command: RunCommand{
+				environment: &environment{
+					osStat:          osStatFunc("foo.env", nil),
+					envFile:         "foo.env",
+					templateVersion: "2",
+					stage:           "prod",
+					readFile:        readFileFunc("foo.env", "stages:\n  dev:\n    vars:\n      TEST: dev\n  prod:\n    extends: dev\n    vars:\n      TEST: prod\n"),
+				},
+			},
+			expectedEnv: []string{"TEST=prod"},
+		},
+		"env file stage without env file": {
+			command: RunCommand{
+				environment: &environment{
+					osStat: osStatFunc("secrethub.env", os.ErrNotExist),
+					stage:  "prod",
+				},
+			},
+			err: ErrStageWithoutEnvFile("prod"),
+		},
 		"env file secret does not exist": {
 			command: RunCommand{
 				command: cli.StringListValue{"echo", "test"},
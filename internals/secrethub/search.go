@@ -0,0 +1,371 @@
+package secrethub
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/policy"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+
+	"github.com/spf13/cobra"
+)
+
+// Errors
+var (
+	ErrInvalidSearchPattern = errMain.Code("invalid_search_pattern").ErrorPref("invalid search pattern %s: %v")
+	ErrInvalidSearchTime    = errMain.Code("invalid_search_time").ErrorPref("invalid time %s: use a date such as 2020-12-31, an RFC3339 timestamp or a duration such as 30d")
+)
+
+const (
+	// searchConcurrency is the maximum number of repositories that are searched at the same time.
+	searchConcurrency = 8
+
+	searchTypeSecret = "secret"
+	searchTypeDir    = "dir"
+)
+
+// SearchCommand finds secrets and directories by name in all repositories the account has access to.
+type SearchCommand struct {
+	io             ui.IO
+	pattern        cli.StringValue
+	regex          bool
+	modifiedAfter  string
+	modifiedBefore string
+	minVersions    int
+	maxVersions    int
+	format         string
+	useTimestamps  bool
+	timeFormatter  TimeFormatter
+	newClient      newClientFunc
+}
+
+// NewSearchCommand creates a new SearchCommand.
+func NewSearchCommand(io ui.IO, newClient newClientFunc) *SearchCommand {
+	return &SearchCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *SearchCommand) Register(r cli.Registerer) {
+	clause := r.Command("search", "Find secrets and directories by name in all repositories you have access to.")
+	clause.HelpLong("The pattern is a glob, in which * matches any sequence of characters except / and ? matches a single character, " +
+		"or a regular expression when --regex is set. Matching is case insensitive. " +
+		"A pattern is matched against the names of secrets and directories, unless it contains a /, " +
+		"in which case it is matched against their full paths.\n\n" +
+		"The modified filters take a date such as 2020-12-31, an RFC3339 timestamp or a duration such as 30d, " +
+		"which is counted back from now. A secret is modified when a version is written to it.")
+	clause.Flags().BoolVar(&cmd.regex, "regex", false, "Interpret the pattern as a regular expression instead of a glob.")
+	clause.Flags().StringVar(&cmd.modifiedAfter, "modified-after", "", "Only show secrets and directories that were last modified after this time.")
+	clause.Flags().StringVar(&cmd.modifiedBefore, "modified-before", "", "Only show secrets and directories that were last modified before this time.")
+	clause.Flags().IntVar(&cmd.minVersions, "min-versions", 0, "Only show secrets with at least this number of versions.")
+	clause.Flags().IntVar(&cmd.maxVersions, "max-versions", 0, "Only show secrets with at most this number of versions.")
+	clause.Flags().StringVar(&cmd.format, "output-format", formatTable, "Specify the format in which to output the results. Options are: table and json.")
+	_ = clause.Cmd.RegisterFlagCompletionFunc("output-format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{formatTable, formatJSON}, cobra.ShellCompDirectiveDefault
+	})
+	registerTimestampFlag(clause, &cmd.useTimestamps)
+
+	clause.BindAction(cmd.Run)
+	clause.BindArguments([]cli.Argument{{Value: &cmd.pattern, Name: "pattern", Required: true, Description: "The glob or regular expression to match the names or paths against."}})
+}
+
+// Run searches all repositories and prints the matches.
+func (cmd *SearchCommand) Run() error {
+	cmd.beforeRun()
+	return cmd.run()
+}
+
+// beforeRun configures the command using the flag values.
+func (cmd *SearchCommand) beforeRun() {
+	cmd.timeFormatter = NewTimeFormatter(cmd.useTimestamps || cmd.format == formatJSON)
+}
+
+// searchResult is a secret or directory that matches the search.
+type searchResult struct {
+	path         string
+	typ          string
+	versions     int
+	lastModified time.Time
+}
+
+// searchFilter decides which secrets and directories are part of the results.
+type searchFilter struct {
+	match          func(string) bool
+	matchPath      bool
+	modifiedAfter  time.Time
+	modifiedBefore time.Time
+	minVersions    int
+	maxVersions    int
+}
+
+// run searches all repositories and prints the matches.
+func (cmd *SearchCommand) run() error {
+	if cmd.format != formatTable && cmd.format != formatJSON {
+		return errNoSuchFormat(cmd.format)
+	}
+
+	filter, err := cmd.filter(time.Now())
+	if err != nil {
+		return err
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	repos, err := client.Repos().ListMine()
+	if err != nil {
+		return err
+	}
+
+	results, err := searchRepos(client, repos, filter)
+	if err != nil {
+		return err
+	}
+
+	if cmd.format == formatJSON {
+		formatter := newJSONFormatter(cmd.io.Output(), []string{"path", "type", "versions", "last modified"})
+		for _, result := range results {
+			err = formatter.Write(cmd.row(result))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(results) == 0 {
+		fmt.Fprintf(cmd.io.Output(), "No secrets or directories match %s.\n", cmd.pattern.Value)
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(cmd.io.Output(), 0, 4, 4, ' ', 0)
+	fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", "PATH", "TYPE", "VERSIONS", "LAST MODIFIED")
+	for _, result := range results {
+		fmt.Fprintln(tw, strings.Join(cmd.row(result), "\t"))
+	}
+	return tw.Flush()
+}
+
+// row returns the fields of a result to print.
+func (cmd *SearchCommand) row(result searchResult) []string {
+	versions := ""
+	if result.typ == searchTypeSecret {
+		versions = strconv.Itoa(result.versions)
+	}
+	return []string{result.path, result.typ, versions, cmd.timeFormatter.Format(result.lastModified.Local())}
+}
+
+// filter creates the filter from the pattern and flags.
+func (cmd *SearchCommand) filter(now time.Time) (*searchFilter, error) {
+	pattern := cmd.pattern.Value
+	filter := &searchFilter{
+		matchPath:   strings.Contains(pattern, "/"),
+		minVersions: cmd.minVersions,
+		maxVersions: cmd.maxVersions,
+	}
+
+	if cmd.regex {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, ErrInvalidSearchPattern(pattern, err)
+		}
+		filter.match = re.MatchString
+	} else {
+		glob := strings.ToLower(pattern)
+		_, err := path.Match(glob, "")
+		if err != nil {
+			return nil, ErrInvalidSearchPattern(pattern, err)
+		}
+		filter.match = func(s string) bool {
+			match, _ := path.Match(glob, strings.ToLower(s))
+			return match
+		}
+	}
+
+	var err error
+	if cmd.modifiedAfter != "" {
+		filter.modifiedAfter, err = parseSearchTime(cmd.modifiedAfter, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if cmd.modifiedBefore != "" {
+		filter.modifiedBefore, err = parseSearchTime(cmd.modifiedBefore, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return filter, nil
+}
+
+// parseSearchTime parses a date, an RFC3339 timestamp or a duration before now.
+func parseSearchTime(value string, now time.Time) (time.Time, error) {
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err == nil {
+		return t, nil
+	}
+
+	t, err = time.Parse(time.RFC3339, value)
+	if err == nil {
+		return t, nil
+	}
+
+	d, err := policy.ParseDuration(value)
+	if err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+
+	return time.Time{}, ErrInvalidSearchTime(value)
+}
+
+// searchRepos searches the repositories in parallel and returns the matches sorted by path.
+// Repositories that can no longer be read, e.g. because they were removed during the search, are skipped.
+func searchRepos(client secrethub.ClientInterface, repos []*api.Repo, filter *searchFilter) ([]searchResult, error) {
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	var results []searchResult
+	var firstErr error
+
+	semaphore := make(chan struct{}, searchConcurrency)
+	for _, repo := range repos {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(repoPath string) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+
+			found, err := searchRepo(client, repoPath, filter)
+			if api.IsErrNotFound(err) || err == api.ErrForbidden {
+				return
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			results = append(results, found...)
+		}(repo.Path().Value())
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].path < results[j].path
+	})
+	return results, nil
+}
+
+// searchRepo returns the secrets and directories in the repository that match the filter.
+func searchRepo(client secrethub.ClientInterface, repoPath string, filter *searchFilter) ([]searchResult, error) {
+	tree, err := client.Dirs().GetTree(repoPath, -1, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []searchResult
+	if filter.minVersions == 0 && filter.maxVersions == 0 {
+		for id, dir := range tree.Dirs {
+			if id == tree.RootDir.DirID {
+				continue
+			}
+			dirPath, err := tree.AbsDirPath(id)
+			if err != nil {
+				return nil, err
+			}
+			result := searchResult{
+				path:         dirPath.Value(),
+				typ:          searchTypeDir,
+				lastModified: dir.LastModifiedAt,
+			}
+			if filter.matchesName(dir.Name, result.path) && filter.matchesTime(result.lastModified) {
+				results = append(results, result)
+			}
+		}
+	}
+
+	for id, secret := range tree.Secrets {
+		if isReservedSecretName(secret.Name) {
+			continue
+		}
+		secretPath, err := tree.AbsSecretPath(id)
+		if err != nil {
+			return nil, err
+		}
+		result := searchResult{
+			path:     secretPath.Value(),
+			typ:      searchTypeSecret,
+			versions: secret.VersionCount,
+		}
+		if !filter.matchesName(secret.Name, result.path) || !filter.matchesVersions(result.versions) {
+			continue
+		}
+
+		// The tree does not contain the time the latest version was written,
+		// so it is only fetched for the secrets that match the name.
+		version, err := client.Secrets().Versions().GetWithoutData(result.path)
+		if err != nil {
+			return nil, err
+		}
+		result.lastModified = version.CreatedAt
+		if filter.matchesTime(result.lastModified) {
+			results = append(results, result)
+		}
+	}
+
+	return results, nil
+}
+
+// matchesName returns whether the name, or the path when the pattern contains a /, matches the pattern.
+func (f *searchFilter) matchesName(name string, path string) bool {
+	if f.matchPath {
+		return f.match(path)
+	}
+	return f.match(name)
+}
+
+// matchesVersions returns whether the version count is within the bounds of the filter.
+func (f *searchFilter) matchesVersions(versions int) bool {
+	if f.minVersions > 0 && versions < f.minVersions {
+		return false
+	}
+	if f.maxVersions > 0 && versions > f.maxVersions {
+		return false
+	}
+	return true
+}
+
+// matchesTime returns whether the time is within the bounds of the filter.
+func (f *searchFilter) matchesTime(t time.Time) bool {
+	if !f.modifiedAfter.IsZero() && !t.After(f.modifiedAfter) {
+		return false
+	}
+	if !f.modifiedBefore.IsZero() && !t.Before(f.modifiedBefore) {
+		return false
+	}
+	return true
+}
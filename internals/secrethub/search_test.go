@@ -0,0 +1,211 @@
+package secrethub
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/api/uuid"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestSearchCommand_Run(t *testing.T) {
+	recent := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+	old := time.Now().Add(-100 * 24 * time.Hour).Truncate(time.Second)
+	format := func(t time.Time) string {
+		return t.Local().Format(time.RFC3339)
+	}
+
+	repo1RootID := uuid.New()
+	paymentsID := uuid.New()
+	repo2RootID := uuid.New()
+	trees := map[string]*api.Tree{
+		"dev1/repo1": {
+			ParentPath: "dev1",
+			RootDir:    &api.Dir{DirID: repo1RootID, Name: "repo1"},
+			Dirs: map[uuid.UUID]*api.Dir{
+				repo1RootID: {DirID: repo1RootID, Name: "repo1", LastModifiedAt: recent},
+				paymentsID:  {DirID: paymentsID, ParentID: &repo1RootID, Name: "payments", LastModifiedAt: recent},
+			},
+			Secrets: map[uuid.UUID]*api.Secret{
+				uuid.New(): {DirID: paymentsID, Name: "stripe_api_key", VersionCount: 3},
+				uuid.New(): {DirID: repo1RootID, Name: "db_password", VersionCount: 1},
+				uuid.New(): {DirID: repo1RootID, Name: ".annotations", VersionCount: 1},
+			},
+		},
+		"dev1/repo2": {
+			ParentPath: "dev1",
+			RootDir:    &api.Dir{DirID: repo2RootID, Name: "repo2"},
+			Dirs: map[uuid.UUID]*api.Dir{
+				repo2RootID: {DirID: repo2RootID, Name: "repo2", LastModifiedAt: old},
+			},
+			Secrets: map[uuid.UUID]*api.Secret{
+				uuid.New(): {DirID: repo2RootID, Name: "Stripe_API_Key", VersionCount: 1},
+			},
+		},
+	}
+	modified := map[string]time.Time{
+		"dev1/repo1/payments/stripe_api_key": recent,
+		"dev1/repo1/db_password":             old,
+		"dev1/repo2/Stripe_API_Key":          old,
+	}
+
+	cases := map[string]struct {
+		cmd         SearchCommand
+		listErr     error
+		expectedOut string
+		expectedErr error
+	}{
+		"glob": {
+			cmd: SearchCommand{
+				pattern: cli.StringValue{Value: "stripe*"},
+			},
+			expectedOut: "PATH                                  TYPE      VERSIONS    LAST MODIFIED\n" +
+				"dev1/repo1/payments/stripe_api_key    secret    3           " + format(recent) + "\n" +
+				"dev1/repo2/Stripe_API_Key             secret    1           " + format(old) + "\n",
+		},
+		"regex": {
+			cmd: SearchCommand{
+				pattern: cli.StringValue{Value: "^(db|pay)"},
+				regex:   true,
+			},
+			expectedOut: "PATH                      TYPE      VERSIONS    LAST MODIFIED\n" +
+				"dev1/repo1/db_password    secret    1           " + format(old) + "\n" +
+				"dev1/repo1/payments       dir                   " + format(recent) + "\n",
+		},
+		"path pattern": {
+			cmd: SearchCommand{
+				pattern: cli.StringValue{Value: "dev1/repo1/*"},
+			},
+			expectedOut: "PATH                      TYPE      VERSIONS    LAST MODIFIED\n" +
+				"dev1/repo1/db_password    secret    1           " + format(old) + "\n" +
+				"dev1/repo1/payments       dir                   " + format(recent) + "\n",
+		},
+		"min versions": {
+			cmd: SearchCommand{
+				pattern:     cli.StringValue{Value: "*"},
+				minVersions: 2,
+			},
+			expectedOut: "PATH                                  TYPE      VERSIONS    LAST MODIFIED\n" +
+				"dev1/repo1/payments/stripe_api_key    secret    3           " + format(recent) + "\n",
+		},
+		"modified after": {
+			cmd: SearchCommand{
+				pattern:       cli.StringValue{Value: "*"},
+				modifiedAfter: "30d",
+			},
+			expectedOut: "PATH                                  TYPE      VERSIONS    LAST MODIFIED\n" +
+				"dev1/repo1/payments                   dir                   " + format(recent) + "\n" +
+				"dev1/repo1/payments/stripe_api_key    secret    3           " + format(recent) + "\n",
+		},
+		"modified before": {
+			cmd: SearchCommand{
+				pattern:        cli.StringValue{Value: "*"},
+				modifiedBefore: recent.Add(-time.Hour).Format(time.RFC3339),
+				maxVersions:    1,
+			},
+			expectedOut: "PATH                         TYPE      VERSIONS    LAST MODIFIED\n" +
+				"dev1/repo1/db_password       secret    1           " + format(old) + "\n" +
+				"dev1/repo2/Stripe_API_Key    secret    1           " + format(old) + "\n",
+		},
+		"json": {
+			cmd: SearchCommand{
+				pattern: cli.StringValue{Value: "db_*"},
+				format:  formatJSON,
+			},
+			expectedOut: `{"LastModified":"` + format(old) + `","Path":"dev1/repo1/db_password","Type":"secret","Versions":"1"}` + "\n",
+		},
+		"no matches": {
+			cmd: SearchCommand{
+				pattern: cli.StringValue{Value: "unknown"},
+			},
+			expectedOut: "No secrets or directories match unknown.\n",
+		},
+		"invalid regex": {
+			cmd: SearchCommand{
+				pattern: cli.StringValue{Value: "(db"},
+				regex:   true,
+			},
+			expectedErr: ErrInvalidSearchPattern("(db", func() error {
+				_, err := regexp.Compile("(?i)(db")
+				return err
+			}()),
+		},
+		"invalid time": {
+			cmd: SearchCommand{
+				pattern:       cli.StringValue{Value: "*"},
+				modifiedAfter: "last week",
+			},
+			expectedErr: ErrInvalidSearchTime("last week"),
+		},
+		"invalid format": {
+			cmd: SearchCommand{
+				pattern: cli.StringValue{Value: "*"},
+				format:  "yaml",
+			},
+			expectedErr: errNoSuchFormat("yaml"),
+		},
+		"list repos error": {
+			cmd: SearchCommand{
+				pattern: cli.StringValue{Value: "*"},
+			},
+			listErr:     errors.New("list repos error"),
+			expectedErr: errors.New("list repos error"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			io := fakeui.NewIO(t)
+			tc.cmd.io = io
+			tc.cmd.useTimestamps = true
+			if tc.cmd.format == "" {
+				tc.cmd.format = formatTable
+			}
+			tc.cmd.newClient = func() (secrethub.ClientInterface, error) {
+				return fakeclient.Client{
+					RepoService: &fakeclient.RepoService{
+						ListMineFunc: func() ([]*api.Repo, error) {
+							if tc.listErr != nil {
+								return nil, tc.listErr
+							}
+							return []*api.Repo{
+								{Owner: "dev1", Name: "repo1"},
+								{Owner: "dev1", Name: "repo2"},
+								{Owner: "dev1", Name: "removed"},
+							}, nil
+						},
+					},
+					DirService: &fakeclient.DirService{
+						GetTreeFunc: func(path string, depth int, ancestors bool) (*api.Tree, error) {
+							tree, ok := trees[path]
+							if !ok {
+								return nil, api.ErrDirNotFound
+							}
+							return tree, nil
+						},
+					},
+					SecretService: &fakeclient.SecretService{
+						VersionService: &fakeclient.SecretVersionService{
+							GetWithoutDataFunc: func(path string) (*api.SecretVersion, error) {
+								return &api.SecretVersion{CreatedAt: modified[path]}, nil
+							},
+						},
+					},
+				}, nil
+			}
+
+			err := tc.cmd.Run()
+
+			assert.Equal(t, err, tc.expectedErr)
+			assert.Equal(t, io.Out.String(), tc.expectedOut)
+		})
+	}
+}
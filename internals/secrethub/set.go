@@ -2,8 +2,11 @@ package secrethub
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"text/tabwriter"
 
 	"github.com/secrethub/secrethub-go/internals/api"
 
@@ -18,11 +21,14 @@ var (
 	ErrCannotReadFile    = errMain.Code("cannot_read_file").ErrorPref("cannot read file at %s: %v")
 	ErrSecretsNotCleared = errMain.Code("secrets_not_cleared").Error("exiting without having cleared all secrets")
 	ErrNoSourcesInSpec   = errMain.Code("no_sources_in_spec").Error("cannot find any sources in the .yml spec file")
+	ErrDriftDetected     = errMain.Code("drift_detected").ErrorPref("%d target(s) on disk differ from the spec")
 )
 
 // SetCommand parses a secret spec file and presents secrets on the system.
 type SetCommand struct {
 	in        string
+	check     bool
+	prune     bool
 	io        ui.IO
 	newClient newClientFunc
 }
@@ -39,6 +45,8 @@ func NewSetCommand(io ui.IO, newClient newClientFunc) *SetCommand {
 func (cmd *SetCommand) Register(r cli.Registerer) {
 	clause := r.Command("set", "Set the secrets in your local environment. This reads and parses the secrets.yml file in the current working directory.").Hidden()
 	clause.Flags().StringVarP(&cmd.in, "in", "i", "secrets.yml", "The path to a secrets.yml file to read")
+	clause.Flags().BoolVar(&cmd.check, "check", false, "Do not set any secrets, but report the targets on disk that differ from the spec. Exits with a non-zero exit code when drift is detected.")
+	clause.Flags().BoolVar(&cmd.prune, "prune", false, "Remove targets that were set before, but are no longer declared in the spec.")
 
 	clause.BindAction(cmd.Run)
 	clause.BindArguments(nil)
@@ -89,6 +97,30 @@ func (cmd *SetCommand) Run() error {
 		secrets[path] = *secret
 	}
 
+	statePath := cmd.statePath()
+	previous, err := secretspec.ReadState(statePath)
+	if err != nil {
+		return err
+	}
+
+	if cmd.check {
+		drift, err := presenter.Check(secrets, previous)
+		if err != nil {
+			return err
+		}
+
+		if len(drift) == 0 {
+			fmt.Fprintln(cmd.io.Output(), "No drift detected. All targets are up to date.")
+			return nil
+		}
+
+		err = printDrift(cmd.io.Output(), drift)
+		if err != nil {
+			return err
+		}
+		return ErrDriftDetected(len(drift))
+	}
+
 	fmt.Fprintln(cmd.io.Output(), "Setting secrets...")
 
 	err = presenter.Set(secrets)
@@ -96,7 +128,62 @@ func (cmd *SetCommand) Run() error {
 		return err
 	}
 
+	state, err := presenter.State(secrets)
+	if err != nil {
+		return err
+	}
+
+	if cmd.prune {
+		removed, err := presenter.Prune(secrets, previous)
+		for _, target := range removed {
+			fmt.Fprintf(cmd.io.Output(), "Removed orphaned target %s\n", target)
+		}
+		if err != nil {
+			return err
+		}
+	} else {
+		// Keep track of orphaned targets, so they can still be pruned later on.
+		for target, hash := range previous.Targets {
+			_, declared := state.Targets[target]
+			if !declared {
+				state.Targets[target] = hash
+			}
+		}
+	}
+
+	err = state.Write(statePath)
+	if err != nil {
+		return err
+	}
+
 	fmt.Fprintln(cmd.io.Output(), "Set complete! The secrets are now available on your system.")
 
 	return nil
 }
+
+// statePath returns the path of the file recording the targets that were set,
+// which is a hidden file next to the spec file.
+func (cmd *SetCommand) statePath() string {
+	return filepath.Join(filepath.Dir(cmd.in), "."+filepath.Base(cmd.in)+".state")
+}
+
+// printDrift writes a table with the drifted targets and their hashes to the given writer.
+func printDrift(w io.Writer, drift []secretspec.Drift) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 4, ' ', 0)
+	fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", "STATUS", "TARGET", "CURRENT", "EXPECTED")
+	for _, d := range drift {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", d.Status, d.Target, shortHash(d.CurrentHash), shortHash(d.ExpectedHash))
+	}
+	return tw.Flush()
+}
+
+// shortHash returns an abbreviated hash for display, or a dash when the hash is empty.
+func shortHash(hash string) string {
+	if hash == "" {
+		return "-"
+	}
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
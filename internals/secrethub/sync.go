@@ -0,0 +1,125 @@
+package secrethub
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/policy"
+)
+
+// Errors
+var (
+	ErrSyncConflicts        = errMain.Code("sync_conflicts").ErrorPref("%s changed both locally and on SecretHub since the last sync. Use --force to overwrite the %s")
+	ErrSyncDirMismatch      = errMain.Code("sync_dir_mismatch").ErrorPref("%s is synced with %s, not with %s. Remove %s to sync it with another directory")
+	ErrInvalidSyncStateFile = errMain.Code("invalid_sync_state_file").ErrorPref("cannot read the sync state in %s: %v")
+)
+
+// syncStateFileName is the name of the file in the local directory that
+// records the state of every entry at the last sync.
+const syncStateFileName = ".secrethub-sync.json"
+
+// SyncCommand handles syncing a local directory with a directory on SecretHub.
+type SyncCommand struct {
+	io        ui.IO
+	newClient newClientFunc
+}
+
+// NewSyncCommand creates a new SyncCommand.
+func NewSyncCommand(io ui.IO, newClient newClientFunc) *SyncCommand {
+	return &SyncCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command and its sub-commands on the provided Registerer.
+func (cmd *SyncCommand) Register(r cli.Registerer) {
+	clause := r.Command("sync", "Sync a local directory with a directory on SecretHub.")
+	clause.HelpLong("Every secret in the directory and its subdirectories maps to a file at the same relative path in the local directory. " +
+		"pull writes the secrets to the files and push writes the changed files as new versions of the secrets.\n\n" +
+		"The version and content hash of every entry at the last sync are recorded in " + syncStateFileName + " in the local directory. " +
+		"They are used to skip the entries that did not change and to detect entries that changed on both sides since the last sync. " +
+		"Such conflicting entries are not overwritten unless --force is set.\n\n" +
+		"The " + annotationsFileName + " and " + policy.FileName + " secrets that hold the annotations and policy of a directory are not synced.")
+	NewSyncPullCommand(cmd.io, cmd.newClient).Register(clause)
+	NewSyncPushCommand(cmd.io, cmd.newClient).Register(clause)
+}
+
+// syncState is the state of the entries of a local directory at the last sync.
+type syncState struct {
+	Dir     string               `json:"dir"`
+	Entries map[string]syncEntry `json:"entries"`
+}
+
+// syncEntry is the state of a single secret and file at the last sync.
+type syncEntry struct {
+	Version int    `json:"version"`
+	SHA256  string `json:"sha256"`
+}
+
+// readSyncState reads the sync state of the local directory.
+// A local directory that was never synced returns an empty state.
+func readSyncState(localDir string, dirPath string) (*syncState, error) {
+	file := filepath.Join(localDir, syncStateFileName)
+	raw, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return &syncState{
+			Dir:     dirPath,
+			Entries: map[string]syncEntry{},
+		}, nil
+	} else if err != nil {
+		return nil, ErrReadFile(file, err)
+	}
+
+	state := &syncState{}
+	err = json.Unmarshal(raw, state)
+	if err != nil {
+		return nil, ErrInvalidSyncStateFile(file, err)
+	}
+	if !strings.EqualFold(state.Dir, dirPath) {
+		return nil, ErrSyncDirMismatch(localDir, state.Dir, dirPath, file)
+	}
+	if state.Entries == nil {
+		state.Entries = map[string]syncEntry{}
+	}
+	return state, nil
+}
+
+// write writes the sync state to the local directory.
+func (s *syncState) write(localDir string) error {
+	raw, err := json.MarshalIndent(s, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	file := filepath.Join(localDir, syncStateFileName)
+	err = ioutil.WriteFile(file, raw, 0600)
+	if err != nil {
+		return ErrCannotWrite(file, err)
+	}
+	return nil
+}
+
+// hashContent returns the hex encoded SHA-256 hash of the content.
+func hashContent(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// printSyncConflicts prints the entries that changed on both sides, sorted.
+func printSyncConflicts(io ui.IO, conflicts []string) {
+	sort.Strings(conflicts)
+	fmt.Fprintln(io.Output(), "The following entries changed both locally and on SecretHub since the last sync:")
+	for _, conflict := range conflicts {
+		fmt.Fprintf(io.Output(), "  %s\n", conflict)
+	}
+}
@@ -0,0 +1,177 @@
+package secrethub
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/filemode"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/pkg/secretpath"
+)
+
+// SyncPullCommand writes the secrets in a directory to files in a local directory.
+type SyncPullCommand struct {
+	io        ui.IO
+	path      api.DirPath
+	localDir  cli.StringValue
+	fileMode  filemode.FileMode
+	force     bool
+	newClient newClientFunc
+}
+
+// NewSyncPullCommand creates a new SyncPullCommand.
+func NewSyncPullCommand(io ui.IO, newClient newClientFunc) *SyncPullCommand {
+	return &SyncPullCommand{
+		io:        io,
+		fileMode:  filemode.New(0600),
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *SyncPullCommand) Register(r cli.Registerer) {
+	clause := r.Command("pull", "Write the secrets in a directory to files in a local directory.")
+	clause.HelpLong("Every secret in the directory and its subdirectories is written to the file at the same relative path in the local directory. " +
+		"Secrets that did not change since the last sync are skipped. " +
+		"Files that were changed locally since the last sync are not overwritten, unless --force is set.")
+	clause.Flags().VarPF(&cmd.fileMode, "file-mode", "", "Set filemode for the written files.")
+	clause.Flags().BoolVarP(&cmd.force, "force", "f", false, "Overwrite files that were changed locally since the last sync.")
+
+	clause.BindAction(cmd.Run)
+	clause.BindArguments([]cli.Argument{
+		{Value: &cmd.path, Name: "dir-path", Required: true, Placeholder: optionalDirPathPlaceHolder, Description: "The path to the directory to pull."},
+		{Value: &cmd.localDir, Name: "local-dir", Required: true, Description: "The local directory to write the files to."},
+	})
+}
+
+// pulledSecret is a secret that is written to a local file.
+type pulledSecret struct {
+	name    string
+	file    string
+	version *api.SecretVersion
+}
+
+// Run writes the changed secrets to the local directory.
+func (cmd *SyncPullCommand) Run() error {
+	localDir := cmd.localDir.Value
+	state, err := readSyncState(localDir, cmd.path.Value())
+	if err != nil {
+		return err
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	tree, err := client.Dirs().GetTree(cmd.path.Value(), -1, false)
+	if err != nil {
+		return err
+	}
+
+	var pulls []pulledSecret
+	var conflicts []string
+	unchanged := 0
+	names := make(map[string]bool, len(tree.Secrets))
+	for id, secret := range tree.Secrets {
+		// Changing these files locally would overwrite the annotations or policy of the whole directory on the next push.
+		if isReservedSecretName(secret.Name) {
+			continue
+		}
+		secretPath, err := tree.AbsSecretPath(id)
+		if err != nil {
+			return err
+		}
+
+		// The tree is rooted at the pulled directory, so this is the path relative to it.
+		parts := strings.SplitN(secretPath.Value(), "/", secretpath.Count(cmd.path.Value())+1)
+		name := parts[len(parts)-1]
+		names[name] = true
+
+		file := filepath.Join(localDir, filepath.FromSlash(name))
+		local, err := ioutil.ReadFile(file)
+		exists := err == nil
+		if err != nil && !os.IsNotExist(err) {
+			return ErrReadFile(file, err)
+		}
+
+		entry, synced := state.Entries[name]
+		localHash := hashContent(local)
+		if exists && synced && entry.Version == secret.LatestVersion && entry.SHA256 == localHash {
+			unchanged++
+			continue
+		}
+
+		version, err := client.Secrets().Versions().GetWithData(secretPath.Value())
+		if err != nil {
+			return err
+		}
+
+		remoteHash := hashContent(version.Data)
+		if exists && localHash == remoteHash {
+			state.Entries[name] = syncEntry{Version: version.Version, SHA256: remoteHash}
+			unchanged++
+			continue
+		}
+
+		if exists && (!synced || entry.SHA256 != localHash) && !cmd.force {
+			conflicts = append(conflicts, file)
+			continue
+		}
+
+		pulls = append(pulls, pulledSecret{name: name, file: file, version: version})
+	}
+
+	if len(conflicts) > 0 {
+		printSyncConflicts(cmd.io, conflicts)
+		return ErrSyncConflicts(pluralize("file", "files", len(conflicts)), "local files")
+	}
+
+	sort.Slice(pulls, func(i, j int) bool {
+		return pulls[i].name < pulls[j].name
+	})
+	for _, pull := range pulls {
+		err = os.MkdirAll(filepath.Dir(pull.file), 0700)
+		if err != nil {
+			return ErrCannotWrite(pull.file, err)
+		}
+
+		err = ioutil.WriteFile(pull.file, pull.version.Data, cmd.fileMode.FileMode())
+		if err != nil {
+			return ErrCannotWrite(pull.file, err)
+		}
+		// WriteFile only sets the mode of new files.
+		err = os.Chmod(pull.file, cmd.fileMode.FileMode())
+		if err != nil {
+			return ErrCannotWrite(pull.file, err)
+		}
+
+		state.Entries[pull.name] = syncEntry{
+			Version: pull.version.Version,
+			SHA256:  hashContent(pull.version.Data),
+		}
+		fmt.Fprintf(cmd.io.Output(), "Pulled %s\n", pull.file)
+	}
+
+	// Secrets that no longer exist are no longer synced.
+	for name := range state.Entries {
+		if !names[name] {
+			delete(state.Entries, name)
+		}
+	}
+
+	err = state.write(localDir)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Pull complete! %s written and %d unchanged in %s.\n", pluralize("file", "files", len(pulls)), unchanged, localDir)
+	return nil
+}
@@ -0,0 +1,260 @@
+package secrethub
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/filemode"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/api/uuid"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+// writeSyncTestDir writes the files and, when it is not nil, the sync state to the local directory.
+func writeSyncTestDir(t *testing.T, dir string, files map[string]string, state *syncState) {
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		err := os.MkdirAll(filepath.Dir(file), 0700)
+		assert.OK(t, err)
+		err = ioutil.WriteFile(file, []byte(content), 0600)
+		assert.OK(t, err)
+	}
+	if state != nil {
+		err := state.write(dir)
+		assert.OK(t, err)
+	}
+}
+
+// readSyncTestState reads the sync state in the local directory, or nil when there is none.
+func readSyncTestState(t *testing.T, dir string) *syncState {
+	raw, err := ioutil.ReadFile(filepath.Join(dir, syncStateFileName))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	assert.OK(t, err)
+
+	state := &syncState{}
+	err = json.Unmarshal(raw, state)
+	assert.OK(t, err)
+	return state
+}
+
+func TestSyncPullCommand_Run(t *testing.T) {
+	rootID := uuid.New()
+	subID := uuid.New()
+	tree := &api.Tree{
+		ParentPath: "dev1/repo",
+		RootDir:    &api.Dir{DirID: rootID, Name: "app"},
+		Dirs: map[uuid.UUID]*api.Dir{
+			rootID: {DirID: rootID, Name: "app"},
+			subID:  {DirID: subID, ParentID: &rootID, Name: "sub"},
+		},
+		Secrets: map[uuid.UUID]*api.Secret{
+			uuid.New(): {DirID: rootID, Name: "a", LatestVersion: 2},
+			uuid.New(): {DirID: subID, Name: "b", LatestVersion: 1},
+			uuid.New(): {DirID: rootID, Name: ".annotations", LatestVersion: 1},
+			uuid.New(): {DirID: subID, Name: ".policy", LatestVersion: 1},
+		},
+	}
+	remote := map[string]*api.SecretVersion{
+		"dev1/repo/app/a":            {Version: 2, Data: []byte("alpha")},
+		"dev1/repo/app/sub/b":        {Version: 1, Data: []byte("beta")},
+		"dev1/repo/app/.annotations": {Version: 1, Data: []byte("a:\n  owner: ops\n")},
+		"dev1/repo/app/sub/.policy":  {Version: 1, Data: []byte("length: 8\n")},
+	}
+
+	synced := map[string]syncEntry{
+		"a":     {Version: 2, SHA256: hashContent([]byte("alpha"))},
+		"sub/b": {Version: 1, SHA256: hashContent([]byte("beta"))},
+	}
+
+	cases := map[string]struct {
+		files         map[string]string
+		state         *syncState
+		force         bool
+		fileMode      os.FileMode
+		noReads       bool
+		expectedFiles map[string]string
+		expectedState *syncState
+		expectedOut   string
+		expectedErr   error
+	}{
+		"first pull": {
+			fileMode: 0640,
+			expectedFiles: map[string]string{
+				"a":     "alpha",
+				"sub/b": "beta",
+			},
+			expectedState: &syncState{Dir: "dev1/repo/app", Entries: synced},
+			expectedOut: "Pulled <dir>/a\n" +
+				"Pulled <dir>/sub/b\n" +
+				"Pull complete! 2 files written and 0 unchanged in <dir>.\n",
+		},
+		"unchanged": {
+			files: map[string]string{
+				"a":     "alpha",
+				"sub/b": "beta",
+			},
+			state:   &syncState{Dir: "dev1/repo/app", Entries: synced},
+			noReads: true,
+			expectedFiles: map[string]string{
+				"a":     "alpha",
+				"sub/b": "beta",
+			},
+			expectedState: &syncState{Dir: "dev1/repo/app", Entries: synced},
+			expectedOut:   "Pull complete! 0 files written and 2 unchanged in <dir>.\n",
+		},
+		"changed on secrethub": {
+			files: map[string]string{
+				"a":     "old",
+				"sub/b": "beta",
+				"other": "not synced",
+			},
+			state: &syncState{Dir: "dev1/repo/app", Entries: map[string]syncEntry{
+				"a":       {Version: 1, SHA256: hashContent([]byte("old"))},
+				"sub/b":   synced["sub/b"],
+				"removed": {Version: 1, SHA256: hashContent([]byte("removed"))},
+			}},
+			expectedFiles: map[string]string{
+				"a":     "alpha",
+				"sub/b": "beta",
+				"other": "not synced",
+			},
+			expectedState: &syncState{Dir: "dev1/repo/app", Entries: synced},
+			expectedOut: "Pulled <dir>/a\n" +
+				"Pull complete! 1 file written and 1 unchanged in <dir>.\n",
+		},
+		"changed locally": {
+			files: map[string]string{
+				"a":     "edited",
+				"sub/b": "beta",
+			},
+			state: &syncState{Dir: "dev1/repo/app", Entries: synced},
+			expectedFiles: map[string]string{
+				"a":     "edited",
+				"sub/b": "beta",
+			},
+			expectedState: &syncState{Dir: "dev1/repo/app", Entries: synced},
+			expectedOut: "The following entries changed both locally and on SecretHub since the last sync:\n" +
+				"  <dir>/a\n",
+			expectedErr: ErrSyncConflicts("1 file", "local files"),
+		},
+		"never synced": {
+			files: map[string]string{
+				"a": "local",
+			},
+			expectedFiles: map[string]string{
+				"a": "local",
+			},
+			expectedOut: "The following entries changed both locally and on SecretHub since the last sync:\n" +
+				"  <dir>/a\n",
+			expectedErr: ErrSyncConflicts("1 file", "local files"),
+		},
+		"force": {
+			files: map[string]string{
+				"a": "edited",
+			},
+			state: &syncState{Dir: "dev1/repo/app", Entries: synced},
+			force: true,
+			expectedFiles: map[string]string{
+				"a":     "alpha",
+				"sub/b": "beta",
+			},
+			expectedState: &syncState{Dir: "dev1/repo/app", Entries: synced},
+			expectedOut: "Pulled <dir>/a\n" +
+				"Pulled <dir>/sub/b\n" +
+				"Pull complete! 2 files written and 0 unchanged in <dir>.\n",
+		},
+		"synced with other dir": {
+			state:         &syncState{Dir: "dev1/repo/other"},
+			expectedFiles: map[string]string{},
+			expectedState: &syncState{Dir: "dev1/repo/other"},
+			expectedErr:   ErrSyncDirMismatch("<dir>", "dev1/repo/other", "dev1/repo/app", "<dir>/"+syncStateFileName),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir, cleanup := testdata.tempDir(t)
+			defer cleanup()
+			writeSyncTestDir(t, dir, tc.files, tc.state)
+
+			if tc.fileMode == 0 {
+				tc.fileMode = 0600
+			}
+
+			io := fakeui.NewIO(t)
+			cmd := SyncPullCommand{
+				io:       io,
+				path:     "dev1/repo/app",
+				localDir: cli.StringValue{Value: dir},
+				fileMode: filemode.New(tc.fileMode),
+				force:    tc.force,
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						DirService: &fakeclient.DirService{
+							GetTreeFunc: func(path string, depth int, ancestors bool) (*api.Tree, error) {
+								assert.Equal(t, path, "dev1/repo/app")
+								return tree, nil
+							},
+						},
+						SecretService: &fakeclient.SecretService{
+							VersionService: &fakeclient.SecretVersionService{
+								GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+									if tc.noReads {
+										return nil, errors.New("unchanged secrets should not be read")
+									}
+									return remote[path], nil
+								},
+							},
+						},
+					}, nil
+				},
+			}
+
+			err := cmd.Run()
+
+			if err != nil {
+				err = errors.New(strings.ReplaceAll(err.Error(), dir, "<dir>"))
+			}
+			if tc.expectedErr != nil {
+				tc.expectedErr = errors.New(tc.expectedErr.Error())
+			}
+			assert.Equal(t, err, tc.expectedErr)
+			assert.Equal(t, strings.ReplaceAll(io.Out.String(), dir, "<dir>"), tc.expectedOut)
+
+			files := map[string]string{}
+			for _, file := range mustReadLocalFiles(t, dir) {
+				files[file.name] = string(file.data)
+				if _, ok := tc.files[file.name]; !ok {
+					info, err := os.Stat(file.file)
+					assert.OK(t, err)
+					assert.Equal(t, info.Mode().Perm(), tc.fileMode)
+				}
+			}
+			assert.Equal(t, files, tc.expectedFiles)
+
+			if tc.expectedState == nil {
+				tc.expectedState = tc.state
+			}
+			assert.Equal(t, readSyncTestState(t, dir), tc.expectedState)
+		})
+	}
+}
+
+// mustReadLocalFiles reads the files in the local directory and fails the test on an error.
+func mustReadLocalFiles(t *testing.T, dir string) []localFile {
+	files, err := readLocalFiles(dir)
+	assert.OK(t, err)
+	return files
+}
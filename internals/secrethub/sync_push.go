@@ -0,0 +1,203 @@
+package secrethub
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/pkg/secretpath"
+)
+
+// Errors
+var (
+	ErrCannotPushFile = errMain.Code("cannot_push_file").ErrorPref("cannot push %s: %v")
+)
+
+// SyncPushCommand writes the changed files in a local directory as new versions of the secrets in a directory.
+type SyncPushCommand struct {
+	io        ui.IO
+	localDir  cli.StringValue
+	path      api.DirPath
+	force     bool
+	newClient newClientFunc
+}
+
+// NewSyncPushCommand creates a new SyncPushCommand.
+func NewSyncPushCommand(io ui.IO, newClient newClientFunc) *SyncPushCommand {
+	return &SyncPushCommand{
+		io:        io,
+		newClient: newClient,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *SyncPushCommand) Register(r cli.Registerer) {
+	clause := r.Command("push", "Write the changed files in a local directory to the secrets in a directory.")
+	clause.HelpLong("Every file in the local directory and its subdirectories is written to the secret at the same relative path in the directory. " +
+		"Files that did not change since the last sync are skipped and missing directories are created. " +
+		"Secrets that were changed on SecretHub since the last sync are not overwritten, unless --force is set. " +
+		"Secrets of which the file was removed are not removed.")
+	clause.Flags().BoolVarP(&cmd.force, "force", "f", false, "Overwrite secrets that were changed on SecretHub since the last sync.")
+
+	clause.BindAction(cmd.Run)
+	clause.BindArguments([]cli.Argument{
+		{Value: &cmd.localDir, Name: "local-dir", Required: true, Description: "The local directory to read the files from."},
+		{Value: &cmd.path, Name: "dir-path", Required: true, Placeholder: optionalDirPathPlaceHolder, Description: "The path to the directory to push to."},
+	})
+}
+
+// localFile is a file in the local directory that is synced.
+type localFile struct {
+	name string
+	file string
+	data []byte
+}
+
+// Run writes the changed files in the local directory to the directory.
+func (cmd *SyncPushCommand) Run() error {
+	localDir := cmd.localDir.Value
+	state, err := readSyncState(localDir, cmd.path.Value())
+	if err != nil {
+		return err
+	}
+
+	files, err := readLocalFiles(localDir)
+	if err != nil {
+		return err
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	var pushes []localFile
+	var conflicts []string
+	unchanged := 0
+	for _, file := range files {
+		path := secretpath.Join(cmd.path.Value(), file.name)
+		_, err = api.NewSecretPath(path)
+		if err != nil {
+			return ErrCannotPushFile(file.file, err)
+		}
+		if len(strings.TrimSpace(string(file.data))) == 0 {
+			return ErrCannotPushFile(file.file, errEmptySecret)
+		}
+
+		entry, synced := state.Entries[file.name]
+		localHash := hashContent(file.data)
+		if synced && entry.SHA256 == localHash {
+			unchanged++
+			continue
+		}
+
+		remote, err := client.Secrets().Versions().GetWithData(path)
+		exists := err == nil
+		if err != nil && !api.IsErrNotFound(err) {
+			return err
+		}
+
+		if exists && hashContent(remote.Data) == localHash {
+			state.Entries[file.name] = syncEntry{Version: remote.Version, SHA256: localHash}
+			unchanged++
+			continue
+		}
+
+		if exists && (!synced || entry.Version != remote.Version) && !cmd.force {
+			conflicts = append(conflicts, path)
+			continue
+		}
+
+		pushes = append(pushes, file)
+	}
+
+	if len(conflicts) > 0 {
+		printSyncConflicts(cmd.io, conflicts)
+		return ErrSyncConflicts(pluralize("secret", "secrets", len(conflicts)), "secrets")
+	}
+
+	secrets := make([]importedSecret, len(pushes))
+	for i, push := range pushes {
+		secrets[i] = importedSecret{path: secretpath.Join(cmd.path.Value(), push.name), value: push.data}
+	}
+
+	// All values are checked before anything is written,
+	// so a violation does not leave the directory half pushed.
+	finder := newPolicyFinder(client)
+	for _, secret := range secrets {
+		err = finder.check(secret.path, secret.value)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = createImportDirs(client, secrets)
+	if err != nil {
+		return err
+	}
+
+	for i, secret := range secrets {
+		version, err := client.Secrets().Write(secret.path, secret.value)
+		if err != nil {
+			return err
+		}
+
+		state.Entries[pushes[i].name] = syncEntry{
+			Version: version.Version,
+			SHA256:  hashContent(secret.value),
+		}
+		fmt.Fprintf(cmd.io.Output(), "Pushed %s\n", secret.path)
+	}
+
+	err = state.write(localDir)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Push complete! %s written and %d unchanged in %s.\n", pluralize("secret", "secrets", len(secrets)), unchanged, cmd.path)
+	return nil
+}
+
+// readLocalFiles reads the regular files in the local directory and its subdirectories, sorted by name.
+// The sync state file and the files named after the annotations or policy of a directory are skipped.
+func readLocalFiles(localDir string) ([]localFile, error) {
+	var files []localFile
+	err := filepath.Walk(localDir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(localDir, file)
+		if err != nil {
+			return err
+		}
+		if rel == syncStateFileName || isReservedSecretName(info.Name()) {
+			return nil
+		}
+
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return ErrReadFile(file, err)
+		}
+
+		files = append(files, localFile{
+			name: filepath.ToSlash(rel),
+			file: file,
+			data: data,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
@@ -0,0 +1,205 @@
+package secrethub
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestSyncPushCommand_Run(t *testing.T) {
+	synced := map[string]syncEntry{
+		"a":     {Version: 2, SHA256: hashContent([]byte("alpha"))},
+		"sub/b": {Version: 1, SHA256: hashContent([]byte("beta"))},
+	}
+
+	cases := map[string]struct {
+		files           map[string]string
+		state           *syncState
+		remote          map[string]*api.SecretVersion
+		force           bool
+		expectedCreated []string
+		expectedWritten map[string]string
+		expectedState   *syncState
+		expectedOut     string
+		expectedErr     error
+	}{
+		"first push": {
+			files: map[string]string{
+				"a":            "alpha",
+				"sub/b":        "beta",
+				".annotations": "a:\n  owner: ops\n",
+				"sub/.policy":  "length: 8\n",
+			},
+			expectedCreated: []string{"dev1/repo/app", "dev1/repo/app/sub"},
+			expectedWritten: map[string]string{
+				"dev1/repo/app/a":     "alpha",
+				"dev1/repo/app/sub/b": "beta",
+			},
+			expectedState: &syncState{Dir: "dev1/repo/app", Entries: map[string]syncEntry{
+				"a":     {Version: 1, SHA256: hashContent([]byte("alpha"))},
+				"sub/b": {Version: 1, SHA256: hashContent([]byte("beta"))},
+			}},
+			expectedOut: "Pushed dev1/repo/app/a\n" +
+				"Pushed dev1/repo/app/sub/b\n" +
+				"Push complete! 2 secrets written and 0 unchanged in dev1/repo/app.\n",
+		},
+		"unchanged": {
+			files: map[string]string{
+				"a":     "alpha",
+				"sub/b": "beta",
+			},
+			state:         &syncState{Dir: "dev1/repo/app", Entries: synced},
+			expectedState: &syncState{Dir: "dev1/repo/app", Entries: synced},
+			expectedOut:   "Push complete! 0 secrets written and 2 unchanged in dev1/repo/app.\n",
+		},
+		"changed locally": {
+			files: map[string]string{
+				"a":     "edited",
+				"sub/b": "beta",
+			},
+			state: &syncState{Dir: "dev1/repo/app", Entries: synced},
+			remote: map[string]*api.SecretVersion{
+				"dev1/repo/app/a": {Version: 2, Data: []byte("alpha")},
+			},
+			expectedWritten: map[string]string{
+				"dev1/repo/app/a": "edited",
+			},
+			expectedState: &syncState{Dir: "dev1/repo/app", Entries: map[string]syncEntry{
+				"a":     {Version: 3, SHA256: hashContent([]byte("edited"))},
+				"sub/b": synced["sub/b"],
+			}},
+			expectedOut: "Pushed dev1/repo/app/a\n" +
+				"Push complete! 1 secret written and 1 unchanged in dev1/repo/app.\n",
+		},
+		"same change on both sides": {
+			files: map[string]string{
+				"a": "edited",
+			},
+			state: &syncState{Dir: "dev1/repo/app", Entries: synced},
+			remote: map[string]*api.SecretVersion{
+				"dev1/repo/app/a": {Version: 3, Data: []byte("edited")},
+			},
+			expectedState: &syncState{Dir: "dev1/repo/app", Entries: map[string]syncEntry{
+				"a":     {Version: 3, SHA256: hashContent([]byte("edited"))},
+				"sub/b": synced["sub/b"],
+			}},
+			expectedOut: "Push complete! 0 secrets written and 1 unchanged in dev1/repo/app.\n",
+		},
+		"changed on both sides": {
+			files: map[string]string{
+				"a": "edited",
+			},
+			state: &syncState{Dir: "dev1/repo/app", Entries: synced},
+			remote: map[string]*api.SecretVersion{
+				"dev1/repo/app/a": {Version: 3, Data: []byte("rotated")},
+			},
+			expectedState: &syncState{Dir: "dev1/repo/app", Entries: synced},
+			expectedOut: "The following entries changed both locally and on SecretHub since the last sync:\n" +
+				"  dev1/repo/app/a\n",
+			expectedErr: ErrSyncConflicts("1 secret", "secrets"),
+		},
+		"force": {
+			files: map[string]string{
+				"a": "edited",
+			},
+			state: &syncState{Dir: "dev1/repo/app", Entries: synced},
+			remote: map[string]*api.SecretVersion{
+				"dev1/repo/app/a": {Version: 3, Data: []byte("rotated")},
+			},
+			force: true,
+			expectedWritten: map[string]string{
+				"dev1/repo/app/a": "edited",
+			},
+			expectedState: &syncState{Dir: "dev1/repo/app", Entries: map[string]syncEntry{
+				"a":     {Version: 4, SHA256: hashContent([]byte("edited"))},
+				"sub/b": synced["sub/b"],
+			}},
+			expectedOut: "Pushed dev1/repo/app/a\n" +
+				"Push complete! 1 secret written and 0 unchanged in dev1/repo/app.\n",
+		},
+		"empty file": {
+			files: map[string]string{
+				"a": "\n",
+			},
+			expectedErr: ErrCannotPushFile("<dir>/a", errEmptySecret),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir, cleanup := testdata.tempDir(t)
+			defer cleanup()
+			writeSyncTestDir(t, dir, tc.files, tc.state)
+
+			var created []string
+			written := map[string]string{}
+
+			io := fakeui.NewIO(t)
+			cmd := SyncPushCommand{
+				io:       io,
+				localDir: cli.StringValue{Value: dir},
+				path:     "dev1/repo/app",
+				force:    tc.force,
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						DirService: &fakeclient.DirService{
+							ExistsFunc: func(path string) (bool, error) {
+								return tc.remote != nil, nil
+							},
+							CreateFunc: func(path string) (*api.Dir, error) {
+								created = append(created, path)
+								return &api.Dir{}, nil
+							},
+						},
+						SecretService: &fakeclient.SecretService{
+							WriteFunc: func(path string, data []byte) (*api.SecretVersion, error) {
+								written[path] = string(data)
+								version := 1
+								if remote, ok := tc.remote[path]; ok {
+									version = remote.Version + 1
+								}
+								return &api.SecretVersion{Version: version}, nil
+							},
+							VersionService: &fakeclient.SecretVersionService{
+								GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+									version, ok := tc.remote[path]
+									if !ok {
+										return nil, api.ErrSecretNotFound
+									}
+									return version, nil
+								},
+							},
+						},
+					}, nil
+				},
+			}
+
+			err := cmd.Run()
+
+			if err != nil {
+				err = errors.New(strings.ReplaceAll(err.Error(), dir, "<dir>"))
+			}
+			if tc.expectedErr != nil {
+				tc.expectedErr = errors.New(tc.expectedErr.Error())
+			}
+			assert.Equal(t, err, tc.expectedErr)
+			assert.Equal(t, io.Out.String(), tc.expectedOut)
+			sort.Strings(created)
+			assert.Equal(t, created, tc.expectedCreated)
+			if tc.expectedWritten == nil {
+				tc.expectedWritten = map[string]string{}
+			}
+			assert.Equal(t, written, tc.expectedWritten)
+			assert.Equal(t, readSyncTestState(t, dir), tc.expectedState)
+		})
+	}
+}
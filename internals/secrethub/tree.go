@@ -9,6 +9,9 @@ import (
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
 
 	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/pkg/secretpath"
+
+	"github.com/spf13/cobra"
 )
 
 // TreeCommand lists the contents of a directory at a given path in a tree-like format.
@@ -18,14 +21,19 @@ type TreeCommand struct {
 	fullPaths     bool
 	noIndentation bool
 	noReport      bool
+	format        string
+	timeFormatter TimeFormatter
 	newClient     newClientFunc
 }
 
+const formatTree = "tree"
+
 // NewTreeCommand creates a new TreeCommand.
 func NewTreeCommand(io ui.IO, clientFactory newClientFunc) *TreeCommand {
 	return &TreeCommand{
-		io:        io,
-		newClient: clientFactory,
+		io:            io,
+		newClient:     clientFactory,
+		timeFormatter: NewTimeFormatter(true),
 	}
 }
 
@@ -36,10 +44,19 @@ func (cmd *TreeCommand) Run() error {
 		return err
 	}
 
+	if cmd.format != formatTree && cmd.format != formatJSON {
+		return errNoSuchFormat(cmd.format)
+	}
+
 	t, err := client.Dirs().GetTree(cmd.path.Value(), -1, false)
 	if err != nil {
 		return err
 	}
+	hideReservedSecrets(t)
+
+	if cmd.format == formatJSON {
+		return newPrettyJSONFormatter(cmd.io.Output()).WriteValue(newDirOutput(cmd.path.Value(), t.RootDir, cmd.timeFormatter))
+	}
 
 	cmd.printTree(t, cmd.io.Output())
 	return nil
@@ -53,6 +70,10 @@ func (cmd *TreeCommand) Register(r cli.Registerer) {
 	clause.Flags().BoolVarP(&cmd.noIndentation, "no-indentation", "i", false, "Do not use the standard indentation.")
 	clause.Flags().BoolVar(&cmd.noReport, "no-report", false, "Turn off secret/directory count at end of tree listing.")
 	clause.Flags().BoolVar(&cmd.noReport, "noreport", false, "Turn off secret/directory count at end of tree listing.").Hidden()
+	clause.Flags().StringVar(&cmd.format, "output-format", formatTree, "Specify the format in which to output the tree. Options are: tree and json. The json format contains the nested directories and secrets with their IDs, statuses, version counts and timestamps.")
+	_ = clause.Cmd.RegisterFlagCompletionFunc("output-format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{formatTree, formatJSON}, cobra.ShellCompDirectiveDefault
+	})
 
 	clause.BindAction(cmd.Run)
 	clause.BindArguments([]cli.Argument{{Value: &cmd.path, Name: "dir-path", Required: true, Placeholder: optionalDirPathPlaceHolder, Description: "The path to to show contents for."}})
@@ -138,3 +159,42 @@ func (cmd *TreeCommand) printDirContentsRecursively(dir *api.Dir, prefix string,
 		i++
 	}
 }
+
+// newDirOutput returns the JSON output of a directory and its contents, sorted by name.
+func newDirOutput(path string, dir *api.Dir, timeFormatter TimeFormatter) dirOutput {
+	sort.Sort(api.SortDirByName(dir.SubDirs))
+	sort.Sort(api.SortSecretByName(dir.Secrets))
+
+	out := dirOutput{
+		DirID:          dir.DirID.String(),
+		Name:           dir.Name,
+		Path:           path,
+		Status:         dir.Status,
+		CreatedAt:      timeFormatter.Format(dir.CreatedAt.Local()),
+		LastModifiedAt: timeFormatter.Format(dir.LastModifiedAt.Local()),
+		Dirs:           make([]dirOutput, len(dir.SubDirs)),
+		Secrets:        make([]secretOutput, len(dir.Secrets)),
+	}
+
+	for i, sub := range dir.SubDirs {
+		out.Dirs[i] = newDirOutput(secretpath.Join(path, sub.Name), sub, timeFormatter)
+	}
+
+	for i, secret := range dir.Secrets {
+		out.Secrets[i] = newSecretOutput(secretpath.Join(path, secret.Name), secret, timeFormatter)
+	}
+
+	return out
+}
+
+// dirOutput is the printable JSON format of a directory.
+type dirOutput struct {
+	DirID          string
+	Name           string
+	Path           string
+	Status         string
+	CreatedAt      string
+	LastModifiedAt string
+	Dirs           []dirOutput
+	Secrets        []secretOutput
+}
@@ -6,10 +6,13 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/fakes"
 	"github.com/secrethub/secrethub-go/internals/api"
 	"github.com/secrethub/secrethub-go/internals/api/uuid"
 	"github.com/secrethub/secrethub-go/internals/assert"
 	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
 )
 
 func TestSimpleTree(t *testing.T) {
@@ -237,3 +240,108 @@ func TestTreeColoring(t *testing.T) {
 		})
 	}
 }
+
+func TestTreeCommand_Run_json(t *testing.T) {
+	rootID := uuid.New()
+	dirID := uuid.New()
+	secretID := uuid.New()
+	tree := &api.Tree{
+		RootDir: &api.Dir{
+			DirID:  rootID,
+			Name:   "repo",
+			Status: api.StatusOK,
+			SubDirs: []*api.Dir{
+				{
+					DirID:    dirID,
+					ParentID: &rootID,
+					Name:     "dir",
+					Status:   api.StatusOK,
+				},
+			},
+			Secrets: []*api.Secret{
+				{
+					SecretID:      secretID,
+					DirID:         rootID,
+					Name:          "secret",
+					Status:        api.StatusFlagged,
+					VersionCount:  2,
+					LatestVersion: 2,
+				},
+			},
+		},
+	}
+
+	cases := map[string]struct {
+		format      string
+		expectedOut string
+		expectedErr error
+	}{
+		"json": {
+			format: formatJSON,
+			expectedOut: "{\n" +
+				"    \"DirID\": \"" + rootID.String() + "\",\n" +
+				"    \"Name\": \"repo\",\n" +
+				"    \"Path\": \"namespace/repo\",\n" +
+				"    \"Status\": \"ok\",\n" +
+				"    \"CreatedAt\": \"2018-01-01T01:01:01+01:00\",\n" +
+				"    \"LastModifiedAt\": \"2018-01-01T01:01:01+01:00\",\n" +
+				"    \"Dirs\": [\n" +
+				"        {\n" +
+				"            \"DirID\": \"" + dirID.String() + "\",\n" +
+				"            \"Name\": \"dir\",\n" +
+				"            \"Path\": \"namespace/repo/dir\",\n" +
+				"            \"Status\": \"ok\",\n" +
+				"            \"CreatedAt\": \"2018-01-01T01:01:01+01:00\",\n" +
+				"            \"LastModifiedAt\": \"2018-01-01T01:01:01+01:00\",\n" +
+				"            \"Dirs\": [],\n" +
+				"            \"Secrets\": []\n" +
+				"        }\n" +
+				"    ],\n" +
+				"    \"Secrets\": [\n" +
+				"        {\n" +
+				"            \"SecretID\": \"" + secretID.String() + "\",\n" +
+				"            \"Name\": \"secret\",\n" +
+				"            \"Path\": \"namespace/repo/secret\",\n" +
+				"            \"Status\": \"flagged\",\n" +
+				"            \"CreatedAt\": \"2018-01-01T01:01:01+01:00\",\n" +
+				"            \"VersionCount\": 2,\n" +
+				"            \"LatestVersion\": 2\n" +
+				"        }\n" +
+				"    ]\n" +
+				"}\n",
+		},
+		"invalid format": {
+			format:      "yaml",
+			expectedErr: errNoSuchFormat("yaml"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			io := fakeui.NewIO(t)
+			cmd := TreeCommand{
+				path:   "namespace/repo",
+				io:     io,
+				format: tc.format,
+				timeFormatter: &fakes.TimeFormatter{
+					Response: "2018-01-01T01:01:01+01:00",
+				},
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						DirService: &fakeclient.DirService{
+							GetTreeFunc: func(path string, depth int, ancestors bool) (*api.Tree, error) {
+								assert.Equal(t, path, "namespace/repo")
+								return tree, nil
+							},
+						},
+					}, nil
+				},
+			}
+
+			err := cmd.Run()
+
+			assert.Equal(t, err, tc.expectedErr)
+			assert.Equal(t, io.Out.String(), tc.expectedOut)
+		})
+	}
+}
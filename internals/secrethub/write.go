@@ -22,8 +22,11 @@ var (
 // WriteCommand is a command to write content to a secret.
 type WriteCommand struct {
 	io           ui.IO
-	path         api.SecretPath
+	path         api.Path
 	inFile       string
+	fromDotEnv   string
+	fromJSON     string
+	fromYAML     string
 	multiline    bool
 	useClipboard bool
 	noTrim       bool
@@ -43,19 +46,24 @@ func NewWriteCommand(io ui.IO, newClient newClientFunc) *WriteCommand {
 // Register registers the command, arguments and flags on the provided Registerer.
 func (cmd *WriteCommand) Register(r cli.Registerer) {
 	clause := r.Command("write", "Write a secret.")
+	clause.HelpLong("Write the value of a secret from stdin, the clipboard, a file or a prompt.\n\n" +
+		"With --from-dotenv, --from-json or --from-yaml, all key-value pairs in the file are written as secrets " +
+		"to the given directory instead. Nested keys in JSON and YAML files become subdirectories. " +
+		"Missing directories are created and values that equal the latest version of the secret are skipped.")
 	clause.Flags().BoolVarP(&cmd.useClipboard, "clip", "c", false, "Use clipboard content as input.")
 	clause.Flags().BoolVarP(&cmd.multiline, "multiline", "m", false, "Prompt for multiple lines of input, until an EOF is reached. On Linux/Mac, press CTRL-D to end input. On Windows, press CTRL-Z and then ENTER to end input.")
 	clause.Flags().BoolVar(&cmd.noTrim, "no-trim", false, "Do not trim leading and trailing whitespace in the secret.")
 	clause.Flags().StringVarP(&cmd.inFile, "in-file", "i", "", "Use the contents of this file as the value of the secret.")
+	clause.Flags().StringVar(&cmd.fromDotEnv, "from-dotenv", "", "Write every key=value pair in this .env file as a secret in the given directory.")
+	clause.Flags().StringVar(&cmd.fromJSON, "from-json", "", "Write every key-value pair in this JSON file as a secret in the given directory.")
+	clause.Flags().StringVar(&cmd.fromYAML, "from-yaml", "", "Write every key-value pair in this YAML file as a secret in the given directory.")
 
 	clause.BindAction(cmd.Run)
-	clause.BindArguments([]cli.Argument{{Value: &cmd.path, Name: "secret-path", Required: true, Placeholder: secretPathPlaceHolder, Description: "The path to the secret."}})
+	clause.BindArguments([]cli.Argument{{Value: &cmd.path, Name: "secret-path", Required: true, Placeholder: secretPathPlaceHolder, Description: "The path to the secret, or to the directory to write to when writing from a file with key-value pairs."}})
 }
 
 // Run handles the command with the options as specified in the command.
 func (cmd *WriteCommand) Run() error {
-	var err error
-
 	// This error is checked here to fail fast.
 	// The error is also checked in the client.
 	// Without this check here, the user would be prompted for input when io.Stdin is not piped, but the path is incorrect.
@@ -63,6 +71,10 @@ func (cmd *WriteCommand) Run() error {
 		return errCannotWriteToVersion
 	}
 
+	if cmd.fromDotEnv != "" || cmd.fromJSON != "" || cmd.fromYAML != "" {
+		return cmd.runImport()
+	}
+
 	if cmd.multiline && (cmd.useClipboard || cmd.inFile != "") {
 		return errMultilineWithNonInteractiveFlag
 	}
@@ -71,6 +83,12 @@ func (cmd *WriteCommand) Run() error {
 		return errClipAndInFile
 	}
 
+	// The path is validated before the value is read, so the user is not prompted for a value that cannot be written.
+	secretPath, err := cmd.path.ToSecretPath()
+	if err != nil {
+		return err
+	}
+
 	var data []byte
 	if cmd.useClipboard {
 		data, err = cmd.clipper.ReadAll()
@@ -120,7 +138,12 @@ func (cmd *WriteCommand) Run() error {
 		return err
 	}
 
-	version, err := client.Secrets().Write(cmd.path.Value(), data)
+	err = checkPolicy(client, secretPath.Value(), data)
+	if err != nil {
+		return err
+	}
+
+	version, err := client.Secrets().Write(secretPath.Value(), data)
 	if err != nil {
 		return err
 	}
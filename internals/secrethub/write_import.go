@@ -0,0 +1,310 @@
+package secrethub
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secretpath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Errors
+var (
+	errImportFlagConflict = errMain.Code("import_flag_conflict").Error("only one of from-dotenv, from-json and from-yaml can be used and not together with clip, in-file or multiline")
+	ErrInvalidImportFile  = errMain.Code("invalid_import_file").ErrorPref("cannot write the key-value pairs in %s: %v")
+)
+
+// importedSecret is a secret read from a file with key-value pairs.
+type importedSecret struct {
+	path  string
+	value []byte
+}
+
+// runImport writes every key-value pair in the file given with one of the
+// --from flags as a secret in the directory.
+func (cmd *WriteCommand) runImport() error {
+	file, parse, err := cmd.importFile()
+	if err != nil {
+		return err
+	}
+
+	dirPath, err := cmd.path.ToDirPath()
+	if err != nil {
+		return err
+	}
+
+	raw, err := ioutil.ReadFile(file)
+	if err != nil {
+		return ErrReadFile(file, err)
+	}
+
+	pairs, err := parse(raw)
+	if err != nil {
+		return ErrInvalidImportFile(file, err)
+	}
+
+	secrets := make([]importedSecret, 0, len(pairs))
+	for key, value := range pairs {
+		if key == "" {
+			return ErrInvalidImportFile(file, errors.New("a key is empty"))
+		}
+		path := secretpath.Join(dirPath.Value(), key)
+		_, err = api.NewSecretPath(path)
+		if err != nil {
+			return ErrInvalidImportFile(file, fmt.Sprintf("invalid key %s: %v", key, err))
+		}
+		if value == "" {
+			return ErrInvalidImportFile(file, fmt.Sprintf("the value of %s is empty", key))
+		}
+		secrets = append(secrets, importedSecret{path: path, value: []byte(value)})
+	}
+	sort.Slice(secrets, func(i, j int) bool {
+		return secrets[i].path < secrets[j].path
+	})
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	// All values are checked before anything is written,
+	// so a violation does not leave the directory half written.
+	finder := newPolicyFinder(client)
+	for _, secret := range secrets {
+		err = finder.check(secret.path, secret.value)
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprint(cmd.io.Output(), "Writing secret values...\n")
+
+	newDirs, err := createImportDirs(client, secrets)
+	if err != nil {
+		return err
+	}
+	if len(newDirs) > 0 {
+		fmt.Fprintf(cmd.io.Output(), "Created %s.\n", pluralize("directory", "directories", len(newDirs)))
+	}
+
+	created := 0
+	updated := 0
+	unchanged := 0
+	for _, secret := range secrets {
+		exists := false
+		if !newDirs[secretpath.Parent(secret.path)] {
+			current, err := client.Secrets().Versions().GetWithData(secret.path)
+			if err == nil {
+				exists = true
+				if bytes.Equal(current.Data, secret.value) {
+					unchanged++
+					continue
+				}
+			} else if !api.IsErrNotFound(err) {
+				return err
+			}
+		}
+
+		_, err = client.Secrets().Write(secret.path, secret.value)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			updated++
+		} else {
+			created++
+		}
+	}
+
+	fmt.Fprintf(cmd.io.Output(), "Write complete! %s created, %d updated and %d unchanged in %s.\n", pluralize("secret", "secrets", created), updated, unchanged, dirPath)
+	return nil
+}
+
+// importFile returns the file given with one of the --from flags and the parser for its format.
+func (cmd *WriteCommand) importFile() (string, func([]byte) (map[string]string, error), error) {
+	if cmd.useClipboard || cmd.inFile != "" || cmd.multiline {
+		return "", nil, errImportFlagConflict
+	}
+
+	var file string
+	var parse func([]byte) (map[string]string, error)
+	for _, from := range []struct {
+		file  string
+		parse func([]byte) (map[string]string, error)
+	}{
+		{file: cmd.fromDotEnv, parse: parseDotEnvPairs},
+		{file: cmd.fromJSON, parse: parseJSONPairs},
+		{file: cmd.fromYAML, parse: parseYAMLPairs},
+	} {
+		if from.file == "" {
+			continue
+		}
+		if file != "" {
+			return "", nil, errImportFlagConflict
+		}
+		file = from.file
+		parse = from.parse
+	}
+
+	return file, parse, nil
+}
+
+// createImportDirs creates the directories of the secrets that do not exist yet, parents first.
+// It returns the created directories.
+func createImportDirs(client secrethub.ClientInterface, secrets []importedSecret) (map[string]bool, error) {
+	needed := map[string]bool{}
+	for _, secret := range secrets {
+		// The root directory of a repository cannot be created.
+		for dir := secretpath.Parent(secret.path); secretpath.Count(dir) > 2 && !needed[dir]; dir = secretpath.Parent(dir) {
+			needed[dir] = true
+		}
+	}
+
+	dirs := make([]string, 0, len(needed))
+	for dir := range needed {
+		dirs = append(dirs, dir)
+	}
+	// A directory sorts before the directories in it.
+	sort.Strings(dirs)
+
+	created := map[string]bool{}
+	for _, dir := range dirs {
+		// The subdirectories of a created directory cannot exist yet.
+		if !created[secretpath.Parent(dir)] {
+			exists, err := client.Dirs().Exists(dir)
+			if err != nil {
+				return nil, err
+			}
+			if exists {
+				continue
+			}
+		}
+
+		_, err := client.Dirs().Create(dir)
+		if err != nil {
+			return nil, err
+		}
+		created[dir] = true
+	}
+
+	return created, nil
+}
+
+// parseDotEnvPairs parses key=value pairs in the .env syntax.
+func parseDotEnvPairs(raw []byte) (map[string]string, error) {
+	vars, err := parseDotEnv(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := make(map[string]string, len(vars))
+	for _, v := range vars {
+		pairs[v.key] = v.value
+	}
+	return pairs, nil
+}
+
+// parseJSONPairs parses a JSON object, of which nested objects become subdirectories.
+func parseJSONPairs(raw []byte) (map[string]string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	// Keep numbers exactly as they are written in the file.
+	decoder.UseNumber()
+
+	var values map[string]interface{}
+	err := decoder.Decode(&values)
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := map[string]string{}
+	err = flattenPairs("", values, pairs)
+	if err != nil {
+		return nil, err
+	}
+	return pairs, nil
+}
+
+// parseYAMLPairs parses a YAML map, of which nested maps become subdirectories.
+// Values are kept exactly as they are written in the file, so 0800 is not written as 800.
+func parseYAMLPairs(raw []byte) (map[string]string, error) {
+	var doc yaml.Node
+	err := yaml.Unmarshal(raw, &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := map[string]string{}
+	if len(doc.Content) == 0 {
+		return pairs, nil
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("the file does not contain a map")
+	}
+
+	err = flattenYAMLPairs("", doc.Content[0], pairs)
+	if err != nil {
+		return nil, err
+	}
+	return pairs, nil
+}
+
+// flattenYAMLPairs adds the scalars in a YAML node to the pairs,
+// using the path of keys to a scalar as its key.
+func flattenYAMLPairs(key string, node *yaml.Node, pairs map[string]string) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		// Content alternates between keys and values.
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k := node.Content[i].Value
+			if k == "" {
+				return errors.New("a key is empty")
+			}
+			err := flattenYAMLPairs(secretpath.Join(key, k), node.Content[i+1], pairs)
+			if err != nil {
+				return err
+			}
+		}
+	case yaml.AliasNode:
+		return flattenYAMLPairs(key, node.Alias, pairs)
+	case yaml.SequenceNode:
+		return fmt.Errorf("%s is a list: only strings, numbers and booleans can be written", key)
+	default:
+		if node.Tag == "!!null" {
+			return fmt.Errorf("%s has no value", key)
+		}
+		pairs[key] = node.Value
+	}
+	return nil
+}
+
+// flattenPairs adds the values of a decoded JSON document to the pairs,
+// using the path of keys to a value as its key.
+func flattenPairs(key string, value interface{}, pairs map[string]string) error {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if k == "" {
+				return errors.New("a key is empty")
+			}
+			err := flattenPairs(secretpath.Join(key, k), child, pairs)
+			if err != nil {
+				return err
+			}
+		}
+	case nil:
+		return fmt.Errorf("%s has no value", key)
+	case []interface{}:
+		return fmt.Errorf("%s is a list: only strings, numbers and booleans can be written", key)
+	default:
+		pairs[key] = fmt.Sprint(v)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package secrethub
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestWriteCommand_Run_import(t *testing.T) {
+	dir, cleanup := testdata.tempDir(t)
+	defer cleanup()
+
+	files := map[string]string{
+		".env":         "# database\nDB_USER=app\nDB_PASSWORD=\"changed\"\nAPI_KEY=new\n",
+		"secrets.json": `{"db": {"user": "app", "port": 5432}, "api_key": "new"}`,
+		"secrets.yml":  "db:\n  user: app\n  tls:\n    enabled: true\napi_key: new\n",
+		"empty.env":    "DB_USER=\n",
+		"list.yml":     "hosts: [a, b]\n",
+		"scalars.yml":  "port: 0800\nversion: 1.10\nenabled: yes\n",
+		"invalid.env":  "DB USER\n",
+	}
+	for name, content := range files {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600)
+		assert.OK(t, err)
+	}
+
+	cases := map[string]struct {
+		cmd             WriteCommand
+		expectedCreated []string
+		expectedWritten map[string]string
+		expectedOut     string
+		expectedErr     error
+	}{
+		"dotenv": {
+			cmd: WriteCommand{
+				path:       "namespace/repo/app",
+				fromDotEnv: filepath.Join(dir, ".env"),
+			},
+			expectedWritten: map[string]string{
+				"namespace/repo/app/API_KEY":     "new",
+				"namespace/repo/app/DB_PASSWORD": "changed",
+			},
+			expectedOut: "Writing secret values...\n" +
+				"Write complete! 1 secret created, 1 updated and 1 unchanged in namespace/repo/app.\n",
+		},
+		"json with nested keys": {
+			cmd: WriteCommand{
+				path:     "namespace/repo/new",
+				fromJSON: filepath.Join(dir, "secrets.json"),
+			},
+			expectedCreated: []string{"namespace/repo/new", "namespace/repo/new/db"},
+			expectedWritten: map[string]string{
+				"namespace/repo/new/api_key": "new",
+				"namespace/repo/new/db/port": "5432",
+				"namespace/repo/new/db/user": "app",
+			},
+			expectedOut: "Writing secret values...\n" +
+				"Created 2 directories.\n" +
+				"Write complete! 3 secrets created, 0 updated and 0 unchanged in namespace/repo/new.\n",
+		},
+		"yaml with nested keys": {
+			cmd: WriteCommand{
+				path:     "namespace/repo/app",
+				fromYAML: filepath.Join(dir, "secrets.yml"),
+			},
+			expectedCreated: []string{"namespace/repo/app/db", "namespace/repo/app/db/tls"},
+			expectedWritten: map[string]string{
+				"namespace/repo/app/api_key":        "new",
+				"namespace/repo/app/db/tls/enabled": "true",
+				"namespace/repo/app/db/user":        "app",
+			},
+			expectedOut: "Writing secret values...\n" +
+				"Created 2 directories.\n" +
+				"Write complete! 3 secrets created, 0 updated and 0 unchanged in namespace/repo/app.\n",
+		},
+		"yaml values as written": {
+			cmd: WriteCommand{
+				path:     "namespace/repo/app",
+				fromYAML: filepath.Join(dir, "scalars.yml"),
+			},
+			expectedWritten: map[string]string{
+				"namespace/repo/app/enabled": "yes",
+				"namespace/repo/app/port":    "0800",
+				"namespace/repo/app/version": "1.10",
+			},
+			expectedOut: "Writing secret values...\n" +
+				"Write complete! 3 secrets created, 0 updated and 0 unchanged in namespace/repo/app.\n",
+		},
+		"multiple files": {
+			cmd: WriteCommand{
+				path:       "namespace/repo/app",
+				fromDotEnv: filepath.Join(dir, ".env"),
+				fromJSON:   filepath.Join(dir, "secrets.json"),
+			},
+			expectedErr: errImportFlagConflict,
+		},
+		"file and clip": {
+			cmd: WriteCommand{
+				path:         "namespace/repo/app",
+				fromDotEnv:   filepath.Join(dir, ".env"),
+				useClipboard: true,
+			},
+			expectedErr: errImportFlagConflict,
+		},
+		"empty value": {
+			cmd: WriteCommand{
+				path:       "namespace/repo/app",
+				fromDotEnv: filepath.Join(dir, "empty.env"),
+			},
+			expectedErr: ErrInvalidImportFile(filepath.Join(dir, "empty.env"), "the value of DB_USER is empty"),
+		},
+		"list value": {
+			cmd: WriteCommand{
+				path:     "namespace/repo/app",
+				fromYAML: filepath.Join(dir, "list.yml"),
+			},
+			expectedErr: ErrInvalidImportFile(filepath.Join(dir, "list.yml"), errors.New("hosts is a list: only strings, numbers and booleans can be written")),
+		},
+		"invalid key": {
+			cmd: WriteCommand{
+				path:       "namespace/repo/app",
+				fromDotEnv: filepath.Join(dir, "invalid.env"),
+			},
+			expectedErr: ErrInvalidImportFile(filepath.Join(dir, "invalid.env"), ErrTemplate(1, errors.New("template is not formatted as key=value pairs"))),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			existingDirs := map[string]bool{
+				"namespace/repo/app": true,
+			}
+			existingSecrets := map[string]string{
+				"namespace/repo/app/DB_USER":     "app",
+				"namespace/repo/app/DB_PASSWORD": "old",
+			}
+
+			var created []string
+			written := map[string]string{}
+
+			io := fakeui.NewIO(t)
+			tc.cmd.io = io
+			tc.cmd.newClient = func() (secrethub.ClientInterface, error) {
+				return fakeclient.Client{
+					DirService: &fakeclient.DirService{
+						ExistsFunc: func(path string) (bool, error) {
+							return existingDirs[path], nil
+						},
+						CreateFunc: func(path string) (*api.Dir, error) {
+							created = append(created, path)
+							return &api.Dir{}, nil
+						},
+					},
+					SecretService: &fakeclient.SecretService{
+						WriteFunc: func(path string, data []byte) (*api.SecretVersion, error) {
+							written[path] = string(data)
+							return &api.SecretVersion{}, nil
+						},
+						VersionService: &fakeclient.SecretVersionService{
+							GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+								value, ok := existingSecrets[path]
+								if !ok {
+									return nil, api.ErrSecretNotFound
+								}
+								return &api.SecretVersion{Data: []byte(value)}, nil
+							},
+						},
+					},
+				}, nil
+			}
+
+			err := tc.cmd.Run()
+
+			assert.Equal(t, err, tc.expectedErr)
+			sort.Strings(created)
+			assert.Equal(t, created, tc.expectedCreated)
+			if tc.expectedWritten == nil {
+				tc.expectedWritten = map[string]string{}
+			}
+			assert.Equal(t, written, tc.expectedWritten)
+			assert.Equal(t, io.Out.String(), tc.expectedOut)
+		})
+	}
+}
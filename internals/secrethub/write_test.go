@@ -9,6 +9,7 @@ import (
 	"github.com/secrethub/secrethub-cli/internals/cli/clip/fakeclip"
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
 	"github.com/secrethub/secrethub-cli/internals/cli/ui/fakeui"
+	"github.com/secrethub/secrethub-cli/internals/policy"
 
 	"github.com/secrethub/secrethub-go/internals/api"
 	"github.com/secrethub/secrethub-go/internals/assert"
@@ -29,6 +30,7 @@ func TestWriteCommand_Run(t *testing.T) {
 		promptErr         error
 		passwordIn        string
 		newClientError    error
+		policies          map[string]string
 		passwordErr       error
 		readErr           error
 		expectedPath      api.SecretPath
@@ -203,6 +205,7 @@ func TestWriteCommand_Run(t *testing.T) {
 		},
 		"cannot open file": {
 			cmd: WriteCommand{
+				path:   "namespace/repo/secret",
 				inFile: "filename",
 			},
 			expectedErr: ErrReadFile("filename", errors.New("open filename: no such file or directory")),
@@ -217,6 +220,44 @@ func TestWriteCommand_Run(t *testing.T) {
 			newClientError: testErr,
 			expectedOut:    "Writing secret value...\n",
 		},
+		"policy met": {
+			cmd: WriteCommand{
+				path: "namespace/repo/dir/secret",
+			},
+			in:    "1234567890abcdefghij",
+			piped: true,
+			policies: map[string]string{
+				"namespace/repo/.policy": "length: 16\nmin: {numeric: 4}",
+			},
+			writeFunc: func(path string, data []byte) (*api.SecretVersion, error) {
+				return &api.SecretVersion{Version: 1}, nil
+			},
+			expectedPath: "namespace/repo/dir/secret",
+			expectedData: []byte("1234567890abcdefghij"),
+			expectedOut:  "Writing secret value...\nWrite complete! The given value has been written to namespace/repo/dir/secret:1\n",
+		},
+		"policy violated": {
+			cmd: WriteCommand{
+				path: "namespace/repo/dir/secret",
+			},
+			in:    "password",
+			piped: true,
+			policies: map[string]string{
+				"namespace/repo/.policy":     "length: 16",
+				"namespace/repo/dir/.policy": "forbidden_words: [password]",
+			},
+			expectedErr: ErrPolicyViolation("namespace/repo/dir/.policy", `contains the forbidden word "password"`),
+			expectedOut: "Writing secret value...\n",
+		},
+		"invalid policy": {
+			cmd: WriteCommand{
+				path: "namespace/repo/.policy",
+			},
+			in:          "length: ten",
+			piped:       true,
+			expectedErr: ErrInvalidPolicyFile("namespace/repo/.policy", policy.ErrInvalidPolicy("yaml: unmarshal errors:\n  line 1: cannot unmarshal !!str `ten` into int")),
+			expectedOut: "Writing secret value...\n",
+		},
 		"empty multiline": {
 			cmd: WriteCommand{
 				path:      "namespace/repo/secret",
@@ -240,6 +281,15 @@ func TestWriteCommand_Run(t *testing.T) {
 							argData = data
 							return tc.writeFunc(path, data)
 						},
+						VersionService: &fakeclient.SecretVersionService{
+							GetWithDataFunc: func(path string) (*api.SecretVersion, error) {
+								data, ok := tc.policies[path]
+								if !ok {
+									return nil, api.ErrSecretNotFound
+								}
+								return &api.SecretVersion{Data: []byte(data)}, nil
+							},
+						},
 					},
 				}, tc.newClientError
 			}
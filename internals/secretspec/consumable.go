@@ -26,6 +26,8 @@ var (
 		FileParser{},
 		EnvParser{},
 		InjectParser{},
+		KubernetesParser{},
+		DockerParser{},
 	}
 
 	// DefaultFileMode is the default filemode to use for consumables.
@@ -54,6 +56,8 @@ var (
 type Consumable interface {
 	// Set sets the consumable to any matching secrets.
 	Set(secrets map[string]api.SecretVersion) error
+	// Render returns the contents of every file Set would write, indexed by path.
+	Render(secrets map[string]api.SecretVersion) (map[string][]byte, error)
 	// Clear clears the consumable of any content.
 	Clear() error
 	// Sources returns a set of full paths of the secrets corresponding to the consumable.
@@ -0,0 +1,285 @@
+package secretspec
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	fieldSecrets = "secrets"
+	fieldCompose = "compose"
+
+	defaultDockerSecretsDir = "secrets"
+)
+
+var (
+	dockerSecretNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+)
+
+// Errors
+var (
+	ErrInvalidDockerSecretName = errConsumption.Code("invalid_docker_secret_name").ErrorPref("invalid docker secret name %s: it must start with an alphanumeric character and may only contain alphanumeric characters, '_', '-' or '.'")
+)
+
+// DockerParser is a Parser to parse Docker file-based secret Consumables.
+type DockerParser struct{}
+
+// Type returns the parser type.
+func (p DockerParser) Type() string {
+	return "docker"
+}
+
+// Parse parses a config to create a Docker secrets Consumable.
+func (p DockerParser) Parse(rootPath string, allowMountAnywhere bool, config map[string]interface{}) (Consumable, error) {
+	rawSecrets, ok := config[fieldSecrets].(map[interface{}]interface{})
+	if !ok {
+		return nil, ErrFieldNotSet(fieldSecrets, rawSecrets)
+	}
+
+	secrets := make(map[string]string, len(rawSecrets))
+	for key, val := range rawSecrets {
+		name, ok := key.(string)
+		if !ok {
+			return nil, ErrCannotConvertField(fieldSecrets, key, name)
+		}
+		source, ok := val.(string)
+		if !ok {
+			return nil, ErrCannotConvertField(name, val, source)
+		}
+		secrets[name] = source
+	}
+
+	filemode := DefaultFileMode
+	mode, ok := config[fieldFilemode].(string)
+	if ok && mode != "" {
+		var err error
+		filemode, err = strToFileMode(mode)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	target, _ := config[fieldTarget].(string)
+	if target == "" {
+		target = defaultDockerSecretsDir
+	}
+
+	compose, _ := config[fieldCompose].(string)
+
+	d, err := newDockerSecrets(target, compose, secrets, filemode)
+	if err != nil {
+		return nil, err
+	}
+
+	d.target, err = parseTargetOnRootPath(rootPath, d.target, allowMountAnywhere)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.compose != "" {
+		d.compose, err = createTarget(rootPath, d.compose, allowMountAnywhere)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return d, nil
+}
+
+// dockerSecrets implements a Consumable that writes every secret to a file in
+// a directory, so they can be used as file-based secrets by Docker and Compose.
+// Optionally, a Compose file declaring the secrets is written as well.
+type dockerSecrets struct {
+	target   string
+	compose  string
+	secrets  map[string]string
+	filemode os.FileMode
+}
+
+// newDockerSecrets creates a new docker secrets consumable, validating the secret names and sources.
+func newDockerSecrets(target, compose string, secrets map[string]string, filemode os.FileMode) (*dockerSecrets, error) {
+	sources := make(map[string]string, len(secrets))
+	for name, source := range secrets {
+		name = strings.TrimSpace(name)
+		if !dockerSecretNamePattern.MatchString(name) {
+			return nil, ErrInvalidDockerSecretName(name)
+		}
+
+		source = strings.ToLower(strings.TrimSpace(source))
+		err := api.ValidateSecretPath(source)
+		if err != nil {
+			return nil, ErrInvalidSourcePath(err)
+		}
+		sources[name] = source
+	}
+
+	return &dockerSecrets{
+		target:   target,
+		compose:  strings.TrimSpace(compose),
+		secrets:  sources,
+		filemode: filemode,
+	}, nil
+}
+
+// composeFile is the YAML representation of a Compose file that only declares secrets.
+type composeFile struct {
+	Secrets map[string]composeSecret `yaml:"secrets"`
+}
+
+// composeSecret is the YAML representation of a file-based secret in a Compose file.
+type composeSecret struct {
+	File string `yaml:"file"`
+}
+
+// Set writes every matching secret to a file in the target directory and writes the
+// Compose file when configured. Though the map may contain other secrets, it must
+// contain all source secrets of this consumable.
+func (d *dockerSecrets) Set(secrets map[string]api.SecretVersion) error {
+	files, err := d.Render(secrets)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(d.target, DefaultEnvDirFileMode)
+	if err != nil {
+		return ErrMkdirError(d.target, err)
+	}
+
+	for _, name := range d.names() {
+		log.Debugf("setting docker secret: %s (source) => %s (target)", d.secrets[name], name)
+		path := d.secretPath(name)
+		err := overwriteFile(path, files[path], d.filemode)
+		if err != nil {
+			return err
+		}
+	}
+
+	if d.compose == "" {
+		return nil
+	}
+
+	log.Debugf("writing compose file to %s", d.compose)
+	return overwriteFile(d.compose, files[d.compose], DefaultFileMode)
+}
+
+// Render returns the contents of every secret file and of the Compose file when configured.
+func (d *dockerSecrets) Render(secrets map[string]api.SecretVersion) (map[string][]byte, error) {
+	files := make(map[string][]byte, len(d.secrets)+1)
+	for name, source := range d.secrets {
+		version, found := secrets[source]
+		if !found {
+			return nil, ErrSecretNotFound(source)
+		}
+		files[d.secretPath(name)] = version.Data
+	}
+
+	if d.compose != "" {
+		manifest, err := d.renderCompose()
+		if err != nil {
+			return nil, err
+		}
+		files[d.compose] = manifest
+	}
+
+	return files, nil
+}
+
+// renderCompose returns a Compose file declaring all secrets as file-based secrets.
+// The file paths are relative to the directory of the Compose file, as Compose resolves them that way.
+func (d *dockerSecrets) renderCompose() ([]byte, error) {
+	out := composeFile{
+		Secrets: make(map[string]composeSecret, len(d.secrets)),
+	}
+
+	for name := range d.secrets {
+		path, err := filepath.Rel(filepath.Dir(d.compose), d.secretPath(name))
+		if err != nil {
+			return nil, ErrCannotFindAbsPath(d.secretPath(name), err)
+		}
+
+		path = filepath.ToSlash(path)
+		if !strings.HasPrefix(path, ".") {
+			path = "./" + path
+		}
+
+		out.Secrets[name] = composeSecret{
+			File: path,
+		}
+	}
+
+	return yaml.Marshal(out)
+}
+
+// Clear removes the secret files and the Compose file from the filesystem.
+// The target directory is removed when it is empty afterwards.
+func (d *dockerSecrets) Clear() error {
+	files := make([]string, 0, len(d.secrets)+1)
+	for _, name := range d.names() {
+		files = append(files, d.secretPath(name))
+	}
+	if d.compose != "" {
+		files = append(files, d.compose)
+	}
+
+	for _, file := range files {
+		err := os.Remove(file)
+		if os.IsNotExist(err) {
+			log.Warningf("cannot clear file %s as it does not exist", file)
+		} else if err != nil {
+			return err
+		}
+	}
+
+	entries, err := ioutil.ReadDir(d.target)
+	if err == nil && len(entries) == 0 {
+		return os.Remove(d.target)
+	}
+	return nil
+}
+
+// Sources returns the full paths of the secrets from which the consumable is sourced.
+func (d *dockerSecrets) Sources() map[string]struct{} {
+	sources := make(map[string]struct{})
+	for _, source := range d.secrets {
+		sources[source] = struct{}{}
+	}
+	return sources
+}
+
+// Equals checks whether two docker secrets consumables have the same target directory.
+func (d *dockerSecrets) Equals(consumable Consumable) bool {
+	dockerConsumable, ok := consumable.(*dockerSecrets)
+	if !ok {
+		return false
+	}
+	return strings.EqualFold(dockerConsumable.target, d.target)
+}
+
+// String returns the string representation of the docker secrets.
+func (d *dockerSecrets) String() string {
+	return fmt.Sprintf("docker:%s", d.target)
+}
+
+// secretPath returns the path of the file the secret with the given name is written to.
+func (d *dockerSecrets) secretPath(name string) string {
+	return filepath.Join(d.target, name)
+}
+
+// names returns the names of the secrets in alphabetical order.
+func (d *dockerSecrets) names() []string {
+	names := make([]string, 0, len(d.secrets))
+	for name := range d.secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
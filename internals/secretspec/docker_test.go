@@ -0,0 +1,133 @@
+package secretspec
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestDockerParse(t *testing.T) {
+	cases := map[string]struct {
+		config   map[string]interface{}
+		expected *dockerSecrets
+		err      error
+	}{
+		"all values": {
+			config: map[string]interface{}{
+				"target":   ".docker/secrets",
+				"compose":  "docker-compose.secrets.yml",
+				"filemode": "0440",
+				"secrets": map[interface{}]interface{}{
+					"db_password": "user/repo/db/password",
+				},
+			},
+			expected: &dockerSecrets{
+				target:   filepath.Join(".docker", "secrets"),
+				compose:  "docker-compose.secrets.yml",
+				secrets:  map[string]string{"db_password": "user/repo/db/password"},
+				filemode: 0440,
+			},
+		},
+		"defaults": {
+			config: map[string]interface{}{
+				"secrets": map[interface{}]interface{}{
+					"db_password": "user/repo/db/password",
+				},
+			},
+			expected: &dockerSecrets{
+				target:   "secrets",
+				secrets:  map[string]string{"db_password": "user/repo/db/password"},
+				filemode: DefaultFileMode,
+			},
+		},
+		"invalid name": {
+			config: map[string]interface{}{
+				"secrets": map[interface{}]interface{}{
+					"_password": "user/repo/db/password",
+				},
+			},
+			err: ErrInvalidDockerSecretName("_password"),
+		},
+		"secrets not set": {
+			config: map[string]interface{}{},
+			err:    ErrFieldNotSet(fieldSecrets, map[interface{}]interface{}(nil)),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "secretspec")
+			assert.OK(t, err)
+			defer os.RemoveAll(dir)
+
+			actual, err := DockerParser{}.Parse(dir, false, tc.config)
+
+			assert.Equal(t, err, tc.err)
+			if tc.err == nil {
+				tc.expected.target = filepath.Join(dir, tc.expected.target)
+				if tc.expected.compose != "" {
+					tc.expected.compose = filepath.Join(dir, tc.expected.compose)
+				}
+				assert.Equal(t, actual, tc.expected)
+			}
+		})
+	}
+}
+
+func TestDockerSetAndClear(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secretspec")
+	assert.OK(t, err)
+	defer os.RemoveAll(dir)
+
+	secrets := map[string]api.SecretVersion{
+		"user/repo/db/password": {Data: []byte("password")},
+		"user/repo/api/key":     {Data: []byte("key")},
+	}
+
+	d, err := newDockerSecrets(
+		filepath.Join(dir, "run", "secrets"),
+		filepath.Join(dir, "docker-compose.secrets.yml"),
+		map[string]string{"db_password": "user/repo/db/password", "api_key": "user/repo/api/key"},
+		DefaultFileMode,
+	)
+	assert.OK(t, err)
+
+	err = d.Set(secrets)
+	assert.OK(t, err)
+
+	password, err := ioutil.ReadFile(filepath.Join(dir, "run", "secrets", "db_password"))
+	assert.OK(t, err)
+	assert.Equal(t, string(password), "password")
+
+	compose, err := ioutil.ReadFile(filepath.Join(dir, "docker-compose.secrets.yml"))
+	assert.OK(t, err)
+	assert.Equal(t, string(compose), "secrets:\n"+
+		"  api_key:\n"+
+		"    file: ./run/secrets/api_key\n"+
+		"  db_password:\n"+
+		"    file: ./run/secrets/db_password\n")
+
+	err = d.Clear()
+	assert.OK(t, err)
+
+	_, err = os.Stat(filepath.Join(dir, "run", "secrets"))
+	assert.Equal(t, os.IsNotExist(err), true)
+	_, err = os.Stat(filepath.Join(dir, "docker-compose.secrets.yml"))
+	assert.Equal(t, os.IsNotExist(err), true)
+}
+
+func TestDockerSetSecretNotFound(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secretspec")
+	assert.OK(t, err)
+	defer os.RemoveAll(dir)
+
+	d, err := newDockerSecrets(dir, "", map[string]string{"db_password": "user/repo/db/password"}, DefaultFileMode)
+	assert.OK(t, err)
+
+	err = d.Set(map[string]api.SecretVersion{})
+	assert.Equal(t, err, ErrSecretNotFound("user/repo/db/password"))
+}
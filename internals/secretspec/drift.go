@@ -0,0 +1,216 @@
+package secretspec
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+)
+
+// Errors
+var (
+	ErrCannotReadState  = errConsumption.Code("cannot_read_state").ErrorPref("cannot read state file %s: %v")
+	ErrCannotWriteState = errConsumption.Code("cannot_write_state").ErrorPref("cannot write state file %s: %v")
+)
+
+// stateFileMode is the filemode used for the state file. It only contains hashes, no secrets.
+const stateFileMode os.FileMode = 0600
+
+// DriftStatus describes how a target on disk differs from the spec.
+type DriftStatus string
+
+// The possible drift statuses.
+const (
+	// DriftAdded means the spec declares a target that does not exist on disk.
+	DriftAdded DriftStatus = "added"
+	// DriftChanged means the contents of a target on disk differ from what the spec would write.
+	DriftChanged DriftStatus = "changed"
+	// DriftOrphaned means a target was set before, but is no longer declared in the spec.
+	DriftOrphaned DriftStatus = "orphaned"
+)
+
+// Drift is a single target on disk that differs from the spec.
+// Hashes are the hex encoded SHA-256 hashes of the file contents
+// and are empty when the file does not exist or is not declared.
+type Drift struct {
+	Target       string
+	Status       DriftStatus
+	CurrentHash  string
+	ExpectedHash string
+}
+
+// State records the targets that were written by a set, with the hashes of their contents.
+// It is used to find targets that are no longer declared in the spec.
+type State struct {
+	Targets map[string]string `json:"targets"`
+}
+
+// ReadState reads the state from the given path.
+// When the file does not exist, an empty state is returned.
+func ReadState(path string) (State, error) {
+	state := State{
+		Targets: make(map[string]string),
+	}
+
+	raw, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	} else if err != nil {
+		return State{}, ErrCannotReadState(path, err)
+	}
+
+	err = json.Unmarshal(raw, &state)
+	if err != nil {
+		return State{}, ErrCannotReadState(path, err)
+	}
+	if state.Targets == nil {
+		state.Targets = make(map[string]string)
+	}
+
+	return state, nil
+}
+
+// Write writes the state to the given path.
+func (s State) Write(path string) error {
+	raw, err := json.MarshalIndent(s, "", "    ")
+	if err != nil {
+		return ErrCannotWriteState(path, err)
+	}
+
+	err = ioutil.WriteFile(path, raw, stateFileMode)
+	if err != nil {
+		return ErrCannotWriteState(path, err)
+	}
+	return nil
+}
+
+// State returns the state the consumables would be in after setting the given secrets.
+func (p *Presenter) State(secrets map[string]api.SecretVersion) (State, error) {
+	state := State{
+		Targets: make(map[string]string),
+	}
+
+	for _, consumable := range p.consumables {
+		files, err := consumable.Render(secrets)
+		if err != nil {
+			return State{}, err
+		}
+
+		for target, content := range files {
+			state.Targets[target] = hash(content)
+		}
+	}
+
+	return state, nil
+}
+
+// Check compares what the consumables would write for the given secrets with what is
+// currently on disk. Targets that are recorded in the given previous state, but are no
+// longer declared in the spec, are reported as orphaned when they still exist.
+// Targets that are up to date are not returned. The result is sorted by target.
+func (p *Presenter) Check(secrets map[string]api.SecretVersion, previous State) ([]Drift, error) {
+	expected, err := p.State(secrets)
+	if err != nil {
+		return nil, err
+	}
+
+	var drift []Drift
+	for target, expectedHash := range expected.Targets {
+		currentHash, exists, err := hashFile(target)
+		if err != nil {
+			return nil, err
+		}
+
+		if !exists {
+			drift = append(drift, Drift{
+				Target:       target,
+				Status:       DriftAdded,
+				ExpectedHash: expectedHash,
+			})
+		} else if currentHash != expectedHash {
+			drift = append(drift, Drift{
+				Target:       target,
+				Status:       DriftChanged,
+				CurrentHash:  currentHash,
+				ExpectedHash: expectedHash,
+			})
+		}
+	}
+
+	for _, target := range orphans(expected, previous) {
+		currentHash, exists, err := hashFile(target)
+		if err != nil {
+			return nil, err
+		}
+
+		if exists {
+			drift = append(drift, Drift{
+				Target:      target,
+				Status:      DriftOrphaned,
+				CurrentHash: currentHash,
+			})
+		}
+	}
+
+	sort.Slice(drift, func(i, j int) bool {
+		return drift[i].Target < drift[j].Target
+	})
+
+	return drift, nil
+}
+
+// Prune removes the targets that are recorded in the given previous state,
+// but are no longer declared in the spec. It returns the removed targets.
+func (p *Presenter) Prune(secrets map[string]api.SecretVersion, previous State) ([]string, error) {
+	expected, err := p.State(secrets)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	for _, target := range orphans(expected, previous) {
+		err := os.Remove(target)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return removed, err
+		}
+		removed = append(removed, target)
+	}
+
+	return removed, nil
+}
+
+// orphans returns the targets in the previous state that are not in the expected state, sorted.
+func orphans(expected State, previous State) []string {
+	var targets []string
+	for target := range previous.Targets {
+		_, declared := expected.Targets[target]
+		if !declared {
+			targets = append(targets, target)
+		}
+	}
+	sort.Strings(targets)
+	return targets
+}
+
+// hashFile returns the hash of the file at the given path and whether it exists.
+func hashFile(path string) (string, bool, error) {
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, ErrCannotReadFile(path, err)
+	}
+	return hash(content), true, nil
+}
+
+// hash returns the hex encoded SHA-256 hash of the given content.
+func hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
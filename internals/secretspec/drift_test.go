@@ -0,0 +1,98 @@
+package secretspec
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestPresenter_CheckAndPrune(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secretspec")
+	assert.OK(t, err)
+	defer os.RemoveAll(dir)
+
+	p, err := NewPresenter(dir, false, DefaultParsers...)
+	assert.OK(t, err)
+
+	err = p.Parse([]byte(`
+secrets:
+    - file:
+        source: user/repo/secret
+        target: secret.txt
+    - env:
+        vars:
+            TEST: user/repo/secret`))
+	assert.OK(t, err)
+
+	secrets := map[string]api.SecretVersion{
+		"user/repo/secret": {Data: []byte("secret")},
+	}
+	fileTarget := filepath.Join(dir, "secret.txt")
+	envTarget := filepath.Join(dir, SecretEnvPath, defaultEnvName, "TEST")
+
+	// Nothing has been set yet.
+	drift, err := p.Check(secrets, State{})
+	assert.OK(t, err)
+	assert.Equal(t, drift, []Drift{
+		{Target: envTarget, Status: DriftAdded, ExpectedHash: hash([]byte("secret"))},
+		{Target: fileTarget, Status: DriftAdded, ExpectedHash: hash([]byte("secret\n"))},
+	})
+
+	err = p.Set(secrets)
+	assert.OK(t, err)
+
+	drift, err = p.Check(secrets, State{})
+	assert.OK(t, err)
+	assert.Equal(t, len(drift), 0)
+
+	// A target was changed on disk and another one is no longer in the spec.
+	err = overwriteFile(fileTarget, []byte("modified"), DefaultFileMode)
+	assert.OK(t, err)
+
+	orphan := filepath.Join(dir, "orphan.txt")
+	err = ioutil.WriteFile(orphan, []byte("orphan"), DefaultFileMode)
+	assert.OK(t, err)
+
+	previous, err := p.State(secrets)
+	assert.OK(t, err)
+	previous.Targets[orphan] = hash([]byte("orphan"))
+	previous.Targets[filepath.Join(dir, "removed.txt")] = hash([]byte("removed"))
+
+	drift, err = p.Check(secrets, previous)
+	assert.OK(t, err)
+	assert.Equal(t, drift, []Drift{
+		{Target: orphan, Status: DriftOrphaned, CurrentHash: hash([]byte("orphan"))},
+		{Target: fileTarget, Status: DriftChanged, CurrentHash: hash([]byte("modified")), ExpectedHash: hash([]byte("secret\n"))},
+	})
+
+	removed, err := p.Prune(secrets, previous)
+	assert.OK(t, err)
+	assert.Equal(t, removed, []string{orphan})
+
+	_, err = os.Stat(orphan)
+	assert.Equal(t, os.IsNotExist(err), true)
+}
+
+func TestState_ReadWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secretspec")
+	assert.OK(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, ".secrets.yml.state")
+
+	state, err := ReadState(path)
+	assert.OK(t, err)
+	assert.Equal(t, state, State{Targets: map[string]string{}})
+
+	state.Targets["secret.txt"] = hash([]byte("secret"))
+	err = state.Write(path)
+	assert.OK(t, err)
+
+	actual, err := ReadState(path)
+	assert.OK(t, err)
+	assert.Equal(t, actual, state)
+}
@@ -145,14 +145,15 @@ func (e env) Set(secrets map[string]api.SecretVersion) error {
 		return ErrCannotCreateEnvDir(err)
 	}
 
+	files, err := e.Render(secrets)
+	if err != nil {
+		return err
+	}
+
 	for _, v := range e.vars {
 		log.Debugf("setting env var: %s (source) => %s (target)", v.source, v.target)
-		version, found := secrets[v.source]
-		if !found {
-			return ErrSecretNotFound(v.source)
-		}
-
-		err := overwriteFile(e.getVarPath(v), version.Data, DefaultFileMode)
+		path := e.getVarPath(v)
+		err := overwriteFile(path, files[path], DefaultFileMode)
 		if err != nil {
 			return ErrCannotSetEnvironmentVariable(err)
 		}
@@ -160,6 +161,20 @@ func (e env) Set(secrets map[string]api.SecretVersion) error {
 	return nil
 }
 
+// Render returns the contents of the files of all environment variables,
+// indexed by their path in the environment directory.
+func (e env) Render(secrets map[string]api.SecretVersion) (map[string][]byte, error) {
+	files := make(map[string][]byte, len(e.vars))
+	for _, v := range e.vars {
+		version, found := secrets[v.source]
+		if !found {
+			return nil, ErrSecretNotFound(v.source)
+		}
+		files[e.getVarPath(v)] = version.Data
+	}
+	return files, nil
+}
+
 // Clear removes the environment variable directory from the filesystem.
 func (e *env) Clear() error {
 	err := os.RemoveAll(e.dirPath)
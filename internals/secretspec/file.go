@@ -111,12 +111,24 @@ func (f *file) createTarget(rootPath string, allowMountAnywhere bool) error {
 // the file.
 func (f *file) Set(secrets map[string]api.SecretVersion) error {
 	log.Debugf("setting file: %s (source) => %s (target)", f.source, f.target)
+	files, err := f.Render(secrets)
+	if err != nil {
+		return err
+	}
+
+	return overwriteFile(f.target, files[f.target], f.filemode)
+}
+
+// Render returns the contents of the file with the matching secret in the given map.
+func (f *file) Render(secrets map[string]api.SecretVersion) (map[string][]byte, error) {
 	version, found := secrets[f.source]
 	if !found {
-		return ErrSecretNotFound(f.source)
+		return nil, ErrSecretNotFound(f.source)
 	}
 
-	return overwriteFile(f.target, posix.AddNewLine(version.Data), f.filemode)
+	return map[string][]byte{
+		f.target: posix.AddNewLine(version.Data),
+	}, nil
 }
 
 // Clear removes the file from the filesystem.
@@ -122,6 +122,18 @@ type Inject struct {
 // and writes to the target file. Though the map may contain other
 // secrets, it must contain all source secrets of this consumable.
 func (inj *Inject) Set(secrets map[string]api.SecretVersion) error {
+	files, err := inj.Render(secrets)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("writing injected file to %s", inj.target)
+
+	return overwriteFile(inj.target, files[inj.target], inj.filemode)
+}
+
+// Render returns the encoded contents of the target file, injected with the matching secrets.
+func (inj *Inject) Render(secrets map[string]api.SecretVersion) (map[string][]byte, error) {
 	input := make(map[string]string, len(secrets))
 	for path, secret := range secrets {
 		input[path] = string(secret.Data)
@@ -129,17 +141,17 @@ func (inj *Inject) Set(secrets map[string]api.SecretVersion) error {
 
 	output, err := inj.template.Inject(input)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	log.Debugf("writing injected file to %s", inj.target)
-
 	encodedBytes, err := inj.encoding.NewEncoder().Bytes([]byte(output))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return overwriteFile(inj.target, encodedBytes, inj.filemode)
+	return map[string][]byte{
+		inj.target: encodedBytes,
+	}, nil
 }
 
 // Sources returns the full paths of the secrets from which the Consumable is sourced.
@@ -0,0 +1,270 @@
+package secretspec
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	fieldNamespace = "namespace"
+	fieldLabels    = "labels"
+	fieldKeys      = "keys"
+	fieldBase64    = "base64"
+)
+
+var (
+	kubernetesKeyPattern  = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+	kubernetesNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+)
+
+// Errors
+var (
+	ErrInvalidKubernetesName = errConsumption.Code("invalid_kubernetes_name").ErrorPref("invalid kubernetes %s %s: it must consist of lowercase alphanumeric characters, '-' or '.' and must start and end with an alphanumeric character")
+	ErrInvalidKubernetesKey  = errConsumption.Code("invalid_kubernetes_key").ErrorPref("invalid kubernetes secret key %s: it must consist of alphanumeric characters, '-', '_' or '.'")
+)
+
+// KubernetesParser is a Parser to parse Kubernetes Secret manifest Consumables.
+type KubernetesParser struct{}
+
+// Type returns the parser type.
+func (p KubernetesParser) Type() string {
+	return "kubernetes"
+}
+
+// Parse parses a config to create a Kubernetes Secret manifest Consumable.
+func (p KubernetesParser) Parse(rootPath string, allowMountAnywhere bool, config map[string]interface{}) (Consumable, error) {
+	name, ok := config[fieldName].(string)
+	if !ok {
+		return nil, ErrFieldNotSet(fieldName, name)
+	}
+
+	namespace, _ := config[fieldNamespace].(string)
+
+	labels := make(map[string]string)
+	rawLabels, ok := config[fieldLabels].(map[interface{}]interface{})
+	if ok {
+		for key, val := range rawLabels {
+			k, ok := key.(string)
+			if !ok {
+				return nil, ErrCannotConvertField(fieldLabels, key, k)
+			}
+			v, ok := val.(string)
+			if !ok {
+				return nil, ErrCannotConvertField(k, val, v)
+			}
+			labels[k] = v
+		}
+	}
+
+	rawKeys, ok := config[fieldKeys].(map[interface{}]interface{})
+	if !ok {
+		return nil, ErrFieldNotSet(fieldKeys, rawKeys)
+	}
+
+	keys := make(map[string]string, len(rawKeys))
+	for key, val := range rawKeys {
+		k, ok := key.(string)
+		if !ok {
+			return nil, ErrCannotConvertField(fieldKeys, key, k)
+		}
+		source, ok := val.(string)
+		if !ok {
+			return nil, ErrCannotConvertField(k, val, source)
+		}
+		keys[k] = source
+	}
+
+	useBase64, _ := config[fieldBase64].(bool)
+
+	filemode := DefaultFileMode
+	mode, ok := config[fieldFilemode].(string)
+	if ok && mode != "" {
+		var err error
+		filemode, err = strToFileMode(mode)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	targetName, _ := config[fieldTarget].(string)
+	if targetName == "" {
+		targetName = name + ".yml"
+	}
+
+	secret, err := newKubernetesSecret(name, namespace, labels, keys, useBase64, targetName, filemode)
+	if err != nil {
+		return nil, err
+	}
+
+	secret.target, err = createTarget(rootPath, secret.target, allowMountAnywhere)
+	if err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+// kubernetesSecret implements a Consumable that renders a Kubernetes Secret manifest.
+type kubernetesSecret struct {
+	name      string
+	namespace string
+	labels    map[string]string
+	keys      map[string]string
+	base64    bool
+	target    string
+	filemode  os.FileMode
+}
+
+// newKubernetesSecret creates a new kubernetes secret consumable,
+// validating the name, namespace, keys and sources.
+func newKubernetesSecret(name, namespace string, labels map[string]string, keys map[string]string, useBase64 bool, target string, filemode os.FileMode) (*kubernetesSecret, error) {
+	name = strings.TrimSpace(name)
+	if len(name) > 253 || !kubernetesNamePattern.MatchString(name) {
+		return nil, ErrInvalidKubernetesName("secret name", name)
+	}
+
+	namespace = strings.TrimSpace(namespace)
+	if namespace != "" && (len(namespace) > 63 || !kubernetesNamePattern.MatchString(namespace) || strings.Contains(namespace, ".")) {
+		return nil, ErrInvalidKubernetesName("namespace", namespace)
+	}
+
+	sources := make(map[string]string, len(keys))
+	for key, source := range keys {
+		key = strings.TrimSpace(key)
+		if !kubernetesKeyPattern.MatchString(key) {
+			return nil, ErrInvalidKubernetesKey(key)
+		}
+
+		source = strings.ToLower(strings.TrimSpace(source))
+		err := api.ValidateSecretPath(source)
+		if err != nil {
+			return nil, ErrInvalidSourcePath(err)
+		}
+		sources[key] = source
+	}
+
+	return &kubernetesSecret{
+		name:      name,
+		namespace: namespace,
+		labels:    labels,
+		keys:      sources,
+		base64:    useBase64,
+		target:    target,
+		filemode:  filemode,
+	}, nil
+}
+
+// kubernetesManifest is the YAML representation of a Kubernetes Secret.
+type kubernetesManifest struct {
+	APIVersion string                     `yaml:"apiVersion"`
+	Kind       string                     `yaml:"kind"`
+	Metadata   kubernetesManifestMetadata `yaml:"metadata"`
+	Type       string                     `yaml:"type"`
+	Data       map[string]string          `yaml:"data,omitempty"`
+	StringData map[string]string          `yaml:"stringData,omitempty"`
+}
+
+// kubernetesManifestMetadata is the YAML representation of the metadata of a Kubernetes object.
+type kubernetesManifestMetadata struct {
+	Name      string            `yaml:"name"`
+	Namespace string            `yaml:"namespace,omitempty"`
+	Labels    map[string]string `yaml:"labels,omitempty"`
+}
+
+// Set renders the manifest with the matching secrets and writes it to the target.
+// Though the map may contain other secrets, it must contain all source secrets of this consumable.
+func (k *kubernetesSecret) Set(secrets map[string]api.SecretVersion) error {
+	files, err := k.Render(secrets)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("writing kubernetes secret manifest to %s", k.target)
+	return overwriteFile(k.target, files[k.target], k.filemode)
+}
+
+// Render returns the manifest containing the matching secrets.
+func (k *kubernetesSecret) Render(secrets map[string]api.SecretVersion) (map[string][]byte, error) {
+	manifest, err := k.renderManifest(secrets)
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{
+		k.target: manifest,
+	}, nil
+}
+
+// renderManifest returns the YAML encoded manifest containing the matching secrets.
+func (k *kubernetesSecret) renderManifest(secrets map[string]api.SecretVersion) ([]byte, error) {
+	data := make(map[string]string, len(k.keys))
+	for key, source := range k.keys {
+		version, found := secrets[source]
+		if !found {
+			return nil, ErrSecretNotFound(source)
+		}
+
+		if k.base64 {
+			data[key] = base64.StdEncoding.EncodeToString(version.Data)
+		} else {
+			data[key] = string(version.Data)
+		}
+	}
+
+	manifest := kubernetesManifest{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Metadata: kubernetesManifestMetadata{
+			Name:      k.name,
+			Namespace: k.namespace,
+			Labels:    k.labels,
+		},
+		Type: "Opaque",
+	}
+	if k.base64 {
+		manifest.Data = data
+	} else {
+		manifest.StringData = data
+	}
+
+	return yaml.Marshal(manifest)
+}
+
+// Clear removes the manifest from the filesystem.
+func (k *kubernetesSecret) Clear() error {
+	err := os.Remove(k.target)
+	if os.IsNotExist(err) {
+		log.Warningf("cannot clear file %s as it does not exist", k.target)
+		return nil
+	}
+	return err
+}
+
+// Sources returns the full paths of the secrets from which the consumable is sourced.
+func (k *kubernetesSecret) Sources() map[string]struct{} {
+	sources := make(map[string]struct{})
+	for _, source := range k.keys {
+		sources[source] = struct{}{}
+	}
+	return sources
+}
+
+// Equals checks whether two kubernetes secrets have the same target.
+func (k *kubernetesSecret) Equals(consumable Consumable) bool {
+	kubernetesConsumable, ok := consumable.(*kubernetesSecret)
+	if !ok {
+		return false
+	}
+	return strings.EqualFold(kubernetesConsumable.target, k.target)
+}
+
+// String returns the string representation of the kubernetes secret.
+func (k *kubernetesSecret) String() string {
+	return fmt.Sprintf("kubernetes:%s", k.target)
+}
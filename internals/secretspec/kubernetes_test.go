@@ -0,0 +1,170 @@
+package secretspec
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestKubernetesParse(t *testing.T) {
+	cases := map[string]struct {
+		config   map[string]interface{}
+		expected *kubernetesSecret
+		err      error
+	}{
+		"all values": {
+			config: map[string]interface{}{
+				"name":      "db-credentials",
+				"namespace": "prod",
+				"target":    "k8s/secret.yml",
+				"filemode":  "0600",
+				"base64":    true,
+				"labels": map[interface{}]interface{}{
+					"app": "db",
+				},
+				"keys": map[interface{}]interface{}{
+					"password": "User/Repo/db/password",
+				},
+			},
+			expected: &kubernetesSecret{
+				name:      "db-credentials",
+				namespace: "prod",
+				labels:    map[string]string{"app": "db"},
+				keys:      map[string]string{"password": "user/repo/db/password"},
+				base64:    true,
+				target:    filepath.Join("k8s", "secret.yml"),
+				filemode:  0600,
+			},
+		},
+		"defaults": {
+			config: map[string]interface{}{
+				"name": "db-credentials",
+				"keys": map[interface{}]interface{}{
+					"password": "user/repo/db/password",
+				},
+			},
+			expected: &kubernetesSecret{
+				labels:   map[string]string{},
+				name:     "db-credentials",
+				keys:     map[string]string{"password": "user/repo/db/password"},
+				target:   "db-credentials.yml",
+				filemode: DefaultFileMode,
+			},
+		},
+		"invalid name": {
+			config: map[string]interface{}{
+				"name": "DB_Credentials",
+				"keys": map[interface{}]interface{}{},
+			},
+			err: ErrInvalidKubernetesName("secret name", "DB_Credentials"),
+		},
+		"invalid key": {
+			config: map[string]interface{}{
+				"name": "db",
+				"keys": map[interface{}]interface{}{
+					"pass word": "user/repo/db/password",
+				},
+			},
+			err: ErrInvalidKubernetesKey("pass word"),
+		},
+		"keys not set": {
+			config: map[string]interface{}{
+				"name": "db",
+			},
+			err: ErrFieldNotSet(fieldKeys, map[interface{}]interface{}(nil)),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "secretspec")
+			assert.OK(t, err)
+			defer os.RemoveAll(dir)
+
+			actual, err := KubernetesParser{}.Parse(dir, false, tc.config)
+
+			assert.Equal(t, err, tc.err)
+			if tc.err == nil {
+				tc.expected.target = filepath.Join(dir, tc.expected.target)
+				assert.Equal(t, actual, tc.expected)
+			}
+		})
+	}
+}
+
+func TestKubernetesSetAndClear(t *testing.T) {
+	secrets := map[string]api.SecretVersion{
+		"user/repo/db/password": {Data: []byte("pass\"word")},
+		"user/repo/db/user":     {Data: []byte("admin")},
+	}
+
+	cases := map[string]struct {
+		base64   bool
+		expected string
+	}{
+		"string data": {
+			expected: "apiVersion: v1\n" +
+				"kind: Secret\n" +
+				"metadata:\n" +
+				"  name: db\n" +
+				"  namespace: prod\n" +
+				"  labels:\n" +
+				"    app: db\n" +
+				"type: Opaque\n" +
+				"stringData:\n" +
+				"  password: pass\"word\n" +
+				"  user: admin\n",
+		},
+		"base64 data": {
+			base64: true,
+			expected: "apiVersion: v1\n" +
+				"kind: Secret\n" +
+				"metadata:\n" +
+				"  name: db\n" +
+				"  namespace: prod\n" +
+				"  labels:\n" +
+				"    app: db\n" +
+				"type: Opaque\n" +
+				"data:\n" +
+				"  password: cGFzcyJ3b3Jk\n" +
+				"  user: YWRtaW4=\n",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "secretspec")
+			assert.OK(t, err)
+			defer os.RemoveAll(dir)
+
+			target := filepath.Join(dir, "secret.yml")
+			secret, err := newKubernetesSecret(
+				"db",
+				"prod",
+				map[string]string{"app": "db"},
+				map[string]string{"password": "user/repo/db/password", "user": "user/repo/db/user"},
+				tc.base64,
+				target,
+				DefaultFileMode,
+			)
+			assert.OK(t, err)
+
+			err = secret.Set(secrets)
+			assert.OK(t, err)
+
+			actual, err := ioutil.ReadFile(target)
+			assert.OK(t, err)
+			assert.Equal(t, string(actual), tc.expected)
+
+			err = secret.Clear()
+			assert.OK(t, err)
+
+			_, err = os.Stat(target)
+			assert.Equal(t, os.IsNotExist(err), true)
+		})
+	}
+}
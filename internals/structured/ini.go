@@ -0,0 +1,103 @@
+package structured
+
+import (
+	"bytes"
+	"strings"
+)
+
+// iniInjector injects secrets into INI files.
+// Files are processed line by line, so sections, comments and key order are preserved.
+type iniInjector struct{}
+
+// Inject replaces every value that is a secret reference with the secret.
+// Values that contain characters with a special meaning in INI files are double quoted and escaped.
+// Secrets containing newlines are rejected, because INI has no portable way to represent them.
+func (iniInjector) Inject(raw []byte, sr SecretReader) ([]byte, error) {
+	var out bytes.Buffer
+
+	lines := bytes.SplitAfter(raw, []byte("\n"))
+	for _, line := range lines {
+		injected, err := injectINILine(string(line), sr)
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString(injected)
+	}
+
+	return out.Bytes(), nil
+}
+
+// injectINILine replaces a secret reference in the value of a single key-value line.
+// Any other line is returned as is.
+func injectINILine(line string, sr SecretReader) (string, error) {
+	body := strings.TrimRight(line, "\r\n")
+	eol := line[len(body):]
+
+	trimmed := strings.TrimSpace(body)
+	if trimmed == "" || trimmed[0] == ';' || trimmed[0] == '#' || trimmed[0] == '[' {
+		return line, nil
+	}
+
+	sep := strings.IndexAny(body, "=:")
+	if sep == -1 {
+		return line, nil
+	}
+
+	value := body[sep+1:]
+	leading := value[:len(value)-len(strings.TrimLeft(value, " \t"))]
+	value = value[len(leading):]
+
+	// Split off a trailing inline comment, which must be preceded by whitespace.
+	trailing := ""
+	content := value
+	if len(value) > 0 && (value[0] == '"' || value[0] == '\'') {
+		end := strings.IndexByte(value[1:], value[0])
+		if end == -1 {
+			return line, nil
+		}
+		content = value[1 : end+1]
+		trailing = value[end+2:]
+	} else {
+		for i := 1; i < len(value); i++ {
+			if (value[i] == ';' || value[i] == '#') && (value[i-1] == ' ' || value[i-1] == '\t') {
+				content = value[:i]
+				trailing = value[i:]
+				break
+			}
+		}
+		trimmedContent := strings.TrimRight(content, " \t")
+		trailing = content[len(trimmedContent):] + trailing
+		content = trimmedContent
+	}
+
+	path, isReference := referencePath(content)
+	if !isReference {
+		return line, nil
+	}
+
+	secret, err := sr.ReadSecret(path)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.ContainsAny(secret, "\r\n") {
+		return "", ErrUnsupportedValue(path, FormatINI, "secrets containing newlines cannot be represented in INI values")
+	}
+
+	return body[:sep+1] + leading + encodeINIValue(secret) + trailing + eol, nil
+}
+
+// encodeINIValue returns the value as is when it can be written unquoted.
+// Otherwise, it is wrapped in double quotes and quotes and backslashes are escaped.
+func encodeINIValue(value string) string {
+	needsQuotes := value == "" ||
+		strings.ContainsAny(value, "\"';#\\=") ||
+		strings.TrimSpace(value) != value
+
+	if !needsQuotes {
+		return value
+	}
+
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + replacer.Replace(value) + `"`
+}
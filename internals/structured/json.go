@@ -0,0 +1,104 @@
+package structured
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+// jsonInjector injects secrets into JSON documents.
+// String values are replaced in place, so the layout of the document is left untouched.
+type jsonInjector struct{}
+
+// Inject replaces every string value that is a secret reference with the JSON encoded secret.
+// Object keys are never replaced.
+func (jsonInjector) Inject(raw []byte, sr SecretReader) ([]byte, error) {
+	if !json.Valid(raw) {
+		var v interface{}
+		err := json.Unmarshal(raw, &v)
+		if err == nil {
+			err = errors.New("invalid JSON")
+		}
+		return nil, ErrInvalidDocument(FormatJSON, err)
+	}
+
+	var out bytes.Buffer
+	for i := 0; i < len(raw); {
+		if raw[i] != '"' {
+			out.WriteByte(raw[i])
+			i++
+			continue
+		}
+
+		end := scanJSONString(raw, i)
+		literal := raw[i:end]
+		i = end
+
+		var value string
+		err := json.Unmarshal(literal, &value)
+		if err != nil {
+			return nil, ErrInvalidDocument(FormatJSON, err)
+		}
+
+		path, isReference := referencePath(value)
+		if !isReference || isJSONKey(raw, end) {
+			out.Write(literal)
+			continue
+		}
+
+		secret, err := sr.ReadSecret(path)
+		if err != nil {
+			return nil, err
+		}
+
+		encoded, err := encodeJSONString(secret)
+		if err != nil {
+			return nil, err
+		}
+		out.Write(encoded)
+	}
+
+	return out.Bytes(), nil
+}
+
+// scanJSONString returns the index directly after the closing quote
+// of the string literal that starts at the given index.
+func scanJSONString(raw []byte, start int) int {
+	for i := start + 1; i < len(raw); i++ {
+		switch raw[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(raw)
+}
+
+// isJSONKey returns whether the string literal ending at the given index is an object key.
+func isJSONKey(raw []byte, end int) bool {
+	for i := end; i < len(raw); i++ {
+		switch raw[i] {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case ':':
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// encodeJSONString returns the JSON string literal of the given value,
+// without escaping HTML characters.
+func encodeJSONString(value string) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	err := encoder.Encode(value)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
@@ -0,0 +1,90 @@
+// Package structured injects secrets into structured configuration files.
+//
+// Contrary to text templates, values are only replaced in scalar positions
+// of a parsed document and are escaped according to the rules of the format.
+// This makes it safe to inject secrets that contain quotes, newlines or other
+// special characters. Key order and comments are preserved where the format allows.
+package structured
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/secrethub/secrethub-go/internals/errio"
+)
+
+// Errors
+var (
+	errStructured = errio.Namespace("structured")
+
+	ErrUnknownFormat      = errStructured.Code("unknown_format").ErrorPref("unknown format '%s': supported formats are yaml, json, toml and ini")
+	ErrCannotDetectFormat = errStructured.Code("cannot_detect_format").ErrorPref("cannot detect the format of %s: use a .yml, .yaml, .json, .toml or .ini file extension or set the format explicitly")
+	ErrInvalidDocument    = errStructured.Code("invalid_document").ErrorPref("could not parse %s document: %v")
+	ErrUnsupportedValue   = errStructured.Code("unsupported_value").ErrorPref("the value of secret %s cannot be represented in %s: %s")
+)
+
+// ReferencePrefix is the prefix of scalar values that are replaced with secrets.
+const ReferencePrefix = "secrethub://"
+
+// Supported formats.
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+	FormatTOML = "toml"
+	FormatINI  = "ini"
+)
+
+// SecretReader fetches a secret by its path.
+type SecretReader interface {
+	ReadSecret(path string) (string, error)
+}
+
+// Injector replaces secret references in a document with the values of the referenced secrets.
+type Injector interface {
+	Inject(raw []byte, sr SecretReader) ([]byte, error)
+}
+
+// NewInjector returns the Injector for the given format.
+func NewInjector(format string) (Injector, error) {
+	switch strings.ToLower(format) {
+	case FormatYAML, "yml":
+		return yamlInjector{}, nil
+	case FormatJSON:
+		return jsonInjector{}, nil
+	case FormatTOML:
+		return tomlInjector{}, nil
+	case FormatINI:
+		return iniInjector{}, nil
+	default:
+		return nil, ErrUnknownFormat(format)
+	}
+}
+
+// DetectFormat returns the format of a file based on its extension.
+func DetectFormat(filename string) (string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yml", ".yaml":
+		return FormatYAML, nil
+	case ".json":
+		return FormatJSON, nil
+	case ".toml":
+		return FormatTOML, nil
+	case ".ini", ".cfg", ".conf":
+		return FormatINI, nil
+	default:
+		return "", ErrCannotDetectFormat(filename)
+	}
+}
+
+// referencePath returns the path of the secret referenced by the given value
+// and whether the value is a secret reference at all.
+func referencePath(value string) (string, bool) {
+	if !strings.HasPrefix(value, ReferencePrefix) {
+		return "", false
+	}
+	path := strings.TrimPrefix(value, ReferencePrefix)
+	if path == "" || strings.ContainsAny(path, " \t\r\n\"'\\") {
+		return "", false
+	}
+	return path, true
+}
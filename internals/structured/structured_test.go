@@ -0,0 +1,160 @@
+package structured
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+type fakeSecretReader map[string]string
+
+func (sr fakeSecretReader) ReadSecret(path string) (string, error) {
+	secret, ok := sr[path]
+	if !ok {
+		return "", errors.New("secret not found: " + path)
+	}
+	return secret, nil
+}
+
+var testSecrets = fakeSecretReader{
+	"company/repo/password":  `p@ss"word\`,
+	"company/repo/multiline": "line one\nline two",
+	"company/repo/number":    "1234",
+	"company/repo/plain":     "plain",
+}
+
+func TestInject(t *testing.T) {
+	cases := map[string]struct {
+		format   string
+		in       string
+		expected string
+		err      error
+	}{
+		"json": {
+			format:   FormatJSON,
+			in:       "{\n  \"secrethub://company/repo/plain\": \"secrethub://company/repo/password\",\n  \"list\": [\"secrethub://company/repo/multiline\", 1, true],\n  \"html\": \"<b>\"\n}\n",
+			expected: "{\n  \"secrethub://company/repo/plain\": \"p@ss\\\"word\\\\\",\n  \"list\": [\"line one\\nline two\", 1, true],\n  \"html\": \"<b>\"\n}\n",
+		},
+		"json invalid": {
+			format: FormatJSON,
+			in:     `{"foo": }`,
+			err:    ErrInvalidDocument(FormatJSON, errors.New("invalid character '}' looking for beginning of value")),
+		},
+		"yaml": {
+			format: FormatYAML,
+			in: "# database settings\n" +
+				"db:\n" +
+				"  password: secrethub://company/repo/password # inline\n" +
+				"  port: secrethub://company/repo/number\n" +
+				"  cert: 'secrethub://company/repo/multiline'\n" +
+				"  user: admin\n" +
+				"hosts:\n" +
+				"  - secrethub://company/repo/plain\n",
+			expected: "# database settings\n" +
+				"db:\n" +
+				"  password: \"p@ss\\\"word\\\\\" # inline\n" +
+				"  port: \"1234\"\n" +
+				"  cert: |-\n" +
+				"    line one\n" +
+				"    line two\n" +
+				"  user: admin\n" +
+				"hosts:\n" +
+				"  - \"plain\"\n",
+		},
+		"yaml keys are not replaced": {
+			format:   FormatYAML,
+			in:       "secrethub://company/repo/plain: foo\n",
+			expected: "secrethub://company/repo/plain: foo\n",
+		},
+		"toml": {
+			format: FormatTOML,
+			in: "# settings\n" +
+				"[database]\n" +
+				"password = \"secrethub://company/repo/password\" # comment\n" +
+				"cert = 'secrethub://company/repo/multiline'\n" +
+				"\"secrethub://company/repo/plain\" = 1\n" +
+				"ports = [\n" +
+				"  \"secrethub://company/repo/number\",\n" +
+				"]\n" +
+				"inline = { key = \"secrethub://company/repo/plain\" }\n",
+			expected: "# settings\n" +
+				"[database]\n" +
+				"password = \"p@ss\\\"word\\\\\" # comment\n" +
+				"cert = \"line one\\nline two\"\n" +
+				"\"secrethub://company/repo/plain\" = 1\n" +
+				"ports = [\n" +
+				"  \"1234\",\n" +
+				"]\n" +
+				"inline = { key = \"plain\" }\n",
+		},
+		"ini": {
+			format: FormatINI,
+			in: "; settings\r\n" +
+				"[database]\r\n" +
+				"password = secrethub://company/repo/password ; comment\r\n" +
+				"user: \"secrethub://company/repo/plain\"\r\n",
+			expected: "; settings\r\n" +
+				"[database]\r\n" +
+				"password = \"p@ss\\\"word\\\\\" ; comment\r\n" +
+				"user: plain\r\n",
+		},
+		"ini multiline secret": {
+			format: FormatINI,
+			in:     "cert = secrethub://company/repo/multiline\n",
+			err:    ErrUnsupportedValue("company/repo/multiline", FormatINI, "secrets containing newlines cannot be represented in INI values"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			injector, err := NewInjector(tc.format)
+			assert.OK(t, err)
+
+			actual, err := injector.Inject([]byte(tc.in), testSecrets)
+
+			assert.Equal(t, err, tc.err)
+			if tc.err == nil {
+				assert.Equal(t, string(actual), tc.expected)
+			}
+		})
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	cases := map[string]struct {
+		filename string
+		expected string
+		err      error
+	}{
+		"yml": {
+			filename: "config.yml",
+			expected: FormatYAML,
+		},
+		"uppercase json": {
+			filename: "CONFIG.JSON",
+			expected: FormatJSON,
+		},
+		"toml": {
+			filename: "app/config.toml",
+			expected: FormatTOML,
+		},
+		"ini": {
+			filename: "settings.ini",
+			expected: FormatINI,
+		},
+		"unknown": {
+			filename: "config.txt",
+			err:      ErrCannotDetectFormat("config.txt"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := DetectFormat(tc.filename)
+
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package structured
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// tomlInjector injects secrets into TOML documents.
+// Values are replaced in place, so comments, key order and layout are preserved.
+type tomlInjector struct{}
+
+// Inject replaces every string value that is a secret reference with a basic string
+// containing the secret. This includes values in arrays and inline tables.
+// Keys and table headers are never replaced.
+func (tomlInjector) Inject(raw []byte, sr SecretReader) ([]byte, error) {
+	var out bytes.Buffer
+
+	inValue := false
+	inHeader := false
+	depth := 0
+	line := 1
+
+	for i := 0; i < len(raw); {
+		c := raw[i]
+		switch {
+		case c == '#':
+			end := bytes.IndexByte(raw[i:], '\n')
+			if end == -1 {
+				end = len(raw) - i
+			}
+			out.Write(raw[i : i+end])
+			i += end
+		case c == '\n':
+			if depth == 0 {
+				inValue = false
+			}
+			inHeader = false
+			line++
+			out.WriteByte(c)
+			i++
+		case c == '=' && !inHeader && !inValue:
+			inValue = true
+			out.WriteByte(c)
+			i++
+		case c == '[' && !inValue:
+			inHeader = true
+			out.WriteByte(c)
+			i++
+		case (c == '[' || c == '{') && inValue:
+			depth++
+			out.WriteByte(c)
+			i++
+		case (c == ']' || c == '}') && inValue:
+			depth--
+			if depth < 0 {
+				return nil, ErrInvalidDocument(FormatTOML, fmt.Errorf("unexpected '%c' at line %d", c, line))
+			}
+			out.WriteByte(c)
+			i++
+		case c == '=' && depth > 0:
+			// Separator between a key and a value in an inline table.
+			out.WriteByte(c)
+			i++
+		case c == '"' || c == '\'':
+			end, content, err := scanTOMLString(raw, i)
+			if err != nil {
+				return nil, ErrInvalidDocument(FormatTOML, fmt.Errorf("%v at line %d", err, line))
+			}
+			literal := raw[i:end]
+			line += bytes.Count(literal, []byte("\n"))
+			i = end
+
+			path, isReference := referencePath(content)
+			if !isReference || inHeader || !inValue || isTOMLKey(raw, end) {
+				out.Write(literal)
+				continue
+			}
+
+			secret, err := sr.ReadSecret(path)
+			if err != nil {
+				return nil, err
+			}
+			out.WriteString(encodeTOMLString(secret))
+		default:
+			out.WriteByte(c)
+			i++
+		}
+	}
+
+	if depth != 0 {
+		return nil, ErrInvalidDocument(FormatTOML, fmt.Errorf("unclosed array or inline table"))
+	}
+
+	return out.Bytes(), nil
+}
+
+// scanTOMLString scans the string literal starting at the given index.
+// It returns the index directly after the literal and its raw content.
+// Escape sequences in the content are not decoded.
+func scanTOMLString(raw []byte, start int) (int, string, error) {
+	quote := raw[start : start+1]
+	multiline := bytes.Repeat(quote, 3)
+
+	if bytes.HasPrefix(raw[start:], multiline) {
+		contentStart := start + 3
+		for i := contentStart; i < len(raw); i++ {
+			if quote[0] == '"' && raw[i] == '\\' {
+				i++
+				continue
+			}
+			if bytes.HasPrefix(raw[i:], multiline) {
+				content := string(raw[contentStart:i])
+				// A newline directly after the opening delimiter is trimmed.
+				content = strings.TrimPrefix(strings.TrimPrefix(content, "\r"), "\n")
+				return i + 3, content, nil
+			}
+		}
+		return 0, "", fmt.Errorf("unclosed multi-line string")
+	}
+
+	for i := start + 1; i < len(raw); i++ {
+		switch raw[i] {
+		case '\\':
+			if quote[0] == '"' {
+				i++
+			}
+		case '\n':
+			return 0, "", fmt.Errorf("unclosed string")
+		case quote[0]:
+			return i + 1, string(raw[start+1 : i]), nil
+		}
+	}
+	return 0, "", fmt.Errorf("unclosed string")
+}
+
+// isTOMLKey returns whether the string literal ending at the given index is (part of) a key.
+func isTOMLKey(raw []byte, end int) bool {
+	for i := end; i < len(raw); i++ {
+		switch raw[i] {
+		case ' ', '\t':
+			continue
+		case '=', '.':
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// encodeTOMLString returns the TOML basic string literal of the given value.
+func encodeTOMLString(value string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range value {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,113 @@
+package structured
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const defaultYAMLIndent = 2
+
+// yamlInjector injects secrets into YAML documents.
+// Documents are parsed into a node tree, which retains key order and comments.
+type yamlInjector struct{}
+
+// Inject replaces every scalar that is a secret reference with a string containing the secret.
+// Mapping keys are never replaced. Multiple documents in a single stream are supported.
+func (yamlInjector) Inject(raw []byte, sr SecretReader) ([]byte, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(raw))
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(detectYAMLIndent(raw))
+
+	for {
+		var doc yaml.Node
+		err := decoder.Decode(&doc)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, ErrInvalidDocument(FormatYAML, err)
+		}
+
+		err = injectYAMLNode(&doc, sr)
+		if err != nil {
+			return nil, err
+		}
+
+		err = encoder.Encode(&doc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := encoder.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Bytes(), nil
+}
+
+// injectYAMLNode recursively replaces the secret references in the node and its children.
+func injectYAMLNode(node *yaml.Node, sr SecretReader) error {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			err := injectYAMLNode(child, sr)
+			if err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		// Content alternates between keys and values, only the values are injected.
+		for i := 1; i < len(node.Content); i += 2 {
+			err := injectYAMLNode(node.Content[i], sr)
+			if err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		if node.Tag != "!!str" {
+			return nil
+		}
+
+		path, isReference := referencePath(node.Value)
+		if !isReference {
+			return nil
+		}
+
+		secret, err := sr.ReadSecret(path)
+		if err != nil {
+			return err
+		}
+
+		node.Value = secret
+		node.Tag = "!!str"
+		if strings.Contains(secret, "\n") {
+			node.Style = yaml.LiteralStyle
+		} else {
+			node.Style = yaml.DoubleQuotedStyle
+		}
+	}
+	return nil
+}
+
+// detectYAMLIndent returns the indentation of the first indented line of the document,
+// falling back to the default indentation when it cannot be determined.
+func detectYAMLIndent(raw []byte) int {
+	for _, line := range strings.Split(string(raw), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		indent := len(line) - len(trimmed)
+		if indent == 0 || trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if indent < 2 {
+			return defaultYAMLIndent
+		}
+		return indent
+	}
+	return defaultYAMLIndent
+}